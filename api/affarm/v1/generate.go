@@ -0,0 +1,4 @@
+// Package affarmv1 - сгенерированный код gRPC API affarm.v1 для клиентов и сервера
+package affarmv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative affarm/v1/affarm.proto
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"affarm/config"
+	"affarm/internal/database"
+	"affarm/internal/pricefile"
+	services "affarm/internal/service"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// runExport выполняет подкоманду export: выгрузку истории цен в файл без запуска сервера
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	symbols := flags.String("symbols", "", "символы через запятую, по умолчанию все отслеживаемые валюты")
+	fromFlag := flags.String("from", "", "начало периода, RFC3339 или 2006-01-02")
+	toFlag := flags.String("to", "", "конец периода (не включительно), RFC3339 или 2006-01-02")
+	format := flags.String("format", pricefile.FormatCSV, "формат файла: csv, ndjson или parquet")
+	compress := flags.Bool("gzip", false, "сжать файл gzip (включается сам для -o с расширением .gz)")
+	output := flags.String("o", "-", "файл выгрузки, - для stdout")
+	flags.Parse(args)
+
+	query := services.ExportQuery{Format: *format}
+	var err error
+	if query.From, err = parseExportTime(*fromFlag); err != nil {
+		log.Fatalf("неверный -from: %v", err)
+	}
+	if query.To, err = parseExportTime(*toFlag); err != nil {
+		log.Fatalf("неверный -to: %v", err)
+	}
+	if *symbols != "" {
+		for _, symbol := range strings.Split(*symbols, ",") {
+			query.Symbols = append(query.Symbols, strings.TrimSpace(symbol))
+		}
+	}
+	if err := query.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	cfg, err := config.Load("config.yml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, repo, err := database.Open(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		// Файл пишется под временным именем, чтобы прерванная выгрузка не оставила неполный файл
+		file, err := os.Create(*output + ".part")
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		out = file
+		*compress = *compress || strings.HasSuffix(*output, ".gz")
+	}
+	var zw *gzip.Writer
+	if *compress {
+		zw = gzip.NewWriter(out)
+		out = zw
+	}
+
+	rows, err := services.NewPriceService(repo).Export(context.Background(), query, out)
+	if err == nil && zw != nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		if *output != "-" {
+			os.Remove(*output + ".part")
+		}
+		log.Fatalf("ошибка выгрузки цен: %v", err)
+	}
+	if *output != "-" {
+		if err := os.Rename(*output+".part", *output); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Выгружено точек цен: %d\n", rows)
+}
+
+// parseExportTime разбирает момент RFC3339 или дату 2006-01-02 (полночь UTC)
+func parseExportTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("не задан")
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q не является RFC3339 или датой 2006-01-02", value)
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"affarm/config"
+	"affarm/internal/database"
+	"affarm/internal/importer"
+	"affarm/internal/repository"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runImport выполняет подкоманду import: загрузку истории цен из файлов CSV или NDJSON
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "формат файлов: csv или ndjson, по умолчанию по расширению")
+	source := flags.String("source", importer.SourceImport, "источник для строк без колонки source")
+	asJSON := flags.Bool("json", false, "печатать отчет в JSON")
+	actor := flags.String("actor", "cli", "автор периодов отслеживания созданных валют")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		log.Fatal("использование: import [-format csv|ndjson] [-source имя] [-json] файл... (- для stdin, .gz распаковывается)")
+	}
+
+	cfg, err := config.Load("config.yml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Storage.Driver == "memory" {
+		log.Fatal("import требует SQL-хранилища цен, выберите storage.driver postgres или sqlite")
+	}
+	db, _, err := database.Open(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	failed := false
+	for _, name := range flags.Args() {
+		report, err := importFile(repository.WithActor(context.Background(), *actor), db, name, *format, *source)
+		if err != nil {
+			log.Printf("%s: %v", name, err)
+			failed = true
+			continue
+		}
+		if *asJSON {
+			json.NewEncoder(os.Stdout).Encode(struct {
+				File string `json:"file"`
+				importer.Report
+			}{name, report})
+			continue
+		}
+		printReport(name, report)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func importFile(ctx context.Context, db *gorm.DB, name, format, source string) (importer.Report, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return importer.Report{}, err
+		}
+		defer file.Close()
+		r = file
+	}
+	if strings.HasSuffix(name, ".gz") {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return importer.Report{}, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	if format == "" {
+		format = "csv"
+		if ext := filepath.Ext(strings.TrimSuffix(name, ".gz")); ext == ".ndjson" || ext == ".jsonl" {
+			format = "ndjson"
+		}
+	}
+	return importer.Import(ctx, db, r, importer.Options{Format: format, Source: source})
+}
+
+func printReport(name string, report importer.Report) {
+	fmt.Printf("%s: строк %d, добавлено %d, повторов %d, отклонено %d\n",
+		name, report.Lines, report.Accepted, report.Duplicates, report.Rejected)
+	if len(report.CreatedCurrencies) > 0 {
+		fmt.Printf("  новые валюты (приостановлены, сбор включает resume): %s\n", strings.Join(report.CreatedCurrencies, ", "))
+	}
+	if len(report.ExtendedTracking) > 0 {
+		fmt.Printf("  добавлены периоды отслеживания: %s\n", strings.Join(report.ExtendedTracking, ", "))
+	}
+	for _, e := range report.Errors {
+		fmt.Printf("  строка %d: %s\n", e.Line, e.Error)
+	}
+	if report.Rejected > int64(len(report.Errors)) {
+		fmt.Printf("  ... и еще %d отклоненных строк\n", report.Rejected-int64(len(report.Errors)))
+	}
+}
@@ -2,33 +2,175 @@ package main
 
 import (
 	"affarm/config"
+	"affarm/internal/archive"
 	"affarm/internal/database"
+	"affarm/internal/grpcapi"
 	"affarm/internal/handlers"
 	services "affarm/internal/service"
+	"context"
 	"log"
+	"net"
 	"net/http"
+	"os"
 )
 
 func main() {
+	// Подкоманды: управление схемой БД (migrate up | down [N] | status | dedupe)
+	// выгрузка истории цен в файл и загрузка из файлов (export -from ... -to ..., import файл...)
+	// и безвозвратное удаление валюты с историей (purge -reason ... СИМВОЛ)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			runMigrate(os.Args[2:])
+			return
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "import":
+			runImport(os.Args[2:])
+			return
+		case "purge":
+			runPurge(os.Args[2:])
+			return
+		}
+	}
+
 	cfg, err := config.Load("config.yml")
 	if err != nil {
 		log.Fatal(err)
 	}
-	// Подключение к БД
-	db, err := database.GetGormDB()
+	// Подключение к хранилищу: валюты и цены идут через repo, остальные таблицы - через db
+	db, repo, err := database.Open(cfg.Storage)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// Секции prices создаются заранее на несколько месяцев вперед (только PostgreSQL)
+	if cfg.Storage.Driver == "postgres" {
+		partitions := database.NewPartitionMaintainer(db, cfg.Storage.PartitionMonthsAhead)
+		if err := partitions.Start(); err != nil {
+			log.Fatal(err)
+		}
+		defer partitions.Stop()
+	}
+
+	// Кеш списка валют и последних цен
+	priceCache := services.NewPriceCache()
+	if err := priceCache.Load(context.Background(), repo); err != nil {
+		log.Fatal(err)
+	}
+
+	// Кеш матриц корреляций, сбрасывается при поступлении новых цен
+	correlationCache := services.NewCorrelationCache(256)
+
+	// Раздача новых цен потоковым клиентам (SSE и WebSocket)
+	priceHub := services.NewPriceHub(1024, 256)
+	listeners := []services.PriceListener{priceCache, correlationCache, priceHub}
+
+	// Алерты проверяются на каждой новой цене, уведомления уходят на вебхуки в отдельной горутине.
+	// Правила читают валюты и цены из db, поэтому с хранилищем memory алерты выключены
+	var alertService *services.AlertService
+	if cfg.Storage.Driver != "memory" {
+		alertService = services.NewAlertService(db, cfg.Alerts)
+		alertService.Start()
+		defer alertService.Stop()
+		listeners = append(listeners, alertService)
+	}
+
+	// Закрытые месячные секции цен выгружаются в архивные файлы; старые цены можно искать в архиве
+	var archiver *archive.Archiver
+	var archiveReader *archive.Reader
+	if cfg.Archive.Enabled {
+		if cfg.Storage.Driver != "postgres" {
+			log.Fatal("archive работает только с storage.driver postgres")
+		}
+		// Чанки гипертаблицы TimescaleDB архиватор не выгружает, а свертка без его ограничения удалила бы сырые точки
+		if partitioned, err := database.PricesPartitioned(context.Background(), db); err != nil {
+			log.Fatal(err)
+		} else if !partitioned {
+			log.Fatal("archive работает только со встроенным секционированием prices, для гипертаблицы TimescaleDB выключите archive.enabled")
+		}
+		store, err := archive.NewStore(context.Background(), cfg.Archive.Store)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if archiver, err = archive.NewArchiver(db, store, cfg.Archive); err != nil {
+			log.Fatal(err)
+		}
+		archiver.Start()
+		defer archiver.Stop()
+		if cfg.Archive.ReadThrough {
+			archiveReader = archive.NewReader(db, store, cfg.Archive.CacheSeries)
+			repo = archive.NewReadThrough(repo, archiveReader)
+		}
+	}
+
+	// Сервисы, общие для HTTP и gRPC
+	svc := &services.Services{
+		Currencies:   services.NewCurrencyService(repo, priceCache),
+		Prices:       services.NewPriceService(repo),
+		Cache:        priceCache,
+		Correlations: correlationCache,
+		Alerts:       alertService,
+		Hub:          priceHub,
+		Archive:      archiveReader,
+	}
+
 	// Инициализация роутера
-	r := handlers.NewRouter(db)
+	r := handlers.NewRouter(db, svc, cfg)
+
+	// Создаем чекер цен с заданным интервалом, сохраненные цены попадают в кеши, алерты и потоки
+	priceUpdater := services.NewPriceUpdater(repo, &cfg.BinanceConfig, listeners...)
+
+	// События о новых ценах пишутся в outbox вместе с ценой и рассылаются получателям из конфига
+	if cfg.Outbox.Enabled {
+		if cfg.Storage.Driver == "memory" {
+			log.Fatal("outbox требует SQL-хранилища цен, выберите storage.driver postgres или sqlite")
+		}
+		sinks, err := services.NewEventSinks(cfg.Outbox.Sinks)
+		if err != nil {
+			log.Fatal(err)
+		}
+		priceUpdater.WithOutbox(db)
+		outboxRelay := services.NewOutboxRelay(db, cfg.Outbox, sinks)
+		outboxRelay.Start()
+		defer outboxRelay.Stop()
+	}
+
+	// Старые цены сворачиваются в агрегаты по уровням хранения из конфига
+	if cfg.Retention.Enabled {
+		if cfg.Storage.Driver != "postgres" {
+			log.Fatal("retention работает только с storage.driver postgres")
+		}
+		retention := database.NewRetention(db, cfg.Retention)
+		if archiver != nil {
+			// Сырые точки не удаляются, пока их секция не выгружена в архив
+			retention.WithRawLimit(archiver.ArchivedUntil)
+		}
+		retention.Start()
+		defer retention.Stop()
+	}
 
-	// Создаем чекер цен с заданным интервалом
-	priceUpdater := services.NewPriceUpdater(db, cfg)
 	// Запускаем чекер цен в отдельной горутине
 	go priceUpdater.Start()
 	defer priceUpdater.Stop()
 
+	// gRPC API на отдельном порту
+	if cfg.GRPC.Enabled {
+		listener, err := net.Listen("tcp", cfg.GRPC.Addr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		grpcServer := grpcapi.NewServer(svc)
+		go func() {
+			log.Printf("gRPC сервер запущен на %s", cfg.GRPC.Addr)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("Ошибка gRPC сервера: %v", err)
+			}
+		}()
+		defer grpcServer.GracefulStop()
+	}
+
 	// Настройка сервера
 	server := &http.Server{
 		Addr:    ":8080",
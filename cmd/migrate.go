@@ -0,0 +1,83 @@
+package main
+
+import (
+	"affarm/internal/database"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+// runMigrate выполняет подкоманду migrate: up, down [N], status или dedupe [-dry-run]
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal("использование: migrate up | down [N] | status | dedupe [-dry-run]")
+	}
+
+	db, err := database.GetGormDB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(sqlDB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Применено миграций: %d, версия схемы %d\n", len(applied), migrator.Latest())
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				log.Fatalf("неверное количество шагов отката: %s", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, m := range reverted {
+			fmt.Printf("Откачена %04d_%s\n", m.Version, m.Name)
+		}
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range status {
+			applied := "не применена"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, applied)
+		}
+		if err := migrator.Check(ctx); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	case "dedupe":
+		dryRun := len(args) > 1 && args[1] == "-dry-run"
+		removed, err := database.DedupePrices(ctx, db, dryRun)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if dryRun {
+			fmt.Printf("Повторных точек цен к удалению: %d\n", removed)
+		} else {
+			fmt.Printf("Удалено повторных точек цен: %d\n", removed)
+		}
+	default:
+		log.Fatalf("неизвестная команда migrate %s", args[0])
+	}
+}
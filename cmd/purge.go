@@ -0,0 +1,75 @@
+package main
+
+import (
+	"affarm/config"
+	"affarm/internal/database"
+	"affarm/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// runPurge выполняет подкоманду purge: безвозвратное удаление валюты со всей историей
+func runPurge(args []string) {
+	flags := flag.NewFlagSet("purge", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "только посчитать строки, которые будут удалены")
+	reason := flags.String("reason", "", "причина удаления для журнала, обязательна без -dry-run")
+	actor := flags.String("actor", "cli", "автор удаления для журнала")
+	asJSON := flags.Bool("json", false, "печатать отчет в JSON")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Fatal("использование: purge [-dry-run] -reason причина [-actor имя] [-json] СИМВОЛ")
+	}
+	symbol := flags.Arg(0)
+	if !*dryRun && *reason == "" {
+		log.Fatal("укажите -reason: причина записывается в журнал удаления")
+	}
+
+	cfg, err := config.Load("config.yml")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.Storage.Driver == "memory" {
+		log.Fatal("purge требует SQL-хранилища цен, выберите storage.driver postgres или sqlite")
+	}
+	db, _, err := database.Open(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := repository.WithActor(context.Background(), *actor)
+	report, err := database.PurgeCurrency(ctx, db, symbol, database.PurgeOptions{
+		DryRun:    *dryRun,
+		BatchSize: cfg.Admin.PurgeBatchSize,
+		Pause:     time.Duration(cfg.Admin.PurgePauseMs) * time.Millisecond,
+		Reason:    *reason,
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Fatalf("валюта %s не найдена", symbol)
+	}
+	if *asJSON {
+		json.NewEncoder(os.Stdout).Encode(report)
+	} else {
+		verb := "Удалено"
+		if report.DryRun {
+			verb = "Будет удалено"
+		}
+		fmt.Printf("%s для %s: цен %d, сверток %d, периодов отслеживания %d, алертов %d, уведомлений %d\n",
+			verb, symbol, report.Prices, report.Aggregates, report.TrackingPeriods, report.AlertRules, report.AlertDeliveries)
+		if report.ArchiveFiles > 0 {
+			fmt.Printf("  ВНИМАНИЕ: файлы архива (%d) не изменяются, точки валюты (id %d) в них нужно удалить вручную\n",
+				report.ArchiveFiles, report.CurrencyID)
+		}
+		if report.AuditID != 0 {
+			fmt.Printf("  запись журнала удаления: %d\n", report.AuditID)
+		}
+	}
+	if err != nil {
+		log.Fatalf("ошибка удаления валюты: %v", err)
+	}
+}
@@ -8,14 +8,167 @@ import (
 	"path/filepath"
 )
 
+// Config - конфигурация сервиса. Настройки binance лежат в корне файла для обратной совместимости
+type Config struct {
+	BinanceConfig `yaml:",inline"`
+	Alerts        AlertsConfig    `yaml:"alerts"`
+	Outbox        OutboxConfig    `yaml:"outbox"`
+	GRPC          GRPCConfig      `yaml:"grpc"`
+	Storage       StorageConfig   `yaml:"storage"`
+	Retention     RetentionConfig `yaml:"retention"`
+	Archive       ArchiveConfig   `yaml:"archive"`
+	Admin         AdminConfig     `yaml:"admin"`
+	Stream        StreamConfig    `yaml:"stream"`
+}
+
 type BinanceConfig struct {
 	APIURL       string `yaml:"api_url"`
 	TimeoutSec   int    `yaml:"timeout_seconds"`
 	Convertation string `yaml:"convertation"`
 }
 
+// AlertsConfig - настройки доставки уведомлений о срабатывании алертов
+type AlertsConfig struct {
+	Webhooks       []WebhookConfig `yaml:"webhooks"`
+	MaxAttempts    int             `yaml:"max_attempts"`    // попыток доставки до статуса failed
+	TimeoutSec     int             `yaml:"timeout_seconds"` // таймаут одного запроса к вебхуку
+	RetryBackoffMs int             `yaml:"retry_backoff_ms"`
+}
+
+// WebhookConfig - получатель уведомлений. Тело запроса подписывается HMAC-SHA256 секретом
+type WebhookConfig struct {
+	Name      string `yaml:"name"`
+	URL       string `yaml:"url"`
+	Secret    string `yaml:"secret"`
+	SecretEnv string `yaml:"secret_env"` // переменная окружения с секретом, имеет приоритет над secret
+}
+
+// AdminConfig - служебные HTTP-методы (/api/v1/admin/...). Без токена они не регистрируются
+type AdminConfig struct {
+	Token       string `yaml:"token"`     // запросы передают его в заголовке Authorization: Bearer
+	TokenEnv    string `yaml:"token_env"` // переменная окружения с токеном, имеет приоритет над token
+	MaxUploadMB int    `yaml:"max_upload_mb"`
+	// Удаление истории валюты: строк в одном DELETE и пауза между пачками
+	PurgeBatchSize int `yaml:"purge_batch_size"`
+	PurgePauseMs   int `yaml:"purge_pause_ms"`
+}
+
+// StreamConfig - потоковая выдача цен (SSE и WebSocket)
+type StreamConfig struct {
+	// AllowedOrigins - источники (схема://хост[:порт]) страниц других сайтов, которым разрешен WebSocket.
+	// Страницы с того же хоста, что и сервис, и клиенты без заголовка Origin допускаются всегда
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+// OutboxConfig - настройки рассылки событий о новых ценах из таблицы outbox_events
+type OutboxConfig struct {
+	Enabled          bool         `yaml:"enabled"`
+	PollIntervalMs   int          `yaml:"poll_interval_ms"`
+	BatchSize        int          `yaml:"batch_size"`
+	GapTimeoutMs     int          `yaml:"gap_timeout_ms"`     // сколько ждать событие с пропущенным номером (его транзакция еще не закоммичена)
+	PruneIntervalMin int          `yaml:"prune_interval_min"` // как часто удалять события, доставленные всем получателям
+	Sinks            []SinkConfig `yaml:"sinks"`
+}
+
+// SinkConfig - получатель событий outbox
+type SinkConfig struct {
+	Name    string `yaml:"name"`
+	Type    string `yaml:"type"`    // http, file, stdout, nats
+	URL     string `yaml:"url"`     // для http и nats
+	Path    string `yaml:"path"`    // для file
+	Subject string `yaml:"subject"` // для nats
+}
+
+// StorageConfig - выбор хранилища. sqlite и memory позволяют запустить сервис без PostgreSQL;
+// аналитические эндпоинты (stats, average, correlation) работают только на postgres
+type StorageConfig struct {
+	Driver string `yaml:"driver"` // postgres (по умолчанию), sqlite, memory
+	Path   string `yaml:"path"`   // файл базы для sqlite
+	// MigrateOnStart применяет миграции postgres при запуске. Если выключено, сервис
+	// не стартует, пока схема не обновлена командой migrate up
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+	// PartitionMonthsAhead - на сколько месяцев вперед заранее создаются секции prices
+	PartitionMonthsAhead int `yaml:"partition_months_ahead"`
+}
+
+// RetentionConfig - уровни хранения цен. Сырые точки живут RawDays дней, затем сворачиваются
+// в первый уровень Tiers; каждый уровень по истечении KeepDays сворачивается в следующий.
+// Работает только на postgres
+type RetentionConfig struct {
+	Enabled     bool            `yaml:"enabled"`
+	RawDays     int             `yaml:"raw_days"`
+	IntervalMin int             `yaml:"interval_minutes"` // как часто запускается свертка
+	Tiers       []RetentionTier `yaml:"tiers"`
+}
+
+// RetentionTier - уровень хранения со сверткой точек за ResolutionSec секунд
+type RetentionTier struct {
+	ResolutionSec int `yaml:"resolution_seconds"`
+	KeepDays      int `yaml:"keep_days"` // 0 - хранить всегда, допустимо только у последнего уровня
+}
+
+// Validate проверяет, что уровни укрупняются и свертка укладывается в сутки
+func (cfg RetentionConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.RawDays <= 0 {
+		return fmt.Errorf("retention.raw_days должен быть больше 0")
+	}
+	previous, keep := 0, cfg.RawDays
+	for i, tier := range cfg.Tiers {
+		// Свертка идет посуточно, поэтому интервал уровня должен делить сутки
+		if tier.ResolutionSec <= 0 || 86400%tier.ResolutionSec != 0 {
+			return fmt.Errorf("retention.tiers[%d]: resolution_seconds должен делить сутки (86400)", i)
+		}
+		if tier.ResolutionSec <= previous {
+			return fmt.Errorf("retention.tiers[%d]: уровни должны укрупняться", i)
+		}
+		if keep == 0 {
+			return fmt.Errorf("retention.tiers[%d]: предыдущий уровень хранится всегда, этот уровень не будет заполнен", i)
+		}
+		if tier.KeepDays < 0 {
+			return fmt.Errorf("retention.tiers[%d]: keep_days не может быть отрицательным", i)
+		}
+		previous, keep = tier.ResolutionSec, tier.KeepDays
+	}
+	return nil
+}
+
+// ArchiveConfig - выгрузка закрытых месячных секций prices в файлы холодного хранилища.
+// Работает только на postgres с встроенным секционированием
+type ArchiveConfig struct {
+	Enabled        bool               `yaml:"enabled"`
+	Format         string             `yaml:"format"`          // parquet (по умолчанию) или csv (сжатый gzip)
+	AfterMonths    int                `yaml:"after_months"`    // сколько месяцев секция должна быть закрыта до выгрузки
+	DropPartitions bool               `yaml:"drop_partitions"` // удалять секцию из бд после выгрузки
+	ReadThrough    bool               `yaml:"read_through"`    // искать цену в архиве, если в бд остались только свертки или точек нет
+	CacheSeries    int                `yaml:"cache_series"`    // сколько рядов (файл, валюта) держать в памяти для чтения из архива
+	IntervalMin    int                `yaml:"interval_minutes"`
+	Store          ArchiveStoreConfig `yaml:"store"`
+}
+
+// ArchiveStoreConfig - хранилище архивных файлов: локальный каталог или S3-совместимое хранилище
+type ArchiveStoreConfig struct {
+	Type         string `yaml:"type"` // local (по умолчанию) или s3
+	Dir          string `yaml:"dir"`  // каталог для local
+	Endpoint     string `yaml:"endpoint"`
+	Bucket       string `yaml:"bucket"`
+	Prefix       string `yaml:"prefix"`
+	Region       string `yaml:"region"`
+	UseSSL       bool   `yaml:"use_ssl"`
+	AccessKeyEnv string `yaml:"access_key_env"` // переменные окружения с ключами доступа
+	SecretKeyEnv string `yaml:"secret_key_env"`
+}
+
+// GRPCConfig - настройки gRPC API, работающего рядом с HTTP
+type GRPCConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"`
+}
+
 // Load загружает конфиг из YAML файла
-func Load(configPath string) (*BinanceConfig, error) {
+func Load(configPath string) (*Config, error) {
 	fullPath, err := filepath.Abs(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при чтении пути до конфига: %w", err)
@@ -26,13 +179,97 @@ func Load(configPath string) (*BinanceConfig, error) {
 		return nil, fmt.Errorf("ошибки при чтении конфига: %w", err)
 	}
 
-	var cfg BinanceConfig
+	var cfg Config
 	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
 		return nil, fmt.Errorf("ошибка при переводе конфига: %w", err)
 	}
+	cfg.setDefaults()
+	if err := cfg.Retention.Validate(); err != nil {
+		return nil, err
+	}
 
 	log.Printf("Домен для запросов на цены криптовалют: %s", cfg.APIURL)
 	log.Printf("Опорная валюта для конвертации валют: '%v'", cfg.Convertation)
+	log.Printf("Вебхуков для алертов: %d", len(cfg.Alerts.Webhooks))
+	log.Printf("Хранилище: %s", cfg.Storage.Driver)
 
 	return &cfg, nil
 }
+
+func (cfg *Config) setDefaults() {
+	if cfg.Alerts.MaxAttempts <= 0 {
+		cfg.Alerts.MaxAttempts = 5
+	}
+	if cfg.Alerts.TimeoutSec <= 0 {
+		cfg.Alerts.TimeoutSec = 10
+	}
+	if cfg.Alerts.RetryBackoffMs <= 0 {
+		cfg.Alerts.RetryBackoffMs = 1000
+	}
+	if cfg.Outbox.PollIntervalMs <= 0 {
+		cfg.Outbox.PollIntervalMs = 1000
+	}
+	if cfg.Outbox.BatchSize <= 0 {
+		cfg.Outbox.BatchSize = 500
+	}
+	if cfg.Outbox.GapTimeoutMs <= 0 {
+		cfg.Outbox.GapTimeoutMs = 60000
+	}
+	if cfg.Outbox.PruneIntervalMin <= 0 {
+		cfg.Outbox.PruneIntervalMin = 10
+	}
+	if cfg.Storage.Driver == "" {
+		cfg.Storage.Driver = "postgres"
+	}
+	if cfg.Storage.Path == "" {
+		cfg.Storage.Path = "affarm.db"
+	}
+	if cfg.Retention.IntervalMin <= 0 {
+		cfg.Retention.IntervalMin = 60
+	}
+	if cfg.Archive.Format == "" {
+		cfg.Archive.Format = "parquet"
+	}
+	if cfg.Archive.CacheSeries <= 0 {
+		cfg.Archive.CacheSeries = 16
+	}
+	if cfg.Archive.IntervalMin <= 0 {
+		cfg.Archive.IntervalMin = 60
+	}
+	if cfg.Archive.Store.Type == "" {
+		cfg.Archive.Store.Type = "local"
+	}
+	if cfg.Archive.Store.Dir == "" {
+		cfg.Archive.Store.Dir = "archive"
+	}
+	if cfg.Storage.PartitionMonthsAhead <= 0 {
+		cfg.Storage.PartitionMonthsAhead = 3
+	}
+	if cfg.Admin.MaxUploadMB <= 0 {
+		cfg.Admin.MaxUploadMB = 1024
+	}
+	if cfg.Admin.PurgeBatchSize <= 0 {
+		cfg.Admin.PurgeBatchSize = 5000
+	}
+	if cfg.Admin.PurgePauseMs < 0 {
+		cfg.Admin.PurgePauseMs = 0
+	}
+	if cfg.Admin.TokenEnv != "" {
+		if token := os.Getenv(cfg.Admin.TokenEnv); token != "" {
+			cfg.Admin.Token = token
+		}
+	}
+	if cfg.GRPC.Addr == "" {
+		cfg.GRPC.Addr = ":9090"
+	}
+	for i, sink := range cfg.Outbox.Sinks {
+		if sink.Name == "" {
+			cfg.Outbox.Sinks[i].Name = sink.Type
+		}
+	}
+	for i, webhook := range cfg.Alerts.Webhooks {
+		if webhook.SecretEnv != "" {
+			cfg.Alerts.Webhooks[i].Secret = os.Getenv(webhook.SecretEnv)
+		}
+	}
+}
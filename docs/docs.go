@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/currency/add": {
+        "/admin/currencies/{symbol}/purge": {
             "post": {
-                "description": "Добавляет новую криптовалюту в систему отслеживания",
+                "description": "Удаляет валюту, все ее точки цен, свертки, историю отслеживания и алерты. Точки и свертки удаляются пачками по admin.purge_batch_size строк,\nкаждая пачка в своей транзакции, поэтому таблица prices не блокируется целиком. Сначала валюта переводится в archived, и чекер цен ее больше не дополняет.\ndry_run=true только считает строки. Без dry_run нужна причина (reason): каждый запуск пишется в журнал GET /admin/purges с автором из X-Actor.\nФайлы архива старых цен не изменяются, их количество возвращается в archive_files. Прерванное удаление можно запустить повторно.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,25 +25,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "currencies"
+                    "admin"
                 ],
-                "summary": "Добавить новую криптовалюту",
+                "summary": "Безвозвратное удаление валюты и ее истории",
                 "parameters": [
                     {
-                        "description": "Данные валюты",
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Только посчитать строки",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Параметры удаления",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handlers_currency.AddCurrencyRequest"
+                            "$ref": "#/definitions/internal_handlers_admin.PurgeRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003cтокен\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/affarm_internal_models.Currency"
+                            "$ref": "#/definitions/affarm_internal_database.PurgeReport"
                         }
                     },
                     "400": {
@@ -55,6 +75,24 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -67,39 +105,210 @@ const docTemplate = `{
                 }
             }
         },
-        "/currency/remove": {
+        "/admin/import/prices": {
             "post": {
-                "description": "Удаляет криптовалюту из системы по ID или Symbol",
+                "description": "Загружает точки цен из файла CSV (заголовок с колонками symbol, timestamp, price и необязательными source, volume)\nили NDJSON (объекты с теми же полями). Время - RFC3339 или Unix-секунды/миллисекунды.\nФайл передается телом запроса или полем file формы multipart/form-data, сжатый - с Content-Encoding: gzip или расширением .gz.\nНедостающие валюты создаются приостановленными (сбор цен включает resume), точки на уже занятые моменты времени пропускаются,\nдля точек существующих валют вне периодов отслеживания добавляются периоды (extended_tracking в отчете),\nстроки с ошибками и строки архивных валют не загружаются и перечисляются в отчете. Требует Authorization: Bearer с токеном admin.token.",
                 "consumes": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "multipart/form-data"
+                ],
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Загрузка истории цен",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Формат файла, по умолчанию по Content-Type или расширению, иначе csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "import",
+                        "description": "Источник для строк без колонки source",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer \u003cтокен\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/affarm_internal_importer.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/purges": {
+            "get": {
+                "description": "Возвращает последние 100 запусков безвозвратного удаления валют, новые первыми: кто, когда, по какой причине и сколько строк удалено.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "currencies"
+                    "admin"
                 ],
-                "summary": "Удалить криптовалюту",
+                "summary": "Журнал удаления валют",
                 "parameters": [
                     {
-                        "description": "Параметры удаления",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Bearer \u003cтокен\u003e",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handlers_currency.RemoveCurrencyRequest"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/affarm_internal_models.PurgeAudit"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/alerts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Список правил алертов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фильтр по валюте",
+                        "name": "symbol",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handlers_alerts.AlertResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает правило: пересечение уровня (above/below) или изменение больше чем на threshold процентов за window_sec (change).\nУведомления отправляются на вебхуки из конфига с подписью HMAC-SHA256 в заголовке X-Affarm-Signature.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Создать правило алерта",
+                "parameters": [
+                    {
+                        "description": "Правило",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_alerts.AlertRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_alerts.AlertResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -131,9 +340,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/price/get": {
+        "/alerts/{id}": {
             "get": {
-                "description": "Возвращает цену для указанной валютной пары на заданный момент времени. Если точное значение отсутствует, возвращает ближайшее доступное.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Получить правило алерта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_alerts.AlertResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
                 "consumes": [
                     "application/json"
                 ],
@@ -141,17 +384,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "alerts"
                 ],
-                "summary": "Получить цену на момент времени",
+                "summary": "Изменить правило алерта",
                 "parameters": [
                     {
-                        "description": "Параметры запроса",
+                        "type": "integer",
+                        "description": "ID правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Правило",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handlers_currency.GetPriceRequest"
+                            "$ref": "#/definitions/internal_handlers_alerts.AlertRequest"
                         }
                     }
                 ],
@@ -159,7 +409,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handlers_currency.PriceResponse"
+                            "$ref": "#/definitions/internal_handlers_alerts.AlertResponse"
                         }
                     },
                     "400": {
@@ -190,69 +440,2726 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "affarm_internal_models.Currency": {
-            "description": "Currency entity",
-            "type": "object",
-            "properties": {
-                "symbol": {
-                    "type": "string"
-                }
-            }
-        },
-        "internal_handlers_currency.AddCurrencyRequest": {
-            "type": "object",
-            "required": [
-                "symbol"
-            ],
-            "properties": {
-                "symbol": {
-                    "type": "string",
-                    "maxLength": 10
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Удалить правило алерта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "internal_handlers_currency.GetPriceRequest": {
-            "type": "object",
-            "required": [
-                "symbol",
-                "timestamp"
-            ],
+        "/alerts/{id}/deliveries": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Журнал доставки уведомлений алерта",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID правила",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей, по умолчанию 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handlers_alerts.DeliveryResponse"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/convert": {
+            "get": {
+                "description": "Пересчитывает amount из валюты from в валюту to по сохраненным ценам обеих валют в опорной валюте (convertation) на момент at.\nДля каждой валюты берется точное или ближайшее значение; расчет ведется в десятичной арифметике.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Конвертировать сумму на момент времени",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "ETH",
+                        "description": "Исходная валюта",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Целевая валюта",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2.5",
+                        "description": "Сумма",
+                        "name": "amount",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Момент времени (Unix-секунды, миллисекунды или RFC3339), по умолчанию - текущий",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.ConvertResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/correlation": {
+            "get": {
+                "description": "Пересемплирует ряды выбранных валют на общую сетку interval за период и возвращает попарные корреляции лог-доходностей\nи бету каждой валюты относительно benchmark. Результаты кешируются и сбрасываются при поступлении новых цен внутри периода.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Матрица корреляций валют",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC,ETH,SOL",
+                        "description": "Символы через запятую",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Бенчмарк для беты, по умолчанию BTC",
+                        "name": "benchmark",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (не включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1h",
+                        "description": "Шаг сетки (Go duration), по умолчанию 1h",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.CorrelationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currencies": {
+            "get": {
+                "description": "Возвращает все известные валюты со статусом (active, paused, archived), датой добавления, последней ценой и количеством точек. Данные отдаются из кеша в памяти.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Список валют",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/affarm_internal_service.CurrencySnapshot"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/add": {
+            "post": {
+                "description": "Добавляет новую криптовалюту в систему отслеживания",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Добавить новую криптовалюту",
+                "parameters": [
+                    {
+                        "description": "Данные валюты",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.AddCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/affarm_internal_models.Currency"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/price": {
+            "get": {
+                "description": "Возвращает цену для указанной валютной пары на заданный момент времени. Если точное значение отсутствует, возвращает ближайшее доступное.\nВ ответе указаны метки использованных точек, смещение от запрошенного момента, источник и качество ответа.\nПараметры передаются в query (symbol или coin, timestamp, interpolate) либо, для обратной совместимости, в JSON-теле.\ntimestamp принимается в Unix-секундах, Unix-миллисекундах или RFC3339.\nbridge_gaps=false ограничивает поиск периодом отслеживания валюты: на момент, когда валюта не отслеживалась, вернется 404,\nа точки по другую сторону пробела в отслеживании не используются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Получить цену на момент времени",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Синоним symbol",
+                        "name": "coin",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1736500490",
+                        "description": "Момент времени",
+                        "name": "timestamp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Интерполировать между соседними точками",
+                        "name": "interpolate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Брать точки через пробелы в отслеживании",
+                        "name": "bridge_gaps",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Параметры запроса (устаревший вариант)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.GetPriceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/affarm_internal_service.PriceResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/remove": {
+            "post": {
+                "description": "Удаляет криптовалюту из системы по ID или Symbol",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Удалить криптовалюту",
+                "parameters": [
+                    {
+                        "description": "Параметры удаления",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.RemoveCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/average": {
+            "get": {
+                "description": "Возвращает среднюю цену за период, взвешенную по времени действия каждой точки (TWAP), и по объему (VWAP), если объемы собираются.\nТочка действует до следующей, но не дольше max_gap; coverage показывает долю периода, покрытую точками.\nПериод, часть которого уже свернута retention, отклоняется с кодом 422: TWAP и VWAP считаются только по сырым точкам.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "TWAP и VWAP за период",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (не включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "10m",
+                        "description": "Максимальное время действия точки (Go duration), по умолчанию 10m",
+                        "name": "max_gap",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.AverageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/latest": {
+            "get": {
+                "description": "Возвращает последнюю сохраненную цену валюты из кеша в памяти",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Последняя цена валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.LatestPriceResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/pause": {
+            "post": {
+                "description": "Переводит валюту в состояние paused: чекер цен ее пропускает, а история остается доступной для запросов цен, выгрузки и статистики.\nЗакрывает текущий период отслеживания, автор берется из заголовка X-Actor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Приостановить сбор цен валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/affarm_internal_service.CurrencySnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/prices": {
+            "get": {
+                "description": "Возвращает сохраненные точки ряда в диапазоне [from, to) по возрастанию времени, без пересемплирования.\nОтвет обрезается до limit точек; тот же запрос доступен в gRPC как GetPriceRange.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Точки ряда цен за период",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (не включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимум точек, по умолчанию и не больше 10000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.PriceRangeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/resume": {
+            "post": {
+                "description": "Возвращает приостановленную валюту в состояние active и открывает новый период отслеживания.\nУдаленная валюта возобновляется через POST /currency/add.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Возобновить сбор цен валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/affarm_internal_service.CurrencySnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/stats": {
+            "get": {
+                "description": "Пересемплирует ряд цен на сетку interval (последняя цена в интервале) и возвращает простые и логарифмические доходности\nмежду соседними интервалами (после интервала без цен доходность пустая),\nреализованную волатильность, максимальную просадку, min/max/mean и ряды SMA/EMA. Расчеты выполняются оконными функциями в БД, EMA - в сервисе.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Статистика по ряду цен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (не включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1h",
+                        "description": "Шаг пересемплирования (Go duration), по умолчанию 1h",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Окно SMA/EMA в интервалах, по умолчанию 20",
+                        "name": "window",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.StatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/tracking": {
+            "get": {
+                "description": "Возвращает периоды, когда валюта отслеживалась: кто и когда включил и выключил сбор цен.\nАвтор берется из заголовка X-Actor запроса, изменившего валюту; у загрузки истории из командной строки это cli, у миграции - migration.\nУдаленная валюта тоже возвращается, с tracked=false.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "История отслеживания валюты",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Символ валюты",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/affarm_internal_service.TrackingHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/export/prices": {
+            "get": {
+                "description": "Выгружает сырые точки рядов в диапазоне [from, to) файлом CSV, NDJSON или Parquet: по валютам и по возрастанию времени.\nСтроки читаются из бд курсором и сразу пишутся в ответ, поэтому размер выгрузки не ограничен.\ngzip=true сжимает файл целиком (application/gzip); без него текстовые форматы сжимаются при Accept-Encoding: gzip.\nЕсли бд отказала посреди выгрузки, соединение обрывается, и клиент получает неполный файл с ошибкой передачи.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Выгрузка истории цен",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC,ETH",
+                        "description": "Символы через запятую, по умолчанию все отслеживаемые валюты",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало периода",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец периода (не включительно)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Формат файла",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Сжать файл gzip",
+                        "name": "gzip",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Список портфелей",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handlers_portfolio.PortfolioResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает портфель с начальными позициями",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Создать портфель",
+                "parameters": [
+                    {
+                        "description": "Данные портфеля",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.CreatePortfolioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.PortfolioResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Получить портфель",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.PortfolioResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет портфель вместе с позициями и историей транзакций",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Удалить портфель",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/history": {
+            "get": {
+                "description": "Считает стоимость портфеля на сетке from, from+step, ..., to с учетом истории транзакций",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Ряд стоимости портфеля",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Начало диапазона",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Конец диапазона",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1h",
+                        "description": "Шаг сетки (Go duration), по умолчанию 1h",
+                        "name": "step",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.HistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/positions": {
+            "put": {
+                "description": "Устанавливает текущее количество валюты в портфеле без записи транзакции",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Установить позицию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Позиция",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.PositionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.PortfolioResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/transactions": {
+            "post": {
+                "description": "Записывает изменение позиции в историю и применяет его к текущей позиции",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Добавить транзакцию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Транзакция",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.TransactionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.TransactionDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolios/{id}/value": {
+            "get": {
+                "description": "Считает стоимость позиций портфеля на момент at по сохраненным ценам в опорной валюте.\nПозиции без цены не оцениваются нулем, а перечисляются в missing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Стоимость портфеля на момент времени",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID портфеля",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Момент времени (Unix-секунды, миллисекунды или RFC3339), по умолчанию - текущий",
+                        "name": "at",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_portfolio.ValuationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/prices/lookup": {
+            "post": {
+                "description": "Принимает набор пар (symbol, timestamp) и возвращает цены в порядке запроса.\nВсе пары разрешаются одним SQL-запросом; ошибки возвращаются по каждой паре отдельно.\nbridge_gaps=false ограничивает поиск периодами отслеживания валют, как в GET /currency/price.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Пакетный поиск цен на моменты времени",
+                "parameters": [
+                    {
+                        "description": "Пары валюта/момент времени",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.LookupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_currency.LookupResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stream/prices": {
+            "get": {
+                "description": "Отправляет каждую новую сохраненную цену событием price. Поддерживает фильтр по валютам,\nпродолжение с заголовка Last-Event-ID из короткого буфера и heartbeat-комментарии.\nКлиент, не успевающий читать события, отключается.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "stream"
+                ],
+                "summary": "Поток новых цен (Server-Sent Events)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC,ETH",
+                        "description": "Символы через запятую, по умолчанию все",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_stream.PriceMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stream/prices/ws": {
+            "get": {
+                "description": "То же, что /stream/prices, но через WebSocket: каждое сообщение - JSON PriceMessage.\nПродолжение - параметром last_event_id, heartbeat - ping-кадрами.\nСтраницы других сайтов получают 403, если их источника нет в stream.allowed_origins.",
+                "tags": [
+                    "stream"
+                ],
+                "summary": "Поток новых цен (WebSocket)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC,ETH",
+                        "description": "Символы через запятую, по умолчанию все",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID последнего полученного события",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handlers_stream.PriceMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "affarm_internal_database.PurgeReport": {
+            "type": "object",
+            "properties": {
+                "aggregates": {
+                    "description": "свертки price_aggregates",
+                    "type": "integer"
+                },
+                "alert_deliveries": {
+                    "type": "integer"
+                },
+                "alert_rules": {
+                    "type": "integer"
+                },
+                "archive_files": {
+                    "description": "ArchiveFiles - файлы архива старых цен: в них могут быть точки валюты, удаление их не меняет",
+                    "type": "integer"
+                },
+                "audit_id": {
+                    "description": "запись purge_audits, пусто при DryRun",
+                    "type": "integer"
+                },
+                "currency_id": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "prices": {
+                    "type": "integer"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tracking_periods": {
+                    "type": "integer"
+                }
+            }
+        },
+        "affarm_internal_importer.LineError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "affarm_internal_importer.Report": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "добавлено новых точек",
+                    "type": "integer"
+                },
+                "created_currencies": {
+                    "description": "валюты, которых не было в бд",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "duplicates": {
+                    "description": "точки на уже занятый момент времени (в бд или выше в файле)",
+                    "type": "integer"
+                },
+                "errors": {
+                    "description": "первые MaxReportedErrors отклоненных строк",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/affarm_internal_importer.LineError"
+                    }
+                },
+                "extended_tracking": {
+                    "description": "существующие валюты, для точек которых вне периодов отслеживания добавлены периоды",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "lines": {
+                    "description": "строк с данными в файле",
+                    "type": "integer"
+                },
+                "rejected": {
+                    "description": "строки с ошибками, не загружены",
+                    "type": "integer"
+                },
+                "symbols": {
+                    "description": "валюты, в ряды которых добавлены точки",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "affarm_internal_models.Currency": {
+            "description": "Currency entity",
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "affarm_internal_models.PurgeAudit": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "aggregates": {
+                    "description": "свертки price_aggregates",
+                    "type": "integer"
+                },
+                "alert_deliveries": {
+                    "type": "integer"
+                },
+                "alert_rules": {
+                    "type": "integer"
+                },
+                "currency_id": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "prices": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "completed",
+                        "failed"
+                    ]
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tracking_periods": {
+                    "type": "integer"
+                }
+            }
+        },
+        "affarm_internal_models.TrackingPeriod": {
+            "type": "object",
+            "properties": {
+                "started_at": {
+                    "type": "string"
+                },
+                "started_by": {
+                    "description": "кто включил отслеживание: X-Actor запроса, import и т.п.",
+                    "type": "string"
+                },
+                "stopped_at": {
+                    "type": "string"
+                },
+                "stopped_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "affarm_internal_service.CurrencySnapshot": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_price": {
+                    "type": "number"
+                },
+                "last_source": {
+                    "type": "string"
+                },
+                "last_update": {
+                    "type": "string"
+                },
+                "point_count": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused",
+                        "archived"
+                    ]
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "affarm_internal_service.PricePoint": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number"
+                },
+                "resolution_seconds": {
+                    "description": "ResolutionSeconds - шаг свертки, из которой взята точка старше срока хранения сырых цен",
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "affarm_internal_service.PriceResponse": {
+            "type": "object",
+            "properties": {
+                "offset_seconds": {
+                    "description": "знаковое расстояние от запрошенного момента до timestamp",
+                    "type": "number"
+                },
+                "points": {
+                    "description": "все точки, по которым посчитан ответ",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/affarm_internal_service.PricePoint"
+                    }
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quality": {
+                    "type": "string",
+                    "enum": [
+                        "exact",
+                        "nearest",
+                        "interpolated",
+                        "stale"
+                    ]
+                },
+                "source": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "метка ближайшей из использованных точек",
+                    "type": "string"
+                }
+            }
+        },
+        "affarm_internal_service.TrackingHistory": {
+            "type": "object",
+            "properties": {
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/affarm_internal_models.TrackingPeriod"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tracked": {
+                    "description": "цены валюты собираются сейчас: она не удалена и не приостановлена",
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_handlers_admin.PurgeRequest": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "только посчитать строки",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "description": "причина для журнала, обязательна без dry_run",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_alerts.AlertRequest": {
+            "type": "object",
+            "required": [
+                "kind",
+                "symbol"
+            ],
+            "properties": {
+                "cooldown_sec": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "above",
+                        "below",
+                        "change"
+                    ]
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "threshold": {
+                    "description": "уровень цены или процент изменения",
+                    "type": "number"
+                },
+                "webhook": {
+                    "description": "пусто - все настроенные вебхуки",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "window_sec": {
+                    "description": "окно для change",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "internal_handlers_alerts.AlertResponse": {
+            "type": "object",
+            "properties": {
+                "cooldown_sec": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "above",
+                        "below",
+                        "change"
+                    ]
+                },
+                "last_triggered_at": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "webhook": {
+                    "type": "string"
+                },
+                "window_sec": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers_alerts.DeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "response_code": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "delivered",
+                        "failed"
+                    ]
+                },
+                "webhook": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_currency.AddCurrencyRequest": {
+            "type": "object",
+            "properties": {
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "internal_handlers_currency.AverageResponse": {
+            "type": "object",
+            "properties": {
+                "coverage": {
+                    "description": "covered_seconds / длительность периода",
+                    "type": "number"
+                },
+                "covered_seconds": {
+                    "description": "сколько секунд периода покрыто точками",
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "max_gap": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "twap": {
+                    "description": "средняя цена, взвешенная по времени действия точек",
+                    "type": "number"
+                },
+                "twap_samples": {
+                    "description": "точек внутри периода",
+                    "type": "integer"
+                },
+                "volume": {
+                    "type": "number"
+                },
+                "vwap": {
+                    "description": "средняя цена, взвешенная по объему; null, если объемов нет",
+                    "type": "number"
+                },
+                "vwap_samples": {
+                    "description": "точек с объемом внутри периода",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers_currency.ConvertLeg": {
+            "type": "object",
+            "properties": {
+                "offset_seconds": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "string",
+                    "example": "117000.5"
+                },
+                "quality": {
+                    "type": "string",
+                    "enum": [
+                        "exact",
+                        "nearest",
+                        "stale"
+                    ]
+                },
+                "source": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_currency.ConvertResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "2.5"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "from_leg": {
+                    "$ref": "#/definitions/internal_handlers_currency.ConvertLeg"
+                },
+                "rate": {
+                    "description": "сколько to стоит одна единица from",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "amount * rate",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "to_leg": {
+                    "$ref": "#/definitions/internal_handlers_currency.ConvertLeg"
+                },
+                "via": {
+                    "description": "опорная валюта, через которую считается курс",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_currency.CorrelationResponse": {
+            "type": "object",
+            "properties": {
+                "benchmark": {
+                    "type": "string"
+                },
+                "beta": {
+                    "description": "бета каждой валюты относительно benchmark",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                },
+                "cached": {
+                    "type": "boolean"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "matrix": {
+                    "description": "matrix[i][j] - корреляция symbols[i] и symbols[j], null если данных недостаточно",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "number",
+                            "format": "float64"
+                        }
+                    }
+                },
+                "samples": {
+                    "description": "количество общих интервалов для каждой пары",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "integer"
+                        }
+                    }
+                },
+                "symbols": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_currency.GetPriceRequest": {
+            "type": "object",
             "properties": {
+                "bridge_gaps": {
+                    "description": "false - не брать точки из-за пробелов в отслеживании, по умолчанию true",
+                    "type": "boolean"
+                },
+                "coin": {
+                    "description": "синоним symbol из исходного ТЗ",
+                    "type": "string"
+                },
+                "interpolate": {
+                    "description": "интерполировать между соседними точками вместо выбора ближайшей",
+                    "type": "boolean"
+                },
                 "symbol": {
                     "type": "string",
-                    "maxLength": 10
+                    "example": "BTC"
                 },
                 "timestamp": {
-                    "type": "string"
+                    "description": "Unix-секунды, миллисекунды или RFC3339",
+                    "type": "string",
+                    "example": "1736500490"
                 }
             }
         },
-        "internal_handlers_currency.PriceResponse": {
+        "internal_handlers_currency.LatestPriceResponse": {
             "type": "object",
             "properties": {
+                "age_seconds": {
+                    "description": "сколько секунд прошло с момента цены",
+                    "type": "number"
+                },
                 "price": {
                     "type": "number"
                 },
+                "source": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_currency.LookupItem": {
+            "type": "object",
+            "properties": {
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "1736500490"
+                }
+            }
+        },
+        "internal_handlers_currency.LookupRequest": {
+            "type": "object",
+            "properties": {
+                "bridge_gaps": {
+                    "description": "false - не брать точки из-за пробелов в отслеживании, по умолчанию true",
+                    "type": "boolean"
+                },
+                "interpolate": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers_currency.LookupItem"
+                    }
+                }
+            }
+        },
+        "internal_handlers_currency.LookupResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers_currency.LookupResult"
+                    }
+                }
+            }
+        },
+        "internal_handlers_currency.LookupResult": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "result": {
+                    "$ref": "#/definitions/affarm_internal_service.PriceResponse"
+                },
                 "symbol": {
                     "type": "string"
                 }
             }
         },
+        "internal_handlers_currency.PriceRangeResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/affarm_internal_service.PricePoint"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
         "internal_handlers_currency.RemoveCurrencyRequest": {
             "type": "object",
             "properties": {
                 "id": {
                     "type": "integer"
                 },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_currency.StatsPoint": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "description": "последняя цена в интервале",
+                    "type": "number"
+                },
+                "drawdown": {
+                    "description": "отношение к предыдущему максимуму минус 1",
+                    "type": "number"
+                },
+                "ema": {
+                    "type": "number"
+                },
+                "log_return": {
+                    "type": "number"
+                },
+                "simple_return": {
+                    "description": "пусто, если в предыдущем интервале не было цен",
+                    "type": "number"
+                },
+                "sma": {
+                    "description": "пусто, пока не накоплено window точек",
+                    "type": "number"
+                },
+                "timestamp": {
+                    "description": "начало интервала",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_currency.StatsResponse": {
+            "type": "object",
+            "properties": {
+                "annualized_volatility": {
+                    "description": "volatility * sqrt(интервалов в году)",
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "max_drawdown": {
+                    "type": "number"
+                },
+                "mean": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers_currency.StatsPoint"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total_return": {
+                    "type": "number"
+                },
+                "volatility": {
+                    "description": "стандартное отклонение лог-доходностей соседних интервалов",
+                    "type": "number"
+                },
+                "window": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handlers_portfolio.CreatePortfolioRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers_portfolio.PositionDTO"
+                    }
+                }
+            }
+        },
+        "internal_handlers_portfolio.HistoryPoint": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "complete": {
+                    "type": "boolean"
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "total": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_portfolio.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers_portfolio.HistoryPoint"
+                    }
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "step": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_portfolio.HoldingValue": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "price_timestamp": {
+                    "type": "string"
+                },
+                "quality": {
+                    "type": "string",
+                    "enum": [
+                        "exact",
+                        "nearest",
+                        "stale"
+                    ]
+                },
+                "quantity": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_portfolio.PortfolioResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers_portfolio.PositionDTO"
+                    }
+                }
+            }
+        },
+        "internal_handlers_portfolio.PositionDTO": {
+            "type": "object",
+            "required": [
+                "symbol"
+            ],
+            "properties": {
+                "quantity": {
+                    "type": "string",
+                    "example": "2.5"
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 10
+                }
+            }
+        },
+        "internal_handlers_portfolio.TransactionDTO": {
+            "type": "object",
+            "required": [
+                "executed_at",
+                "symbol"
+            ],
+            "properties": {
+                "executed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "quantity": {
+                    "type": "string",
+                    "example": "-0.5"
+                },
                 "symbol": {
                     "type": "string",
                     "maxLength": 10
                 }
             }
+        },
+        "internal_handlers_portfolio.ValuationResponse": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "complete": {
+                    "type": "boolean"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "holdings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handlers_portfolio.HoldingValue"
+                    }
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handlers_stream.PriceMessage": {
+            "type": "object",
+            "properties": {
+                "currency_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "price_id": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
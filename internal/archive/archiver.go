@@ -0,0 +1,297 @@
+package archive
+
+import (
+	"affarm/config"
+	"affarm/internal/database"
+	"affarm/internal/models"
+	"affarm/internal/pricefile"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ManifestKey - ключ файла манифеста в хранилище. Он повторяет таблицу price_archives,
+// чтобы архив можно было разобрать без бд
+const ManifestKey = "manifest.json"
+
+// exportSQL выбирает строки секции в порядке хранения в файле; %q - имя секции
+const exportSQL = `
+SELECT c.symbol, p.currency_id, p."timestamp", p.price::text, p.source, p.volume
+FROM %q p
+JOIN currencies c ON c.id = p.currency_id
+ORDER BY p.currency_id, p."timestamp"`
+
+// Archiver выгружает закрытые месячные секции prices в хранилище и записывает их в манифест.
+// Секция выгружается, когда ее месяц закончился не меньше AfterMonths месяцев назад
+type Archiver struct {
+	db          *gorm.DB
+	store       Store
+	cfg         config.ArchiveConfig
+	stopChannel chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewArchiver - конструктор архиватора
+func NewArchiver(db *gorm.DB, store Store, cfg config.ArchiveConfig) (*Archiver, error) {
+	if cfg.Format != pricefile.FormatParquet && cfg.Format != pricefile.FormatCSV {
+		return nil, fmt.Errorf("неизвестный формат архива %q, ожидается parquet или csv", cfg.Format)
+	}
+	return &Archiver{db: db, store: store, cfg: cfg, stopChannel: make(chan struct{})}, nil
+}
+
+// Start запускает выгрузку сразу и затем с интервалом из конфига
+func (a *Archiver) Start() {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(time.Duration(a.cfg.IntervalMin) * time.Minute)
+		defer ticker.Stop()
+		for {
+			if err := a.Run(context.Background()); err != nil {
+				log.Printf("ошибка выгрузки архива цен: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-a.stopChannel:
+				return
+			}
+		}
+	}()
+	log.Printf("Архивация цен запущена, формат %s, хранилище %s", a.cfg.Format, a.cfg.Store.Type)
+}
+
+// Stop останавливает выгрузку и дожидается текущего прохода
+func (a *Archiver) Stop() {
+	close(a.stopChannel)
+	a.wg.Wait()
+}
+
+// Run выгружает все закрытые и еще не выгруженные секции по возрастанию периода
+func (a *Archiver) Run(ctx context.Context) error {
+	partitions, err := a.partitions(ctx)
+	if err != nil {
+		return err
+	}
+	archived, err := a.manifest(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := a.cutoff(time.Now())
+	for _, partition := range partitions {
+		if partition.To.After(cutoff) {
+			break
+		}
+		entry, ok := archived[partition.Name]
+		if !ok {
+			if entry, err = a.archive(ctx, partition); err != nil {
+				return err
+			}
+			log.Printf("Секция %s выгружена в %s, строк: %d", partition.Name, entry.Key, entry.Rows)
+		}
+		// Секция уже в манифесте, но осталась в бд, если прошлый проход прервался до удаления
+		if a.cfg.DropPartitions {
+			if err := a.drop(ctx, entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ArchivedUntil возвращает начало самой ранней закрытой секции, которая еще не выгружена:
+// до этого момента сырые точки уже сохранены в архиве. false - невыгруженных секций нет.
+// Подходит для database.Retention.WithRawLimit: для гипертаблицы возвращается ошибка, и свертка
+// не удаляет сырые точки, которые архиватор не может выгрузить
+func (a *Archiver) ArchivedUntil(ctx context.Context) (time.Time, bool, error) {
+	partitions, err := a.partitions(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	archived, err := a.manifest(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	for _, partition := range partitions {
+		if _, ok := archived[partition.Name]; !ok {
+			return partition.From, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// partitions возвращает месячные секции prices или database.ErrPricesNotPartitioned, если их нет
+// (гипертаблица TimescaleDB): пустой список означал бы, что выгружать нечего
+func (a *Archiver) partitions(ctx context.Context) ([]database.PricePartition, error) {
+	partitioned, err := database.PricesPartitioned(ctx, a.db)
+	if err != nil {
+		return nil, err
+	}
+	if !partitioned {
+		return nil, database.ErrPricesNotPartitioned
+	}
+	return database.PricePartitions(ctx, a.db)
+}
+
+// cutoff - конец последнего месяца, секции которого уже можно выгружать
+func (a *Archiver) cutoff(now time.Time) time.Time {
+	now = now.UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return month.AddDate(0, -a.cfg.AfterMonths, 0)
+}
+
+func (a *Archiver) manifest(ctx context.Context) (map[string]models.PriceArchive, error) {
+	var entries []models.PriceArchive
+	if err := a.db.WithContext(ctx).Order("period_from").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("ошибка чтения манифеста архива: %w", err)
+	}
+	archived := make(map[string]models.PriceArchive, len(entries))
+	for _, entry := range entries {
+		archived[entry.Partition] = entry
+	}
+	return archived, nil
+}
+
+// archive выгружает секцию во временный файл, загружает его в хранилище и записывает в манифест
+func (a *Archiver) archive(ctx context.Context, partition database.PricePartition) (models.PriceArchive, error) {
+	ext, _ := pricefile.Extension(a.cfg.Format)
+	if a.cfg.Format == pricefile.FormatCSV {
+		ext += ".gz"
+	}
+	entry := models.PriceArchive{
+		Partition:  partition.Name,
+		PeriodFrom: partition.From,
+		PeriodTo:   partition.To,
+		Format:     a.cfg.Format,
+		Key:        fmt.Sprintf("prices/%d/%s%s", partition.From.Year(), partition.Name, ext),
+	}
+
+	tmp, err := os.CreateTemp("", "affarm-archive-*")
+	if err != nil {
+		return entry, fmt.Errorf("ошибка создания временного файла архива: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	if entry.Rows, err = a.export(ctx, partition.Name, io.MultiWriter(tmp, hash)); err != nil {
+		return entry, fmt.Errorf("ошибка выгрузки секции %s: %w", partition.Name, err)
+	}
+	if entry.Bytes, err = tmp.Seek(0, io.SeekCurrent); err != nil {
+		return entry, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return entry, err
+	}
+	entry.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if err := a.store.Put(ctx, entry.Key, tmp, entry.Bytes); err != nil {
+		return entry, err
+	}
+	if err := a.db.WithContext(ctx).Create(&entry).Error; err != nil {
+		return entry, fmt.Errorf("ошибка записи манифеста архива: %w", err)
+	}
+	return entry, a.writeManifest(ctx)
+}
+
+// export пишет строки секции в w в формате архива и возвращает их количество
+func (a *Archiver) export(ctx context.Context, partition string, w io.Writer) (int64, error) {
+	rows, err := a.db.WithContext(ctx).Raw(fmt.Sprintf(exportSQL, partition)).Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	// CSV сжимается gzip целиком, parquet сжимает колонки сам
+	var zw *gzip.Writer
+	if a.cfg.Format == pricefile.FormatCSV {
+		zw = gzip.NewWriter(w)
+		w = zw
+	}
+	writer, err := pricefile.NewWriter(a.cfg.Format, w)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for rows.Next() {
+		var (
+			row    pricefile.Row
+			volume sql.NullFloat64
+		)
+		if err := rows.Scan(&row.Symbol, &row.CurrencyID, &row.Timestamp, &row.Price, &row.Source, &volume); err != nil {
+			writer.Close()
+			return count, err
+		}
+		row.Timestamp = row.Timestamp.UTC()
+		if volume.Valid {
+			row.Volume = &volume.Float64
+		}
+		if err := writer.Write(row); err != nil {
+			writer.Close()
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		writer.Close()
+		return count, err
+	}
+	if err := writer.Close(); err != nil {
+		return count, err
+	}
+	if zw != nil {
+		return count, zw.Close()
+	}
+	return count, nil
+}
+
+// drop удаляет выгруженную секцию, если в ней столько же строк, сколько попало в архив.
+// Строки, дописанные в закрытый месяц после выгрузки, не теряются: секция остается
+func (a *Archiver) drop(ctx context.Context, entry models.PriceArchive) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var exists bool
+		if err := tx.Raw("SELECT to_regclass(?) IS NOT NULL", entry.Partition).Scan(&exists).Error; err != nil || !exists {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf(`LOCK TABLE %q IN ACCESS EXCLUSIVE MODE`, entry.Partition)).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Raw(fmt.Sprintf(`SELECT count(*) FROM %q`, entry.Partition)).Scan(&count).Error; err != nil {
+			return err
+		}
+		if count != entry.Rows {
+			log.Printf("Секция %s не удалена: в ней %d строк, в архиве %d", entry.Partition, count, entry.Rows)
+			return nil
+		}
+		if err := database.DropPricePartition(ctx, tx, entry.Partition); err != nil {
+			return err
+		}
+		log.Printf("Выгруженная секция %s удалена из бд", entry.Partition)
+		return nil
+	})
+}
+
+// writeManifest перезаписывает манифест в хранилище по таблице price_archives
+func (a *Archiver) writeManifest(ctx context.Context) error {
+	var entries []models.PriceArchive
+	if err := a.db.WithContext(ctx).Order("period_from").Find(&entries).Error; err != nil {
+		return fmt.Errorf("ошибка чтения манифеста архива: %w", err)
+	}
+	body, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.store.Put(ctx, ManifestKey, bytes.NewReader(body), int64(len(body)))
+}
@@ -0,0 +1,254 @@
+package archive
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/pricefile"
+	"affarm/internal/repository"
+	"compress/gzip"
+	"container/list"
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxArchiveSteps - сколько архивных файлов назад просматривается в поиске предыдущей точки
+const maxArchiveSteps = 2
+
+// manifestTTL - как долго манифест архива используется без перечитывания из бд
+const manifestTTL = time.Minute
+
+// Reader ищет точки рядов в архивных файлах. Ряд одной валюты из одного файла
+// читается целиком и держится в LRU-кеше, чтобы соседние запросы не перечитывали файл
+type Reader struct {
+	db    *gorm.DB
+	store Store
+
+	mu             sync.Mutex
+	limit          int
+	order          *list.List // ключи seriesKey, в начале - недавно использованные
+	entries        map[seriesKey]*list.Element
+	manifest       []models.PriceArchive // по возрастанию period_from
+	manifestLoaded time.Time
+}
+
+type seriesKey struct {
+	key        string
+	currencyID uint
+}
+
+type seriesEntry struct {
+	key    seriesKey
+	points []repository.Point
+}
+
+// NewReader - конструктор чтения архива, limit - сколько рядов держать в памяти
+func NewReader(db *gorm.DB, store Store, limit int) *Reader {
+	return &Reader{db: db, store: store, limit: limit, order: list.New(), entries: make(map[seriesKey]*list.Element)}
+}
+
+// Neighbors ищет в архиве соседние точки валюты symbol для момента at. Строки архива сопоставляются
+// по currency_id, а не по символу: валюта, добавленная заново после удаления, не получит чужую историю.
+// Файлы просматриваются от покрывающего at назад, пока не найдется предыдущая точка
+func (r *Reader) Neighbors(ctx context.Context, symbol string, at time.Time) (repository.Neighbors, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Currency{}).Where("symbol = ?", symbol).Pluck("id", &ids).Error
+	if err != nil {
+		return repository.Neighbors{}, fmt.Errorf("ошибка поиска валюты: %w", err)
+	}
+	if len(ids) == 0 {
+		return repository.Neighbors{}, nil
+	}
+	currencyID := ids[0]
+
+	manifest, err := r.loadManifest(ctx)
+	if err != nil {
+		return repository.Neighbors{}, err
+	}
+	// Файлы, начинающиеся не позже at, от последнего к первому
+	last := sort.Search(len(manifest), func(i int) bool { return manifest[i].PeriodFrom.After(at) })
+	entries := make([]models.PriceArchive, 0, maxArchiveSteps)
+	for i := last - 1; i >= 0 && len(entries) < maxArchiveSteps; i-- {
+		entries = append(entries, manifest[i])
+	}
+
+	var result repository.Neighbors
+	for _, entry := range entries {
+		points, err := r.series(ctx, entry, currencyID)
+		if err != nil {
+			return result, err
+		}
+		// Первая точка строго позже at; все точки до нее - не позже at
+		after := sort.Search(len(points), func(i int) bool { return points[i].Timestamp.After(at) })
+		if result.After == nil && after < len(points) {
+			result.After = &points[after]
+		}
+		if after > 0 {
+			result.Before = &points[after-1]
+			if result.Before.Timestamp.Equal(at) {
+				result.After = result.Before
+			}
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
+// loadManifest возвращает манифест, перечитывая его из бд не чаще раза в manifestTTL
+func (r *Reader) loadManifest(ctx context.Context) ([]models.PriceArchive, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.manifestLoaded) < manifestTTL {
+		return r.manifest, nil
+	}
+
+	var manifest []models.PriceArchive
+	if err := r.db.WithContext(ctx).Order("period_from").Find(&manifest).Error; err != nil {
+		return nil, fmt.Errorf("ошибка чтения манифеста архива: %w", err)
+	}
+	r.manifest, r.manifestLoaded = manifest, time.Now()
+	return manifest, nil
+}
+
+// Forget убирает из кеша ряды валюты, например после ее безвозвратного удаления
+func (r *Reader) Forget(currencyID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, element := range r.entries {
+		if key.currencyID == currencyID {
+			r.order.Remove(element)
+			delete(r.entries, key)
+		}
+	}
+}
+
+// series возвращает точки валюты currencyID из архивного файла entry по возрастанию времени
+func (r *Reader) series(ctx context.Context, entry models.PriceArchive, currencyID uint) ([]repository.Point, error) {
+	key := seriesKey{key: entry.Key, currencyID: currencyID}
+	r.mu.Lock()
+	if element, ok := r.entries[key]; ok {
+		r.order.MoveToFront(element)
+		r.mu.Unlock()
+		return element.Value.(*seriesEntry).points, nil
+	}
+	r.mu.Unlock()
+
+	points, err := r.load(ctx, entry, currencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[key]; !ok {
+		r.entries[key] = r.order.PushFront(&seriesEntry{key: key, points: points})
+		if r.order.Len() > r.limit {
+			oldest := r.order.Back()
+			r.order.Remove(oldest)
+			delete(r.entries, oldest.Value.(*seriesEntry).key)
+		}
+	}
+	return points, nil
+}
+
+func (r *Reader) load(ctx context.Context, entry models.PriceArchive, currencyID uint) ([]repository.Point, error) {
+	object, err := r.store.Get(ctx, entry.Key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	var source io.Reader = object
+	switch {
+	case entry.Format == pricefile.FormatCSV:
+		zr, err := gzip.NewReader(object)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения архива %s: %w", entry.Key, err)
+		}
+		defer zr.Close()
+		source = zr
+	case !isFile(object):
+		// Parquet читается с произвольным доступом: файл из удаленного хранилища сначала скачивается
+		tmp, err := os.CreateTemp("", "affarm-archive-*")
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания временного файла архива: %w", err)
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if _, err := io.Copy(tmp, object); err != nil {
+			return nil, fmt.Errorf("ошибка скачивания архива %s: %w", entry.Key, err)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		source = tmp
+	}
+
+	var points []repository.Point
+	err = pricefile.Read(entry.Format, source, func(row pricefile.Row) error {
+		if row.CurrencyID != int64(currencyID) {
+			return nil
+		}
+		price, err := decimal.NewFromString(row.Price)
+		if err != nil {
+			return fmt.Errorf("неверная цена %q: %w", row.Price, err)
+		}
+		points = append(points, repository.Point{Price: price, Timestamp: row.Timestamp.UTC(), Source: row.Source})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения архива %s: %w", entry.Key, err)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Timestamp.Before(points[j].Timestamp) })
+	return points, nil
+}
+
+func isFile(r io.Reader) bool {
+	_, ok := r.(*os.File)
+	return ok
+}
+
+// ReadThrough - хранилище, которое дополняет поиск цены на момент времени архивом:
+// если в бд для момента нет предыдущей сырой точки (она выгружена или осталась только в свертках),
+// соседние точки ищутся в архивных файлах
+type ReadThrough struct {
+	repository.Repository
+	reader *Reader
+}
+
+// NewReadThrough - конструктор хранилища с чтением из архива
+func NewReadThrough(repo repository.Repository, reader *Reader) *ReadThrough {
+	return &ReadThrough{Repository: repo, reader: reader}
+}
+
+func (r *ReadThrough) Neighbors(ctx context.Context, queries []repository.PriceQuery) ([]repository.Neighbors, error) {
+	result, err := r.Repository.Neighbors(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, neighbors := range result {
+		if !neighbors.CurrencyFound || (neighbors.Before != nil && neighbors.Before.Resolution == 0) {
+			continue
+		}
+		archived, err := r.reader.Neighbors(ctx, queries[i].Symbol, queries[i].At.UTC())
+		if err != nil {
+			return nil, err
+		}
+		if archived.Before == nil {
+			continue
+		}
+		// Последующая точка из бд остается, если она ближе и не из свертки
+		result[i].Before = archived.Before
+		if archived.After != nil && (neighbors.After == nil || neighbors.After.Resolution > 0 ||
+			archived.After.Timestamp.Before(neighbors.After.Timestamp)) {
+			result[i].After = archived.After
+		}
+	}
+	return result, nil
+}
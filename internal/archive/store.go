@@ -0,0 +1,133 @@
+// Package archive выгружает закрытые месячные секции prices в файлы Parquet или CSV
+// в локальный каталог или S3-совместимое хранилище, ведет манифест выгрузок
+// и позволяет искать старые цены в выгруженных файлах
+package archive
+
+import (
+	"affarm/config"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ErrObjectNotFound - файла с таким ключом в хранилище нет
+var ErrObjectNotFound = errors.New("архивный файл не найден")
+
+// Store - хранилище архивных файлов. Ключи - пути через "/", например prices/2025/prices_2025_01.parquet
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// NewStore создает хранилище по конфигу
+func NewStore(ctx context.Context, cfg config.ArchiveStoreConfig) (Store, error) {
+	switch cfg.Type {
+	case "local":
+		return &localStore{dir: cfg.Dir}, nil
+	case "s3":
+		return newS3Store(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("неизвестный тип архивного хранилища %q, ожидается local или s3", cfg.Type)
+	}
+}
+
+// localStore хранит файлы в каталоге. Файл сначала пишется во временный и затем переименовывается,
+// чтобы недописанный файл не был виден под своим ключом
+type localStore struct {
+	dir string
+}
+
+func (s *localStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("ошибка создания каталога архива: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла архива: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка записи файла архива %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка записи файла архива %s: %w", key, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *localStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	return file, err
+}
+
+// s3Store хранит файлы в бакете S3-совместимого хранилища (AWS S3, MinIO и т.п.)
+type s3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func newS3Store(ctx context.Context, cfg config.ArchiveStoreConfig) (*s3Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("для архива s3 нужны endpoint и bucket")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(os.Getenv(cfg.AccessKeyEnv), os.Getenv(cfg.SecretKeyEnv), ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к s3 %s: %w", cfg.Endpoint, err)
+	}
+
+	// Бакет создается при первом запуске, чтобы локальный MinIO работал без ручной настройки
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки бакета %s: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("ошибка создания бакета %s: %w", cfg.Bucket, err)
+		}
+	}
+	return &s3Store{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.bucket, path.Join(s.prefix, key), r, size, minio.PutObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки %s в s3: %w", key, err)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, path.Join(s.prefix, key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения %s из s3: %w", key, err)
+	}
+	// GetObject не обращается к хранилищу до первого чтения, отсутствие файла проверяется через Stat
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("ошибка чтения %s из s3: %w", key, err)
+	}
+	return object, nil
+}
@@ -1,8 +1,16 @@
 package database
 
 import (
+	"affarm/config"
 	"affarm/internal/models"
+	"affarm/internal/repository"
+	"affarm/internal/repository/gormrepo"
+	"affarm/internal/repository/memory"
+	pgrepo "affarm/internal/repository/postgres"
+	"context"
+	"errors"
 	"fmt"
+	"github.com/glebarez/sqlite"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -27,6 +35,136 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// Open открывает хранилище, выбранное в конфиге. Кроме хранилища валют и цен возвращается
+// GORM-подключение для остальных таблиц (портфели, алерты, outbox): для memory это SQLite в памяти
+func Open(cfg config.StorageConfig) (*gorm.DB, repository.Repository, error) {
+	switch cfg.Driver {
+	case "postgres":
+		db, err := GetGormDB()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := prepareSchema(db, cfg.MigrateOnStart); err != nil {
+			return nil, nil, err
+		}
+		return db, pgrepo.New(db), nil
+	case "sqlite":
+		db, err := OpenSQLite(cfg.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, gormrepo.New(db), nil
+	case "memory":
+		db, err := OpenSQLite(":memory:")
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, memory.New(), nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестное хранилище %q, ожидается postgres, sqlite или memory", cfg.Driver)
+	}
+}
+
+// prepareSchema применяет миграции, если это разрешено конфигом, и отказывается работать
+// со схемой, которая новее бинарника или отстает от него
+func prepareSchema(db *gorm.DB, migrate bool) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке подключения к бд: %w", err)
+	}
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if migrate {
+		if _, err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("ошибка при миграции бд: %w", err)
+		}
+	}
+	if err := migrator.Check(ctx); err != nil {
+		if errors.Is(err, ErrPendingMigrations) {
+			return fmt.Errorf("%w; выполните migrate up или включите storage.migrate_on_start", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// OpenSQLite открывает файл SQLite (или базу в памяти) и создает таблицы через AutoMigrate:
+// версионные миграции написаны для PostgreSQL, а SQLite используется только для разработки
+func OpenSQLite(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(path), gormConfig())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия sqlite %s: %w", path, err)
+	}
+
+	// SQLite не поддерживает параллельную запись, а база в памяти живет только в своем соединении
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при проверке подключения к sqlite: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	// Файлы, созданные до уникального индекса на prices, могут содержать дубли, и AutoMigrate
+	// не построит индекс. SQLite - база разработки, поэтому дубли убираются сразу
+	if db.Migrator().HasTable(&models.Price{}) {
+		removed, err := DedupePrices(context.Background(), db, false)
+		if err != nil {
+			return nil, err
+		}
+		if removed > 0 {
+			log.Printf("Удалено повторных точек цен: %d", removed)
+		}
+	}
+
+	if err := autoMigrate(db); err != nil {
+		return nil, fmt.Errorf("ошибка при миграции sqlite: %w", err)
+	}
+	// Валютам из баз, созданных до истории отслеживания, достается период от добавления до удаления, как в миграции 0006
+	if err := db.Exec(backfillTrackingSQLite).Error; err != nil {
+		return nil, fmt.Errorf("ошибка заполнения истории отслеживания: %w", err)
+	}
+	// Удаленные валюты из баз до колонки status получают состояние archived, как в миграции 0007
+	if err := db.Exec("UPDATE currencies SET status = 'archived' WHERE deleted_at IS NOT NULL AND status <> 'archived'").Error; err != nil {
+		return nil, fmt.Errorf("ошибка заполнения состояния валют: %w", err)
+	}
+	return db, nil
+}
+
+const backfillTrackingSQLite = `
+INSERT INTO tracking_periods (currency_id, started_at, started_by, stopped_at, stopped_by)
+SELECT c.id, c.created_at, 'migration', c.deleted_at, CASE WHEN c.deleted_at IS NULL THEN '' ELSE 'migration' END
+FROM currencies c
+WHERE NOT EXISTS (SELECT 1 FROM tracking_periods t WHERE t.currency_id = c.id)`
+
+func gormConfig() *gorm.Config {
+	return &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		//Logger: logger.Default.LogMode(logger.Info), // полное логирование запросов
+		TranslateError: true, // нарушение уникальности приходит как gorm.ErrDuplicatedKey
+	}
+}
+
+func autoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&models.Currency{},
+		&models.Price{},
+		&models.PriceAggregate{},
+		&models.PriceArchive{},
+		&models.TrackingPeriod{},
+		&models.PurgeAudit{},
+		&models.Portfolio{},
+		&models.Position{},
+		&models.Transaction{},
+		&models.AlertRule{},
+		&models.AlertDelivery{},
+		&models.OutboxEvent{},
+		&models.OutboxCursor{},
+	)
+}
+
 // GetGormDB возвращает подключение к PostgreSQL с GORM
 func GetGormDB() (*gorm.DB, error) {
 	port, _ := strconv.Atoi(getEnv("PG_PORT", "5432"))
@@ -41,23 +179,11 @@ func GetGormDB() (*gorm.DB, error) {
 		"disable",
 	)
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-		//Logger: logger.Default.LogMode(logger.Info), // полное логирование запросов
-	})
+	db, err := gorm.Open(postgres.Open(dsn), gormConfig())
 	if err != nil {
 		return nil, fmt.Errorf("ошибка подключения к бд: %w", err)
 	}
 
-	// Автомиграция структур
-	err = db.AutoMigrate(
-		&models.Currency{},
-		&models.Price{},
-	)
-	if err != nil {
-		panic("ошибка при миграции бд")
-	}
-
 	// Настройка пула соединений
 	sqlDB, err := db.DB()
 	if err != nil {
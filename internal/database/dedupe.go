@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// duplicatePrices - условие для строки prices, у которой есть более поздняя запись на тот же момент.
+// Из дублей остается строка с наибольшим id, как при upsert, где последняя запись заменяет прежнюю
+const duplicatePrices = `EXISTS (
+    SELECT 1 FROM prices newer
+    WHERE newer.currency_id = prices.currency_id
+      AND newer.timestamp = prices.timestamp
+      AND newer.id > prices.id)`
+
+// DedupePrices удаляет повторные точки рядов цен с одинаковыми (currency_id, timestamp),
+// оставляя последнюю записанную. Нужен один раз перед построением уникального индекса.
+// При dryRun только считает строки, которые были бы удалены
+func DedupePrices(ctx context.Context, db *gorm.DB, dryRun bool) (int64, error) {
+	db = db.WithContext(ctx)
+	if dryRun {
+		var count int64
+		if err := db.Raw("SELECT count(*) FROM prices WHERE " + duplicatePrices).Scan(&count).Error; err != nil {
+			return 0, fmt.Errorf("ошибка поиска дублей цен: %w", err)
+		}
+		return count, nil
+	}
+
+	result := db.Exec("DELETE FROM prices WHERE " + duplicatePrices)
+	if result.Error != nil {
+		return 0, fmt.Errorf("ошибка удаления дублей цен: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,347 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Миграции схемы PostgreSQL. Файлы называются NNNN_name.up.sql и NNNN_name.down.sql.
+// Миграция с первой строкой "-- migrate:no-transaction" выполняется вне транзакции
+// (нужно для CREATE INDEX CONCURRENTLY) по одному оператору: операторы в таком файле
+// разделяются ";" в конце строки, поэтому тела функций в них не допускаются
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const noTransactionDirective = "-- migrate:no-transaction"
+
+// migrationsLockID - ключ advisory lock, чтобы миграции не выполнялись параллельно из разных процессов
+const migrationsLockID = 7_252_610_041
+
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrSchemaTooNew - схема БД новее, чем миграции, известные этому бинарнику
+var ErrSchemaTooNew = errors.New("схема бд новее версии приложения")
+
+// ErrPendingMigrations - в БД применены не все миграции бинарника
+var ErrPendingMigrations = errors.New("есть непримененные миграции")
+
+// Migration - версия схемы с SQL применения и отката
+type Migration struct {
+	Version       int
+	Name          string
+	Up            string
+	Down          string
+	NoTransaction bool
+}
+
+// MigrationStatus - миграция и момент ее применения, пустой если не применена
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations возвращает встроенные миграции по возрастанию версии
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("неверное имя файла миграции %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("у миграции %d разные имена: %s и %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+			m.NoTransaction = strings.HasPrefix(m.Up, noTransactionDirective)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("у миграции %04d_%s нет up или down файла", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator применяет и откатывает миграции, ведя учет в таблице schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator - конструктор мигратора со встроенными миграциями
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest возвращает версию последней известной миграции
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status возвращает все известные миграции с отметкой о применении
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	status := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		status[i].Migration = migration
+		if at, ok := applied[migration.Version]; ok {
+			status[i].AppliedAt = &at
+		}
+	}
+	return status, nil
+}
+
+// Check проверяет, что схема совпадает с версией приложения.
+// Возвращает ErrSchemaTooNew или ErrPendingMigrations с подробностями
+func (m *Migrator) Check(ctx context.Context) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := m.checkNewer(applied); err != nil {
+		return err
+	}
+	var pending []string
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ErrPendingMigrations, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// checkNewer возвращает ErrSchemaTooNew, если в БД есть миграция новее известных бинарнику
+func (m *Migrator) checkNewer(applied map[int]time.Time) error {
+	for version := range applied {
+		if version > m.Latest() {
+			return fmt.Errorf("%w: применена миграция %d, приложение знает до %d", ErrSchemaTooNew, version, m.Latest())
+		}
+	}
+	return nil
+}
+
+// Up применяет все непримененные миграции по возрастанию версии и возвращает примененные
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkNewer(applied); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			log.Printf("Применение миграции %04d_%s", migration.Version, migration.Name)
+			err := m.run(ctx, conn, migration.Up, migration.NoTransaction,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("миграция %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down откатывает steps последних примененных миграций и возвращает откаченные
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		// Откатывать миграции из-под более новой схемы нельзя: ее новые части этому бинарнику неизвестны
+		if err := m.checkNewer(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			log.Printf("Откат миграции %04d_%s", migration.Version, migration.Name)
+			err := m.run(ctx, conn, migration.Down, strings.HasPrefix(migration.Down, noTransactionDirective),
+				"DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+			if err != nil {
+				return fmt.Errorf("откат %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// locked выполняет fn на отдельном соединении под advisory lock, создав таблицу учета миграций
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationsLockID); err != nil {
+		return fmt.Errorf("ошибка блокировки миграций: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationsLockID)
+
+	_, err = conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version    bigint PRIMARY KEY,
+            name       text NOT NULL,
+            applied_at timestamptz NOT NULL DEFAULT now()
+        )`)
+	if err != nil {
+		return fmt.Errorf("ошибка создания schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+// run выполняет SQL миграции и запись в schema_migrations. В транзакционном режиме все
+// выполняется в одной транзакции; иначе операторы выполняются по одному, а учет пишется последним
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, body string, noTx bool, record string, args ...any) error {
+	if noTx {
+		for _, statement := range splitStatements(body) {
+			if _, err := conn.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+		_, err := conn.ExecContext(ctx, record, args...)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// splitStatements делит SQL на операторы по ";" в конце строки, пропуская пустые
+func splitStatements(body string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(body, "\n") {
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			if statement := strings.TrimSpace(current.String()); !isComment(statement) {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+		}
+	}
+	if statement := strings.TrimSpace(current.String()); statement != "" && !isComment(statement) {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// isComment сообщает, что фрагмент состоит только из комментариев
+func isComment(statement string) bool {
+	for _, line := range strings.Split(statement, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	applied := make(map[int]time.Time)
+	var exists bool
+	err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists)
+	if err != nil || !exists {
+		return applied, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"os"
+	"testing"
+	"time"
+)
+
+// baselineCurrency и baselinePrice - модели первой версии сервиса: такие таблицы создавал AutoMigrate
+// до версионных миграций
+type baselineCurrency struct {
+	gorm.Model
+	Symbol string          `gorm:"uniqueIndex;size:10"`
+	Prices []baselinePrice `gorm:"foreignKey:CurrencyID"`
+}
+
+func (baselineCurrency) TableName() string { return "currencies" }
+
+type baselinePrice struct {
+	gorm.Model
+	Price      float64 `gorm:"type:decimal(20,8)"`
+	Timestamp  time.Time
+	CurrencyID uint
+}
+
+func (baselinePrice) TableName() string { return "prices" }
+
+// openTestSchema открывает тестовую базу PostgreSQL с отдельной пустой схемой, которая удаляется
+// после теста. Пул ограничен одним соединением, чтобы search_path действовал на все запросы
+func openTestSchema(t *testing.T) *gorm.DB {
+	t.Helper()
+	if testing.Short() || os.Getenv("PG_HOST") == "" {
+		t.Skip("нужна тестовая база PostgreSQL: задайте PG_HOST и остальные переменные PG_*")
+	}
+
+	db, err := GetGormDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	for _, statement := range []string{"CREATE SCHEMA " + schema, "SET search_path TO " + schema + ", public"} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA " + schema + " CASCADE")
+		sqlDB.Close()
+	})
+	return db
+}
+
+// TestMigrateFromBaseline применяет все миграции к базе, созданной AutoMigrate первой версии:
+// в ней нет колонок, добавленных позже, а данные должны пережить перенос в секционированную prices
+func TestMigrateFromBaseline(t *testing.T) {
+	db := openTestSchema(t)
+	ctx := context.Background()
+
+	if err := db.AutoMigrate(&baselineCurrency{}, &baselinePrice{}); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	active := baselineCurrency{Symbol: "BTC"}
+	removed := baselineCurrency{Symbol: "ETH"}
+	for _, currency := range []*baselineCurrency{&active, &removed} {
+		if err := db.Create(currency).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, currency := range []baselineCurrency{active, active, removed} {
+		price := baselinePrice{Price: float64(100 + i), Timestamp: at.Add(time.Duration(i) * time.Minute), CurrencyID: currency.ID}
+		if err := db.Create(&price).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Delete(&removed).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var count, withSource int64
+	err = db.Raw(`SELECT count(*), count(*) FILTER (WHERE source = 'binance' AND volume IS NULL) FROM prices`).
+		Row().Scan(&count, &withSource)
+	if err != nil {
+		t.Fatalf("prices после миграций: %v", err)
+	}
+	if count != 3 || withSource != 3 {
+		t.Fatalf("prices: %d точек, %d с источником binance, ожидалось 3 и 3", count, withSource)
+	}
+
+	var status string
+	if err := db.Raw("SELECT status FROM currencies WHERE id = ?", removed.ID).Row().Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != "archived" {
+		t.Fatalf("удаленная валюта в состоянии %q, ожидалось archived", status)
+	}
+	var periods int64
+	if err := db.Raw("SELECT count(*) FROM tracking_periods").Row().Scan(&periods); err != nil {
+		t.Fatal(err)
+	}
+	if periods != 2 {
+		t.Fatalf("периодов отслеживания %d, ожидалось по одному на валюту", periods)
+	}
+
+	// Новая точка пишется уже с колонками последней схемы
+	err = db.Exec(`INSERT INTO prices (currency_id, price, "timestamp", source, volume) VALUES (?, 1, ?, 'import', 2.5)`,
+		active.ID, at.Add(time.Hour)).Error
+	if err != nil {
+		t.Fatalf("запись точки после миграций: %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS outbox_cursors;
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS alert_deliveries;
+DROP TABLE IF EXISTS alert_rules;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS positions;
+DROP TABLE IF EXISTS portfolios;
+DROP TABLE IF EXISTS prices;
+DROP TABLE IF EXISTS currencies;
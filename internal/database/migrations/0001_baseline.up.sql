@@ -0,0 +1,121 @@
+-- Схема, которую раньше создавал AutoMigrate. IF NOT EXISTS позволяет принять под версионирование
+-- базы, созданные AutoMigrate: для них миграция ничего не меняет и только записывается в schema_migrations
+
+CREATE TABLE IF NOT EXISTS currencies (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    symbol     varchar(10)
+);
+CREATE INDEX IF NOT EXISTS idx_currencies_deleted_at ON currencies (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_currencies_symbol ON currencies (symbol);
+
+CREATE TABLE IF NOT EXISTS prices (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    price       decimal(20,8),
+    timestamp   timestamptz,
+    source      varchar(32) DEFAULT 'binance',
+    volume      decimal(30,8),
+    currency_id bigint,
+    CONSTRAINT fk_prices_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
+);
+CREATE INDEX IF NOT EXISTS idx_prices_deleted_at ON prices (deleted_at);
+-- Колонки, появившиеся после первой версии AutoMigrate: в базах того времени CREATE TABLE IF NOT EXISTS
+-- их не добавит, а от них зависят запросы цен и перенос данных в 0003
+ALTER TABLE prices ADD COLUMN IF NOT EXISTS source varchar(32) DEFAULT 'binance';
+ALTER TABLE prices ADD COLUMN IF NOT EXISTS volume decimal(30,8);
+
+CREATE TABLE IF NOT EXISTS portfolios (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    name       varchar(64)
+);
+CREATE INDEX IF NOT EXISTS idx_portfolios_deleted_at ON portfolios (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolios_name ON portfolios (name);
+
+CREATE TABLE IF NOT EXISTS positions (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    portfolio_id bigint,
+    symbol       varchar(10),
+    quantity     numeric(38,18),
+    CONSTRAINT fk_portfolios_positions FOREIGN KEY (portfolio_id) REFERENCES portfolios (id)
+);
+CREATE INDEX IF NOT EXISTS idx_positions_deleted_at ON positions (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_position_portfolio_symbol ON positions (portfolio_id, symbol);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    portfolio_id bigint,
+    symbol       varchar(10),
+    quantity     numeric(38,18),
+    executed_at  timestamptz,
+    note         varchar(255),
+    CONSTRAINT fk_portfolios_transactions FOREIGN KEY (portfolio_id) REFERENCES portfolios (id)
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_id ON transactions (portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_executed_at ON transactions (executed_at);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id                bigserial PRIMARY KEY,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    deleted_at        timestamptz,
+    currency_id       bigint,
+    kind              varchar(16),
+    threshold         decimal(20,8),
+    window_sec        bigint,
+    cooldown_sec      bigint,
+    webhook           varchar(64),
+    enabled           boolean DEFAULT true,
+    last_price        decimal(20,8),
+    last_triggered_at timestamptz,
+    CONSTRAINT fk_alert_rules_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
+);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_deleted_at ON alert_rules (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_alert_rules_currency_id ON alert_rules (currency_id);
+
+CREATE TABLE IF NOT EXISTS alert_deliveries (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    deleted_at      timestamptz,
+    alert_rule_id   bigint,
+    webhook         varchar(64),
+    payload         text,
+    status          varchar(16),
+    attempts        bigint,
+    response_code   bigint,
+    last_error      text,
+    next_attempt_at timestamptz,
+    delivered_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_deleted_at ON alert_deliveries (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_alert_rule_id ON alert_deliveries (alert_rule_id);
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_status ON alert_deliveries (status);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id         bigserial PRIMARY KEY,
+    topic      varchar(64),
+    payload    text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events (created_at);
+
+CREATE TABLE IF NOT EXISTS outbox_cursors (
+    sink       varchar(64) PRIMARY KEY,
+    last_seq   bigint,
+    updated_at timestamptz
+);
@@ -0,0 +1,3 @@
+-- migrate:no-transaction
+
+DROP INDEX CONCURRENTLY IF EXISTS idx_prices_currency_timestamp;
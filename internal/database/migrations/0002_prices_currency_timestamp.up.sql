@@ -0,0 +1,8 @@
+-- migrate:no-transaction
+-- Уникальный индекс по (currency_id, timestamp): поиск цены на момент времени идет по нему,
+-- а повторная точка на тот же момент становится обновлением. Индекс строится CONCURRENTLY,
+-- не блокируя запись цен. Если в таблице уже есть дубли, построение упадет:
+-- сначала выполните migrate dedupe. Незавершенный после ошибки индекс удаляется при повторном запуске
+
+DROP INDEX CONCURRENTLY IF EXISTS idx_prices_currency_timestamp;
+CREATE UNIQUE INDEX CONCURRENTLY idx_prices_currency_timestamp ON prices (currency_id, timestamp);
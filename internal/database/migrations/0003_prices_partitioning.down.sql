@@ -0,0 +1,30 @@
+-- Возврат к обычной таблице prices: данные копируются из секций (или гипертаблицы) в новую таблицу
+
+ALTER SEQUENCE prices_id_seq OWNED BY NONE;
+
+CREATE TABLE prices_plain (
+    id          bigint NOT NULL DEFAULT nextval('prices_id_seq'),
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    price       decimal(20,8),
+    "timestamp" timestamptz,
+    source      varchar(32) DEFAULT 'binance',
+    volume      decimal(30,8),
+    currency_id bigint,
+    CONSTRAINT prices_plain_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_prices_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
+);
+
+INSERT INTO prices_plain (id, created_at, updated_at, deleted_at, price, "timestamp", source, volume, currency_id)
+SELECT id, created_at, updated_at, deleted_at, price, "timestamp", source, volume, currency_id
+FROM prices;
+
+DROP TABLE prices;
+ALTER TABLE prices_plain RENAME TO prices;
+ALTER INDEX prices_plain_pkey RENAME TO prices_pkey;
+CREATE INDEX idx_prices_deleted_at ON prices (deleted_at);
+CREATE UNIQUE INDEX idx_prices_currency_timestamp ON prices (currency_id, "timestamp");
+ALTER SEQUENCE prices_id_seq OWNED BY prices.id;
+
+DROP FUNCTION IF EXISTS prices_ensure_partitions(timestamptz, timestamptz);
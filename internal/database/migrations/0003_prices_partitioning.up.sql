@@ -0,0 +1,83 @@
+-- Секционирование prices по месяцам (UTC). Если в базе установлено расширение timescaledb,
+-- вместо встроенного секционирования prices становится гипертаблицей с месячными чанками.
+-- Ключ секционирования должен входить в первичный ключ, поэтому он становится (id, timestamp).
+-- Данные переносятся в одной транзакции: на большой таблице миграция выполняется долго
+
+-- prices_ensure_partitions создает недостающие месячные секции, покрывающие [from_ts, to_ts),
+-- и возвращает число созданных. Для гипертаблицы и несекционированной таблицы ничего не делает
+CREATE OR REPLACE FUNCTION prices_ensure_partitions(from_ts timestamptz, to_ts timestamptz)
+RETURNS integer LANGUAGE plpgsql AS $$
+DECLARE
+    -- Месяцы перебираются в timestamp без зоны, чтобы границы не зависели от TimeZone сессии
+    month_start    timestamp := date_trunc('month', from_ts AT TIME ZONE 'UTC');
+    partition_name text;
+    created        integer := 0;
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'prices'::regclass) THEN
+        RETURN 0;
+    END IF;
+    -- Несколько экземпляров сервиса не должны создавать одну секцию одновременно
+    PERFORM pg_advisory_xact_lock(hashtext('prices_ensure_partitions'));
+
+    WHILE (month_start AT TIME ZONE 'UTC') < to_ts LOOP
+        partition_name := 'prices_' || to_char(month_start, 'YYYY_MM');
+        IF to_regclass(partition_name) IS NULL THEN
+            EXECUTE format('CREATE TABLE %I PARTITION OF prices FOR VALUES FROM (%L) TO (%L)',
+                partition_name, month_start AT TIME ZONE 'UTC', (month_start + interval '1 month') AT TIME ZONE 'UTC');
+            created := created + 1;
+        END IF;
+        month_start := month_start + interval '1 month';
+    END LOOP;
+    RETURN created;
+END
+$$;
+
+DO $$
+DECLARE
+    oldest timestamptz;
+BEGIN
+    -- Точка без времени не попадает ни в одну секцию и не находится поиском по времени
+    DELETE FROM prices WHERE "timestamp" IS NULL;
+    ALTER TABLE prices ALTER COLUMN "timestamp" SET NOT NULL;
+    ALTER TABLE prices DROP CONSTRAINT prices_pkey;
+    ALTER TABLE prices ADD CONSTRAINT prices_pkey PRIMARY KEY (id, "timestamp");
+
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb') THEN
+        PERFORM create_hypertable('prices', 'timestamp',
+            chunk_time_interval => interval '1 month', migrate_data => true);
+        RETURN;
+    END IF;
+
+    -- Встроенное секционирование: новая таблица с прежними именами индексов, данные переносятся из старой
+    ALTER TABLE prices RENAME TO prices_unpartitioned;
+    ALTER INDEX prices_pkey RENAME TO prices_unpartitioned_pkey;
+    ALTER INDEX idx_prices_deleted_at RENAME TO idx_prices_unpartitioned_deleted_at;
+    ALTER INDEX idx_prices_currency_timestamp RENAME TO idx_prices_unpartitioned_currency_timestamp;
+    ALTER SEQUENCE prices_id_seq OWNED BY NONE;
+
+    CREATE TABLE prices (
+        id          bigint NOT NULL DEFAULT nextval('prices_id_seq'),
+        created_at  timestamptz,
+        updated_at  timestamptz,
+        deleted_at  timestamptz,
+        price       decimal(20,8),
+        "timestamp" timestamptz NOT NULL,
+        source      varchar(32) DEFAULT 'binance',
+        volume      decimal(30,8),
+        currency_id bigint,
+        CONSTRAINT prices_pkey PRIMARY KEY (id, "timestamp"),
+        CONSTRAINT fk_prices_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
+    ) PARTITION BY RANGE ("timestamp");
+    CREATE INDEX idx_prices_deleted_at ON prices (deleted_at);
+    CREATE UNIQUE INDEX idx_prices_currency_timestamp ON prices (currency_id, "timestamp");
+    ALTER SEQUENCE prices_id_seq OWNED BY prices.id;
+
+    SELECT min("timestamp") INTO oldest FROM prices_unpartitioned;
+    PERFORM prices_ensure_partitions(coalesce(oldest, now()), now() + interval '3 months');
+
+    INSERT INTO prices (id, created_at, updated_at, deleted_at, price, "timestamp", source, volume, currency_id)
+    SELECT id, created_at, updated_at, deleted_at, price, "timestamp", source, volume, currency_id
+    FROM prices_unpartitioned;
+    DROP TABLE prices_unpartitioned;
+END
+$$;
@@ -0,0 +1,19 @@
+-- Свертки старых цен для уровней хранения: уровень задается шагом resolution_sec.
+-- Точка уровня для поиска цены - close на момент last_at, поэтому индекс по (currency_id, last_at)
+
+CREATE TABLE IF NOT EXISTS price_aggregates (
+    currency_id    bigint NOT NULL,
+    resolution_sec bigint NOT NULL,
+    bucket         timestamptz NOT NULL,
+    open           decimal(20,8),
+    high           decimal(20,8),
+    low            decimal(20,8),
+    close          decimal(20,8),
+    volume         decimal(30,8),
+    points         bigint NOT NULL,
+    first_at       timestamptz NOT NULL,
+    last_at        timestamptz NOT NULL,
+    PRIMARY KEY (currency_id, resolution_sec, bucket),
+    CONSTRAINT fk_price_aggregates_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
+);
+CREATE INDEX IF NOT EXISTS idx_price_aggregates_currency_last_at ON price_aggregates (currency_id, last_at);
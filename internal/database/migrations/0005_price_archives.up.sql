@@ -0,0 +1,16 @@
+-- Манифест архива: месячные секции prices, выгруженные в файлы холодного хранилища
+
+CREATE TABLE IF NOT EXISTS price_archives (
+    id          bigserial PRIMARY KEY,
+    partition   varchar(64),
+    period_from timestamptz,
+    period_to   timestamptz,
+    format      varchar(16),
+    key         varchar(255),
+    rows        bigint,
+    bytes       bigint,
+    sha256      varchar(64),
+    created_at  timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_archives_partition ON price_archives (partition);
+CREATE INDEX IF NOT EXISTS idx_price_archives_period_from ON price_archives (period_from);
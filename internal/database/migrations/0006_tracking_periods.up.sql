@@ -0,0 +1,25 @@
+-- История отслеживания валют: каждый период от добавления (восстановления) до удаления
+
+CREATE TABLE IF NOT EXISTS tracking_periods (
+    id          bigserial PRIMARY KEY,
+    currency_id bigint NOT NULL,
+    started_at  timestamptz NOT NULL,
+    started_by  varchar(64) NOT NULL DEFAULT '',
+    stopped_at  timestamptz,
+    stopped_by  varchar(64) NOT NULL DEFAULT '',
+    CONSTRAINT fk_tracking_periods_currency FOREIGN KEY (currency_id) REFERENCES currencies (id)
+);
+CREATE INDEX IF NOT EXISTS idx_tracking_periods_currency_started ON tracking_periods (currency_id, started_at);
+-- Открытый период у валюты не больше одного
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tracking_periods_open ON tracking_periods (currency_id) WHERE stopped_at IS NULL;
+
+-- Прежние удаления и восстановления не сохранились: каждой валюте достается один период от добавления
+-- (или первой точки ряда, если история загружена раньше) до удаления
+INSERT INTO tracking_periods (currency_id, started_at, started_by, stopped_at, stopped_by)
+SELECT c.id,
+       COALESCE(LEAST(c.created_at, (SELECT p."timestamp" FROM prices p WHERE p.currency_id = c.id ORDER BY p."timestamp" LIMIT 1)), now()),
+       'migration',
+       c.deleted_at,
+       CASE WHEN c.deleted_at IS NULL THEN '' ELSE 'migration' END
+FROM currencies c
+WHERE NOT EXISTS (SELECT 1 FROM tracking_periods t WHERE t.currency_id = c.id);
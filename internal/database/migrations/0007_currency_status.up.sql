@@ -0,0 +1,7 @@
+-- Явное состояние отслеживания валюты: active, paused (сбор приостановлен) или archived (удалена)
+
+ALTER TABLE currencies ADD COLUMN IF NOT EXISTS status varchar(16) NOT NULL DEFAULT 'active';
+UPDATE currencies SET status = 'archived' WHERE deleted_at IS NOT NULL;
+ALTER TABLE currencies DROP CONSTRAINT IF EXISTS chk_currencies_status;
+ALTER TABLE currencies ADD CONSTRAINT chk_currencies_status CHECK (status IN ('active', 'paused', 'archived'));
+CREATE INDEX IF NOT EXISTS idx_currencies_status ON currencies (status);
@@ -0,0 +1,19 @@
+-- Журнал безвозвратного удаления валют вместе с историей цен. Ссылки на currencies нет: запись переживает валюту
+
+CREATE TABLE IF NOT EXISTS purge_audits (
+    id               bigserial PRIMARY KEY,
+    symbol           varchar(10),
+    currency_id      bigint,
+    actor            varchar(64),
+    reason           varchar(255),
+    status           varchar(16),
+    error            text,
+    prices           bigint,
+    aggregates       bigint,
+    tracking_periods bigint,
+    alert_rules      bigint,
+    alert_deliveries bigint,
+    started_at       timestamptz,
+    finished_at      timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_purge_audits_symbol ON purge_audits (symbol);
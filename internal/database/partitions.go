@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// pricePartitionLayout - шаблон имени месячной секции prices для time.Parse
+const pricePartitionLayout = "prices_2006_01"
+
+// PricePartition - месячная секция prices, покрывающая [From, To)
+type PricePartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// ErrPricesNotPartitioned - prices не секционирована встроенными средствами PostgreSQL
+// (например, стала гипертаблицей TimescaleDB), и месячных секций у нее нет
+var ErrPricesNotPartitioned = errors.New("таблица prices не секционирована по месяцам")
+
+// PricesPartitioned сообщает, что prices секционирована встроенными средствами по месяцам, а не гипертаблица
+func PricesPartitioned(ctx context.Context, db *gorm.DB) (bool, error) {
+	var partitioned bool
+	err := db.WithContext(ctx).Raw(`SELECT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = 'prices'::regclass)`).
+		Scan(&partitioned).Error
+	if err != nil {
+		return false, fmt.Errorf("ошибка проверки секционирования prices: %w", err)
+	}
+	return partitioned, nil
+}
+
+// PricePartitions возвращает месячные секции prices по возрастанию периода.
+// Для гипертаблицы TimescaleDB имена чанков не совпадают с шаблоном, и список пуст
+func PricePartitions(ctx context.Context, db *gorm.DB) ([]PricePartition, error) {
+	var names []string
+	err := db.WithContext(ctx).Raw(`
+        SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'prices'::regclass`).Scan(&names).Error
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения секций prices: %w", err)
+	}
+
+	partitions := make([]PricePartition, 0, len(names))
+	for _, name := range names {
+		month, err := time.Parse(pricePartitionLayout, name)
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, PricePartition{Name: name, From: month, To: month.AddDate(0, 1, 0)})
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].From.Before(partitions[j].From) })
+	return partitions, nil
+}
+
+// DropPricePartition удаляет секцию prices вместе с ее строками
+func DropPricePartition(ctx context.Context, db *gorm.DB, name string) error {
+	if _, err := time.Parse(pricePartitionLayout, name); err != nil {
+		return fmt.Errorf("%s не является секцией prices", name)
+	}
+	if err := db.WithContext(ctx).Exec(fmt.Sprintf(`DROP TABLE %q`, name)).Error; err != nil {
+		return fmt.Errorf("ошибка удаления секции %s: %w", name, err)
+	}
+	return nil
+}
+
+// partitionCheckInterval - как часто проверяется наличие будущих секций prices
+const partitionCheckInterval = 24 * time.Hour
+
+// EnsurePricePartitions создает недостающие месячные секции prices, покрывающие [from, to),
+// и возвращает число созданных. Для гипертаблицы TimescaleDB чанки создаются сами, и функция возвращает 0
+func EnsurePricePartitions(ctx context.Context, db *gorm.DB, from, to time.Time) (int, error) {
+	var created int
+	err := db.WithContext(ctx).Raw("SELECT prices_ensure_partitions(?, ?)", from.UTC(), to.UTC()).Scan(&created).Error
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания секций prices: %w", err)
+	}
+	return created, nil
+}
+
+// PartitionMaintainer заранее создает секции prices на monthsAhead месяцев вперед,
+// чтобы вставка новой цены не упала на границе месяца
+type PartitionMaintainer struct {
+	db          *gorm.DB
+	monthsAhead int
+	stopChannel chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewPartitionMaintainer - конструктор обслуживания секций
+func NewPartitionMaintainer(db *gorm.DB, monthsAhead int) *PartitionMaintainer {
+	return &PartitionMaintainer{db: db, monthsAhead: monthsAhead, stopChannel: make(chan struct{})}
+}
+
+// Start создает секции сразу и затем проверяет их раз в сутки.
+// Ошибка первой проверки возвращается, чтобы сервис не стартовал без секции на текущий месяц
+func (m *PartitionMaintainer) Start() error {
+	if err := m.ensure(); err != nil {
+		return err
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(partitionCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := m.ensure(); err != nil {
+					log.Println(err)
+				}
+			case <-m.stopChannel:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop останавливает проверку секций
+func (m *PartitionMaintainer) Stop() {
+	close(m.stopChannel)
+	m.wg.Wait()
+}
+
+func (m *PartitionMaintainer) ensure() error {
+	now := time.Now()
+	created, err := EnsurePricePartitions(context.Background(), m.db, now, now.AddDate(0, m.monthsAhead, 0))
+	if err != nil {
+		return err
+	}
+	if created > 0 {
+		log.Printf("Созданы секции prices: %d", created)
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package database
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"affarm/internal/repository/gormrepo"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// DefaultPurgeBatchSize - строк prices и price_aggregates в одном DELETE при удалении истории валюты
+const DefaultPurgeBatchSize = 5000
+
+// deletePricesBatchSQL удаляет очередную пачку точек валюты по уникальному индексу (currency_id, timestamp):
+// каждый DELETE блокирует только свои строки и коммитится сразу, не задерживая запись новых цен
+const deletePricesBatchSQL = `
+DELETE FROM prices WHERE currency_id = ? AND "timestamp" IN (
+    SELECT "timestamp" FROM prices WHERE currency_id = ? ORDER BY "timestamp" LIMIT ?)`
+
+// deleteAggregatesBatchSQL - то же для сверток по первичному ключу (currency_id, resolution_sec, bucket)
+const deleteAggregatesBatchSQL = `
+DELETE FROM price_aggregates WHERE currency_id = ? AND (resolution_sec, bucket) IN (
+    SELECT resolution_sec, bucket FROM price_aggregates WHERE currency_id = ? ORDER BY resolution_sec, bucket LIMIT ?)`
+
+// PurgeOptions - параметры удаления валюты
+type PurgeOptions struct {
+	DryRun    bool          // только посчитать строки, ничего не удаляя и не записывая в журнал
+	BatchSize int           // строк в одном DELETE, по умолчанию DefaultPurgeBatchSize
+	Pause     time.Duration // пауза между пачками, чтобы удаление не вытесняло запись новых цен
+	Reason    string        // причина для журнала, обязательна без DryRun
+	Actor     string        // автор для журнала, по умолчанию из контекста (repository.Actor)
+}
+
+// PurgeReport - итог удаления валюты
+type PurgeReport struct {
+	Symbol     string `json:"symbol"`
+	CurrencyID uint   `json:"currency_id"`
+	DryRun     bool   `json:"dry_run"`
+	models.PurgeCounts
+	// ArchiveFiles - файлы архива старых цен: в них могут остаться точки валюты, удаление их не меняет.
+	// Чтение из архива их больше не отдает, потому что сопоставляет строки по currency_id удаленной валюты
+	ArchiveFiles int64 `json:"archive_files"`
+	AuditID      uint  `json:"audit_id,omitempty"` // запись purge_audits, пусто при DryRun
+}
+
+// PurgeCurrency безвозвратно удаляет валюту (в том числе удаленную ранее) со всеми точками, свертками,
+// историей отслеживания и алертами. Сначала валюта переводится в archived, чтобы чекер цен перестал
+// ее дополнять, затем точки и свертки удаляются пачками, остальное - одной транзакцией вместе с валютой
+// под блокировкой ее строки, чтобы цены, сохраненные чекером параллельно с пачками, не пережили удаление.
+// Каждый запуск без DryRun записывается в purge_audits; прерванное удаление можно запустить снова.
+// ErrNotFound из repository, если валюты нет
+func PurgeCurrency(ctx context.Context, db *gorm.DB, symbol string, opts PurgeOptions) (PurgeReport, error) {
+	report := PurgeReport{Symbol: symbol, DryRun: opts.DryRun}
+	if !opts.DryRun && opts.Reason == "" {
+		return report, fmt.Errorf("не указана причина удаления")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultPurgeBatchSize
+	}
+	if opts.Actor == "" {
+		opts.Actor = repository.Actor(ctx)
+	}
+	db = db.WithContext(ctx)
+
+	var currency models.Currency
+	err := db.Unscoped().Where("symbol = ?", symbol).First(&currency).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return report, fmt.Errorf("%w: %s", repository.ErrNotFound, symbol)
+	}
+	if err != nil {
+		return report, fmt.Errorf("ошибка поиска валюты: %w", err)
+	}
+	report.CurrencyID = currency.ID
+	if err := db.Model(&models.PriceArchive{}).Count(&report.ArchiveFiles).Error; err != nil {
+		return report, fmt.Errorf("ошибка чтения манифеста архива: %w", err)
+	}
+
+	if opts.DryRun {
+		report.PurgeCounts, err = countPurge(db, currency.ID)
+		return report, err
+	}
+
+	audit := models.PurgeAudit{
+		Symbol:     symbol,
+		CurrencyID: currency.ID,
+		Actor:      opts.Actor,
+		Reason:     opts.Reason,
+		Status:     models.PurgeRunning,
+		StartedAt:  time.Now().UTC(),
+	}
+	if err := db.Create(&audit).Error; err != nil {
+		return report, fmt.Errorf("ошибка записи журнала удаления: %w", err)
+	}
+	report.AuditID = audit.ID
+
+	purgeErr := purge(ctx, db, currency, opts, &report.PurgeCounts)
+
+	// Итог пишется и после отмены запроса, чтобы в журнале не оставалось вечно running
+	finished := time.Now().UTC()
+	audit.PurgeCounts, audit.FinishedAt, audit.Status = report.PurgeCounts, &finished, models.PurgeCompleted
+	if purgeErr != nil {
+		audit.Status, audit.Error = models.PurgeFailed, purgeErr.Error()
+	}
+	if err := db.WithContext(context.WithoutCancel(ctx)).Save(&audit).Error; err != nil {
+		return report, errors.Join(purgeErr, fmt.Errorf("ошибка записи журнала удаления: %w", err))
+	}
+	return report, purgeErr
+}
+
+// countPurge считает строки, которые удалит PurgeCurrency
+func countPurge(db *gorm.DB, currencyID uint) (models.PurgeCounts, error) {
+	var counts models.PurgeCounts
+	err := db.Raw(`SELECT
+    (SELECT count(*) FROM prices WHERE currency_id = @id) AS prices,
+    (SELECT count(*) FROM price_aggregates WHERE currency_id = @id) AS aggregates,
+    (SELECT count(*) FROM tracking_periods WHERE currency_id = @id) AS tracking_periods,
+    (SELECT count(*) FROM alert_rules WHERE currency_id = @id) AS alert_rules,
+    (SELECT count(*) FROM alert_deliveries WHERE alert_rule_id IN (SELECT id FROM alert_rules WHERE currency_id = @id)) AS alert_deliveries`,
+		map[string]any{"id": currencyID}).Scan(&counts).Error
+	if err != nil {
+		return counts, fmt.Errorf("ошибка подсчета строк валюты: %w", err)
+	}
+	return counts, nil
+}
+
+// purge удаляет данные валюты, накапливая количество удаленных строк в counts
+func purge(ctx context.Context, db *gorm.DB, currency models.Currency, opts PurgeOptions, counts *models.PurgeCounts) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&currency).Updates(map[string]any{
+			"status":     models.CurrencyArchived,
+			"deleted_at": gorm.Expr("COALESCE(deleted_at, ?)", time.Now().UTC()),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return gormrepo.StopTracking(tx, currency.ID, opts.Actor)
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка остановки отслеживания валюты: %w", err)
+	}
+
+	if err := deleteBatches(ctx, db, deletePricesBatchSQL, currency.ID, opts, &counts.Prices); err != nil {
+		return fmt.Errorf("ошибка удаления цен: %w", err)
+	}
+	if err := deleteBatches(ctx, db, deleteAggregatesBatchSQL, currency.ID, opts, &counts.Aggregates); err != nil {
+		return fmt.Errorf("ошибка удаления сверток: %w", err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// FOR UPDATE ждет вставки цен, начатые до архивации (внешний ключ держит строку валюты), и не дает
+		// начать новые до коммита; SQLite блокирует всю бд на запись и без этого
+		var locked models.Currency
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, currency.ID).Error; err != nil {
+			return err
+		}
+		if !locked.DeletedAt.Valid {
+			return fmt.Errorf("валюта %s восстановлена во время удаления", currency.Symbol)
+		}
+
+		steps := []struct {
+			sql   string
+			count *int64
+		}{
+			{"DELETE FROM prices WHERE currency_id = ?", &counts.Prices},
+			{"DELETE FROM price_aggregates WHERE currency_id = ?", &counts.Aggregates},
+			{"DELETE FROM alert_deliveries WHERE alert_rule_id IN (SELECT id FROM alert_rules WHERE currency_id = ?)", &counts.AlertDeliveries},
+			{"DELETE FROM alert_rules WHERE currency_id = ?", &counts.AlertRules},
+			{"DELETE FROM tracking_periods WHERE currency_id = ?", &counts.TrackingPeriods},
+			{"DELETE FROM currencies WHERE id = ?", nil},
+		}
+		for _, step := range steps {
+			result := tx.Exec(step.sql, currency.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if step.count != nil {
+				*step.count += result.RowsAffected
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка удаления валюты: %w", err)
+	}
+	return nil
+}
+
+// deleteBatches выполняет пакетный DELETE, пока он удаляет полные пачки
+func deleteBatches(ctx context.Context, db *gorm.DB, query string, currencyID uint, opts PurgeOptions, deleted *int64) error {
+	for {
+		result := db.Exec(query, currencyID, currencyID, opts.BatchSize)
+		if result.Error != nil {
+			return result.Error
+		}
+		*deleted += result.RowsAffected
+		if result.RowsAffected < int64(opts.BatchSize) {
+			return nil
+		}
+		if opts.Pause > 0 {
+			select {
+			case <-time.After(opts.Pause):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// PurgeAudits возвращает последние записи журнала удаления валют, новые первыми
+func PurgeAudits(ctx context.Context, db *gorm.DB, limit int) ([]models.PurgeAudit, error) {
+	audits := []models.PurgeAudit{}
+	if err := db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&audits).Error; err != nil {
+		return nil, fmt.Errorf("ошибка чтения журнала удаления: %w", err)
+	}
+	return audits, nil
+}
@@ -0,0 +1,268 @@
+package database
+
+import (
+	"affarm/internal/models"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func openPurgeSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "purge.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// seedPurge создает валюту с prices точками, aggregates свертками, периодом отслеживания
+// и алертом с одним уведомлением
+func seedPurge(t *testing.T, db *gorm.DB, symbol string, prices, aggregates int) models.Currency {
+	t.Helper()
+	currency := models.Currency{Symbol: symbol, Status: models.CurrencyActive}
+	if err := db.Create(&currency).Error; err != nil {
+		t.Fatal(err)
+	}
+	at := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	for i := 0; i < prices; i++ {
+		price := models.Price{Price: float64(100 + i), Timestamp: at.Add(time.Duration(i) * time.Second), Source: "binance", CurrencyID: currency.ID}
+		if err := db.Create(&price).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < aggregates; i++ {
+		bucket := at.Add(-time.Duration(i+1) * time.Hour)
+		aggregate := models.PriceAggregate{CurrencyID: currency.ID, ResolutionSec: 3600, Bucket: bucket,
+			Open: 1, High: 1, Low: 1, Close: 1, Points: 1, FirstAt: bucket, LastAt: bucket}
+		if err := db.Create(&aggregate).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows := []any{
+		&models.TrackingPeriod{CurrencyID: currency.ID, StartedAt: at, StartedBy: "test"},
+		&models.AlertRule{CurrencyID: currency.ID, Kind: "above", Threshold: 200, Enabled: true},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	rule := rows[1].(*models.AlertRule)
+	if err := db.Create(&models.AlertDelivery{AlertRuleID: rule.ID, Status: "delivered"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return currency
+}
+
+// countBatches считает пакетные DELETE, выполненные через db
+func countBatches(t *testing.T, db *gorm.DB) *atomic.Int64 {
+	t.Helper()
+	var batches atomic.Int64
+	err := db.Callback().Raw().After("gorm:raw").Register("test:count_batches", func(tx *gorm.DB) {
+		if strings.Contains(tx.Statement.SQL.String(), "LIMIT") {
+			batches.Add(1)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &batches
+}
+
+func countRows(t *testing.T, db *gorm.DB, query string, args ...any) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Raw(query, args...).Row().Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestPurgeCurrencyBatches(t *testing.T) {
+	db := openPurgeSQLite(t)
+	currency := seedPurge(t, db, "BTC", 10, 4)
+	other := seedPurge(t, db, "ETH", 2, 1)
+	batches := countBatches(t, db)
+
+	report, err := PurgeCurrency(context.Background(), db, "BTC", PurgeOptions{BatchSize: 3, Reason: "test", Actor: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.PurgeCounts{Prices: 10, Aggregates: 4, TrackingPeriods: 1, AlertRules: 1, AlertDeliveries: 1}
+	if report.PurgeCounts != want {
+		t.Fatalf("удалено %+v, ожидалось %+v", report.PurgeCounts, want)
+	}
+	// Цены пачками 3+3+3+1, свертки 3+1
+	if got := batches.Load(); got != 6 {
+		t.Fatalf("пакетных DELETE %d, ожидалось 6", got)
+	}
+	for _, table := range []string{"prices", "price_aggregates", "tracking_periods", "alert_rules", "currencies"} {
+		column := "currency_id"
+		if table == "currencies" {
+			column = "id"
+		}
+		if n := countRows(t, db, "SELECT count(*) FROM "+table+" WHERE "+column+" = ?", currency.ID); n != 0 {
+			t.Fatalf("в %s осталось строк удаленной валюты: %d", table, n)
+		}
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM prices WHERE currency_id = ?", other.ID); n != 2 {
+		t.Fatalf("у другой валюты осталось %d точек, ожидалось 2", n)
+	}
+
+	var audit models.PurgeAudit
+	if err := db.First(&audit, report.AuditID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if audit.Status != models.PurgeCompleted || audit.PurgeCounts != want || audit.Actor != "tester" || audit.FinishedAt == nil {
+		t.Fatalf("запись журнала %+v", audit)
+	}
+}
+
+func TestPurgeCurrencyDryRun(t *testing.T) {
+	db := openPurgeSQLite(t)
+	seedPurge(t, db, "BTC", 5, 2)
+
+	report, err := PurgeCurrency(context.Background(), db, "BTC", PurgeOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.PurgeCounts{Prices: 5, Aggregates: 2, TrackingPeriods: 1, AlertRules: 1, AlertDeliveries: 1}
+	if report.PurgeCounts != want || report.AuditID != 0 {
+		t.Fatalf("отчет %+v, ожидалось %+v без записи журнала", report, want)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM prices"); n != 5 {
+		t.Fatalf("после dry-run осталось %d точек, ожидалось 5", n)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM purge_audits"); n != 0 {
+		t.Fatalf("dry-run записал журнал: %d записей", n)
+	}
+	var status string
+	if err := db.Raw("SELECT status FROM currencies WHERE symbol = 'BTC'").Row().Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != models.CurrencyActive {
+		t.Fatalf("dry-run изменил состояние валюты на %q", status)
+	}
+}
+
+// TestPurgeCurrencyAuditOnFailure прерывает удаление в паузе между пачками: запись журнала
+// получает failed и удаленное до ошибки, а повторный запуск доводит удаление до конца
+func TestPurgeCurrencyAuditOnFailure(t *testing.T) {
+	db := openPurgeSQLite(t)
+	currency := seedPurge(t, db, "BTC", 10, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	report, err := PurgeCurrency(ctx, db, "BTC", PurgeOptions{BatchSize: 3, Pause: time.Hour, Reason: "test"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ошибка %v, ожидалось превышение срока", err)
+	}
+	var audit models.PurgeAudit
+	if err := db.First(&audit, report.AuditID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if audit.Status != models.PurgeFailed || audit.Error == "" || audit.Prices != 3 || audit.FinishedAt == nil {
+		t.Fatalf("запись журнала после ошибки %+v", audit)
+	}
+	var status string
+	if err := db.Raw("SELECT status FROM currencies WHERE id = ?", currency.ID).Row().Scan(&status); err != nil {
+		t.Fatal(err)
+	}
+	if status != models.CurrencyArchived {
+		t.Fatalf("валюта после прерванного удаления в состоянии %q, ожидалось archived", status)
+	}
+
+	report, err = PurgeCurrency(context.Background(), db, "BTC", PurgeOptions{BatchSize: 3, Reason: "retry"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Prices != 7 || report.AuditID == audit.ID {
+		t.Fatalf("повторное удаление %+v, ожидалось 7 оставшихся точек и новая запись журнала", report)
+	}
+}
+
+// TestPurgeCurrencyConcurrentInsert пишет цены валюты из отдельного соединения, пока идет удаление:
+// блокировка строки валюты в последней транзакции не должна оставить ни одной точки
+func TestPurgeCurrencyConcurrentInsert(t *testing.T) {
+	db := openTestSchema(t)
+	ctx := context.Background()
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	currency := seedPurge(t, db, "BTC", 20, 0)
+
+	// Писатель получает свое соединение, а пул gorm - второе, и search_path задается обоим
+	var schema string
+	if err := db.Raw("SELECT current_schema()").Row().Scan(&schema); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(2)
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "SET search_path TO "+schema+", public"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("SET search_path TO " + schema + ", public").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	inserted := make(chan int)
+	go func() {
+		n := 0
+		at := time.Now().UTC()
+		for {
+			select {
+			case <-stop:
+				inserted <- n
+				return
+			default:
+			}
+			at = at.Add(time.Millisecond)
+			_, err := conn.ExecContext(ctx, `INSERT INTO prices (currency_id, price, "timestamp", source) VALUES ($1, 1, $2, 'binance')`,
+				currency.ID, at)
+			if err != nil {
+				// Валюта удалена: внешний ключ больше не пускает новые точки
+				<-stop
+				inserted <- n
+				return
+			}
+			n++
+		}
+	}()
+
+	report, err := PurgeCurrency(ctx, db, "BTC", PurgeOptions{BatchSize: 5, Pause: 10 * time.Millisecond, Reason: "test"})
+	close(stop)
+	written := <-inserted
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countRows(t, db, "SELECT count(*) FROM prices WHERE currency_id = ?", currency.ID); n != 0 {
+		t.Fatalf("после удаления осталось %d точек, записанных параллельно", n)
+	}
+	if report.Prices != int64(20+written) {
+		t.Fatalf("удалено %d точек, ожидалось %d (20 исходных и %d параллельных)", report.Prices, 20+written, written)
+	}
+}
@@ -0,0 +1,240 @@
+package database
+
+import (
+	"affarm/config"
+	"context"
+	"database/sql"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"sync"
+	"time"
+)
+
+// aggregateConflictSQL сливает свертку с уже существующей за тот же интервал: это бывает,
+// когда в уже свернутый интервал позже дописаны точки (например, импортом истории)
+const aggregateConflictSQL = `
+ON CONFLICT (currency_id, resolution_sec, bucket) DO UPDATE SET
+    open     = CASE WHEN excluded.first_at < price_aggregates.first_at THEN excluded.open ELSE price_aggregates.open END,
+    close    = CASE WHEN excluded.last_at > price_aggregates.last_at THEN excluded.close ELSE price_aggregates.close END,
+    high     = greatest(price_aggregates.high, excluded.high),
+    low      = least(price_aggregates.low, excluded.low),
+    volume   = coalesce(price_aggregates.volume + excluded.volume, price_aggregates.volume, excluded.volume),
+    points   = price_aggregates.points + excluded.points,
+    first_at = least(price_aggregates.first_at, excluded.first_at),
+    last_at  = greatest(price_aggregates.last_at, excluded.last_at)`
+
+// rollupPricesSQL сворачивает сырые точки интервала [@from, @to) в свертки шага @res
+const rollupPricesSQL = `
+INSERT INTO price_aggregates (currency_id, resolution_sec, bucket, open, high, low, close, volume, points, first_at, last_at)
+SELECT currency_id, CAST(@res AS bigint), bucket,
+       (array_agg(price ORDER BY "timestamp"))[1], max(price), min(price),
+       (array_agg(price ORDER BY "timestamp" DESC))[1],
+       sum(volume), count(*), min("timestamp"), max("timestamp")
+FROM (
+    SELECT p.*, to_timestamp(floor(extract(epoch FROM p."timestamp") / CAST(@res AS bigint)) * CAST(@res AS bigint)) AS bucket
+    FROM prices p
+    WHERE p."timestamp" >= @from AND p."timestamp" < @to AND p.currency_id IS NOT NULL
+) p
+GROUP BY currency_id, bucket` + aggregateConflictSQL
+
+// rollupAggregatesSQL укрупняет свертки шага @src интервала [@from, @to) до шага @res
+const rollupAggregatesSQL = `
+INSERT INTO price_aggregates (currency_id, resolution_sec, bucket, open, high, low, close, volume, points, first_at, last_at)
+SELECT currency_id, CAST(@res AS bigint), coarse,
+       (array_agg(open ORDER BY first_at))[1], max(high), min(low),
+       (array_agg(close ORDER BY last_at DESC))[1],
+       sum(volume), sum(points), min(first_at), max(last_at)
+FROM (
+    SELECT g.*, to_timestamp(floor(extract(epoch FROM g.bucket) / CAST(@res AS bigint)) * CAST(@res AS bigint)) AS coarse
+    FROM price_aggregates g
+    WHERE g.resolution_sec = @src AND g.bucket >= @from AND g.bucket < @to
+) g
+GROUP BY currency_id, coarse` + aggregateConflictSQL
+
+// retentionLevel - уровень хранения: сырые точки (resolution 0) или свертки одного шага
+type retentionLevel struct {
+	resolution int // шаг сверток в секундах, 0 - сырые точки prices
+	keepDays   int // 0 - хранить всегда
+}
+
+// Retention сворачивает сырые цены старше срока хранения в свертки и укрупняет старые свертки
+// по уровням из конфига. Интервалы обрабатываются посуточно, каждые сутки в своей транзакции:
+// свертка и удаление исходных точек происходят атомарно
+type Retention struct {
+	db          *gorm.DB
+	levels      []retentionLevel
+	interval    time.Duration
+	rawLimit    func(ctx context.Context) (time.Time, bool, error)
+	stopChannel chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewRetention - конструктор свертки. Конфиг должен быть проверен RetentionConfig.Validate
+func NewRetention(db *gorm.DB, cfg config.RetentionConfig) *Retention {
+	levels := []retentionLevel{{keepDays: cfg.RawDays}}
+	for _, tier := range cfg.Tiers {
+		levels = append(levels, retentionLevel{resolution: tier.ResolutionSec, keepDays: tier.KeepDays})
+	}
+	return &Retention{
+		db:          db,
+		levels:      levels,
+		interval:    time.Duration(cfg.IntervalMin) * time.Minute,
+		stopChannel: make(chan struct{}),
+	}
+}
+
+// WithRawLimit ограничивает удаление сырых точек моментом, который возвращает limit
+// (например, началом еще не выгруженных в архив данных). false означает отсутствие ограничения
+func (r *Retention) WithRawLimit(limit func(ctx context.Context) (time.Time, bool, error)) *Retention {
+	r.rawLimit = limit
+	return r
+}
+
+// Start запускает свертку сразу и затем с интервалом из конфига
+func (r *Retention) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			if err := r.Run(context.Background()); err != nil {
+				log.Printf("ошибка свертки старых цен: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-r.stopChannel:
+				return
+			}
+		}
+	}()
+	log.Printf("Свертка старых цен запущена, уровней: %d", len(r.levels)-1)
+}
+
+// Stop останавливает свертку и дожидается текущего прохода
+func (r *Retention) Stop() {
+	close(r.stopChannel)
+	r.wg.Wait()
+}
+
+// Run выполняет один проход: уровни обрабатываются от сырых точек к самым крупным сверткам,
+// чтобы свернутое на этом проходе сразу могло укрупниться дальше
+func (r *Retention) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	for i, level := range r.levels {
+		if level.keepDays == 0 {
+			continue
+		}
+		cutoff := now.AddDate(0, 0, -level.keepDays).Truncate(24 * time.Hour)
+		if level.resolution == 0 && r.rawLimit != nil {
+			limit, ok, err := r.rawLimit(ctx)
+			if err != nil {
+				return err
+			}
+			if ok && limit.Before(cutoff) {
+				cutoff = limit.UTC().Truncate(24 * time.Hour)
+			}
+		}
+
+		var next *retentionLevel
+		if i+1 < len(r.levels) {
+			next = &r.levels[i+1]
+		}
+		rolled, err := r.rollup(ctx, level, next, cutoff)
+		if err != nil {
+			return err
+		}
+		if rolled > 0 {
+			log.Printf("Свернуто суток (уровень %s): %d", level, rolled)
+		}
+		if level.resolution == 0 {
+			if err := r.dropExpiredPartitions(ctx, cutoff); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rollup сворачивает точки уровня level до cutoff в уровень next посуточно и удаляет их.
+// Если следующего уровня нет, точки просто удаляются. Возвращает число обработанных суток
+func (r *Retention) rollup(ctx context.Context, level retentionLevel, next *retentionLevel, cutoff time.Time) (int, error) {
+	oldest, err := r.oldest(ctx, level)
+	if err != nil || oldest == nil {
+		return 0, err
+	}
+
+	days := 0
+	for day := oldest.UTC().Truncate(24 * time.Hour); day.Before(cutoff); day = day.Add(24 * time.Hour) {
+		args := []any{
+			sql.Named("from", day),
+			sql.Named("to", day.Add(24*time.Hour)),
+			sql.Named("src", level.resolution),
+		}
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if next != nil {
+				rollupSQL := rollupPricesSQL
+				if level.resolution > 0 {
+					rollupSQL = rollupAggregatesSQL
+				}
+				if err := tx.Exec(rollupSQL, append(args, sql.Named("res", next.resolution))...).Error; err != nil {
+					return err
+				}
+			}
+			if level.resolution == 0 {
+				return tx.Exec(`DELETE FROM prices WHERE "timestamp" >= @from AND "timestamp" < @to`, args...).Error
+			}
+			return tx.Exec(`DELETE FROM price_aggregates WHERE resolution_sec = @src AND bucket >= @from AND bucket < @to`, args...).Error
+		})
+		if err != nil {
+			return days, fmt.Errorf("свертка %s за %s: %w", level, day.Format("2006-01-02"), err)
+		}
+		days++
+	}
+	return days, nil
+}
+
+// oldest возвращает метку самой старой точки уровня или nil, если уровень пуст
+func (r *Retention) oldest(ctx context.Context, level retentionLevel) (*time.Time, error) {
+	var oldest sql.NullTime
+	query := r.db.WithContext(ctx)
+	if level.resolution == 0 {
+		query = query.Raw(`SELECT min("timestamp") FROM prices`)
+	} else {
+		query = query.Raw(`SELECT min(bucket) FROM price_aggregates WHERE resolution_sec = ?`, level.resolution)
+	}
+	if err := query.Scan(&oldest).Error; err != nil {
+		return nil, fmt.Errorf("ошибка поиска самой старой точки %s: %w", level, err)
+	}
+	if !oldest.Valid {
+		return nil, nil
+	}
+	return &oldest.Time, nil
+}
+
+// dropExpiredPartitions удаляет месячные секции prices, целиком лежащие раньше cutoff.
+// Их строки уже свернуты и удалены, а удаление секции освобождает место без VACUUM
+func (r *Retention) dropExpiredPartitions(ctx context.Context, cutoff time.Time) error {
+	partitions, err := PricePartitions(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	for _, partition := range partitions {
+		if partition.To.After(cutoff) {
+			continue
+		}
+		if err := DropPricePartition(ctx, r.db, partition.Name); err != nil {
+			return err
+		}
+		log.Printf("Удалена секция %s", partition.Name)
+	}
+	return nil
+}
+
+func (l retentionLevel) String() string {
+	if l.resolution == 0 {
+		return "raw"
+	}
+	return (time.Duration(l.resolution) * time.Second).String()
+}
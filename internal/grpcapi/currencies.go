@@ -0,0 +1,119 @@
+package grpcapi
+
+import (
+	affarmv1 "affarm/api/affarm/v1"
+	services "affarm/internal/service"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// currencyServer - реализация affarm.v1.CurrencyService
+type currencyServer struct {
+	affarmv1.UnimplementedCurrencyServiceServer
+	currencies *services.CurrencyService
+}
+
+func (s *currencyServer) AddCurrency(ctx context.Context, req *affarmv1.AddCurrencyRequest) (*affarmv1.AddCurrencyResponse, error) {
+	currency, restored, err := s.currencies.Add(ctx, req.GetSymbol())
+	if err != nil {
+		return nil, toStatus(err, "AddCurrency")
+	}
+	return &affarmv1.AddCurrencyResponse{
+		Currency: &affarmv1.Currency{
+			Id:      uint64(currency.ID),
+			Symbol:  currency.Symbol,
+			Status:  affarmv1.CurrencyStatus_CURRENCY_STATUS_ACTIVE,
+			AddedAt: timestamppb.New(currency.CreatedAt),
+		},
+		Restored: restored,
+	}, nil
+}
+
+func (s *currencyServer) RemoveCurrency(ctx context.Context, req *affarmv1.RemoveCurrencyRequest) (*affarmv1.RemoveCurrencyResponse, error) {
+	var id uint
+	var symbol string
+	switch key := req.GetKey().(type) {
+	case *affarmv1.RemoveCurrencyRequest_Id:
+		if key.Id == 0 {
+			return nil, status.Error(codes.InvalidArgument, "id must not be zero")
+		}
+		id = uint(key.Id)
+	case *affarmv1.RemoveCurrencyRequest_Symbol:
+		symbol = key.Symbol
+	default:
+		return nil, status.Error(codes.InvalidArgument, "must provide either id or symbol")
+	}
+
+	if err := s.currencies.Remove(ctx, id, symbol); err != nil {
+		return nil, toStatus(err, "RemoveCurrency")
+	}
+	return &affarmv1.RemoveCurrencyResponse{}, nil
+}
+
+func (s *currencyServer) ListCurrencies(ctx context.Context, req *affarmv1.ListCurrenciesRequest) (*affarmv1.ListCurrenciesResponse, error) {
+	snapshots := s.currencies.List()
+	resp := &affarmv1.ListCurrenciesResponse{Currencies: make([]*affarmv1.Currency, len(snapshots))}
+	for i, snapshot := range snapshots {
+		resp.Currencies[i] = toCurrency(snapshot)
+	}
+	return resp, nil
+}
+
+func (s *currencyServer) PauseCurrency(ctx context.Context, req *affarmv1.PauseCurrencyRequest) (*affarmv1.Currency, error) {
+	snapshot, err := s.currencies.Pause(ctx, req.GetSymbol())
+	if err != nil {
+		return nil, toStatus(err, "PauseCurrency")
+	}
+	return toCurrency(snapshot), nil
+}
+
+func (s *currencyServer) ResumeCurrency(ctx context.Context, req *affarmv1.ResumeCurrencyRequest) (*affarmv1.Currency, error) {
+	snapshot, err := s.currencies.Resume(ctx, req.GetSymbol())
+	if err != nil {
+		return nil, toStatus(err, "ResumeCurrency")
+	}
+	return toCurrency(snapshot), nil
+}
+
+func (s *currencyServer) GetTrackingHistory(ctx context.Context, req *affarmv1.GetTrackingHistoryRequest) (*affarmv1.GetTrackingHistoryResponse, error) {
+	history, err := s.currencies.TrackingHistory(ctx, req.GetSymbol())
+	if err != nil {
+		return nil, toStatus(err, "GetTrackingHistory")
+	}
+	resp := &affarmv1.GetTrackingHistoryResponse{
+		Symbol:  history.Symbol,
+		Tracked: history.Tracked,
+		Periods: make([]*affarmv1.TrackingPeriod, len(history.Periods)),
+	}
+	for i, period := range history.Periods {
+		resp.Periods[i] = &affarmv1.TrackingPeriod{
+			StartedAt: timestamppb.New(period.StartedAt),
+			StartedBy: period.StartedBy,
+			StoppedAt: optionalTimestamp(period.StoppedAt),
+			StoppedBy: period.StoppedBy,
+		}
+	}
+	return resp, nil
+}
+
+func toCurrency(snapshot services.CurrencySnapshot) *affarmv1.Currency {
+	currency := &affarmv1.Currency{
+		Id:         uint64(snapshot.ID),
+		Symbol:     snapshot.Symbol,
+		Status:     affarmv1.CurrencyStatus_CURRENCY_STATUS_ACTIVE,
+		AddedAt:    timestamppb.New(snapshot.AddedAt),
+		LastPrice:  snapshot.LastPrice,
+		LastUpdate: optionalTimestamp(snapshot.LastUpdate),
+		LastSource: snapshot.LastSource,
+		PointCount: snapshot.PointCount,
+	}
+	switch snapshot.Status {
+	case services.StatusArchived:
+		currency.Status = affarmv1.CurrencyStatus_CURRENCY_STATUS_ARCHIVED
+	case services.StatusPaused:
+		currency.Status = affarmv1.CurrencyStatus_CURRENCY_STATUS_PAUSED
+	}
+	return currency
+}
@@ -0,0 +1,151 @@
+package grpcapi
+
+import (
+	affarmv1 "affarm/api/affarm/v1"
+	services "affarm/internal/service"
+	"context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+	"strings"
+)
+
+// priceServer - реализация affarm.v1.PriceService
+type priceServer struct {
+	affarmv1.UnimplementedPriceServiceServer
+	prices *services.PriceService
+	hub    *services.PriceHub
+}
+
+var qualities = map[string]affarmv1.PriceQuality{
+	services.QualityExact:        affarmv1.PriceQuality_PRICE_QUALITY_EXACT,
+	services.QualityNearest:      affarmv1.PriceQuality_PRICE_QUALITY_NEAREST,
+	services.QualityInterpolated: affarmv1.PriceQuality_PRICE_QUALITY_INTERPOLATED,
+	services.QualityStale:        affarmv1.PriceQuality_PRICE_QUALITY_STALE,
+}
+
+// lookupOptions переводит флаги запроса в параметры поиска; bridge_gaps по умолчанию true
+func lookupOptions(interpolate bool, bridgeGaps *bool) services.LookupOptions {
+	return services.LookupOptions{Interpolate: interpolate, NoBridgeGaps: bridgeGaps != nil && !*bridgeGaps}
+}
+
+func (s *priceServer) GetPrice(ctx context.Context, req *affarmv1.GetPriceRequest) (*affarmv1.Price, error) {
+	resp, err := s.prices.At(ctx, req.GetSymbol(), asTime(req.GetAt()), lookupOptions(req.GetInterpolate(), req.BridgeGaps))
+	if err != nil {
+		return nil, toStatus(err, "GetPrice")
+	}
+	return toPrice(resp), nil
+}
+
+func (s *priceServer) LookupPrices(ctx context.Context, req *affarmv1.LookupPricesRequest) (*affarmv1.LookupPricesResponse, error) {
+	items := req.GetItems()
+	queries := make([]services.PriceQuery, len(items))
+	for i, item := range items {
+		queries[i] = services.PriceQuery{Symbol: item.GetSymbol(), At: asTime(item.GetAt())}
+	}
+
+	batch, err := s.prices.Batch(ctx, queries, lookupOptions(req.GetInterpolate(), req.BridgeGaps))
+	if err != nil {
+		return nil, toStatus(err, "LookupPrices")
+	}
+
+	// Ошибки отдельных пар не влияют на остальные и возвращаются в результате пары
+	results := make([]*affarmv1.LookupResult, len(batch))
+	for i, item := range batch {
+		results[i] = &affarmv1.LookupResult{Symbol: items[i].GetSymbol(), At: items[i].GetAt()}
+		if item.Err != nil {
+			results[i].Error = item.Err.Error()
+		} else {
+			results[i].Result = toPrice(item.Response)
+		}
+	}
+	return &affarmv1.LookupPricesResponse{Results: results}, nil
+}
+
+func (s *priceServer) GetPriceRange(ctx context.Context, req *affarmv1.GetPriceRangeRequest) (*affarmv1.GetPriceRangeResponse, error) {
+	points, err := s.prices.Range(ctx, req.GetSymbol(), asTime(req.GetFrom()), asTime(req.GetTo()), int(req.GetLimit()))
+	if err != nil {
+		return nil, toStatus(err, "GetPriceRange")
+	}
+	resp := &affarmv1.GetPriceRangeResponse{Points: make([]*affarmv1.PricePoint, len(points))}
+	for i, point := range points {
+		resp.Points[i] = toPricePoint(point)
+	}
+	return resp, nil
+}
+
+// SubscribePrices отдает новые цены из того же PriceHub, что и SSE/WebSocket.
+// Клиент, не успевающий читать события, отключается с RESOURCE_EXHAUSTED
+func (s *priceServer) SubscribePrices(req *affarmv1.SubscribePricesRequest, stream affarmv1.PriceService_SubscribePricesServer) error {
+	symbols := make([]string, 0, len(req.GetSymbols()))
+	for _, symbol := range req.GetSymbols() {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, strings.ToUpper(symbol))
+		}
+	}
+
+	sub, complete := s.hub.Subscribe(symbols, req.GetLastEventId())
+	defer sub.Close()
+
+	if !complete {
+		// Часть событий после last_event_id уже вытеснена из буфера
+		if err := stream.Send(&affarmv1.PriceUpdate{Gap: true}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped {
+					log.Printf("gRPC-подписчик отключен: не успевает читать события")
+					return status.Error(codes.ResourceExhausted, "slow consumer")
+				}
+				return nil
+			}
+			err := stream.Send(&affarmv1.PriceUpdate{
+				Id:         event.ID,
+				PriceId:    uint64(event.Price.PriceID),
+				CurrencyId: uint64(event.Price.CurrencyID),
+				Symbol:     event.Price.Symbol,
+				Price:      event.Price.Price,
+				Timestamp:  timestamppb.New(event.Price.Timestamp),
+				Source:     event.Price.Source,
+			})
+			if err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func toPrice(resp services.PriceResponse) *affarmv1.Price {
+	price := &affarmv1.Price{
+		Symbol:        resp.Symbol,
+		Price:         resp.Price,
+		Timestamp:     timestamppb.New(resp.Timestamp),
+		OffsetSeconds: resp.OffsetSeconds,
+		Source:        resp.Source,
+		Quality:       qualities[resp.Quality],
+		Points:        make([]*affarmv1.PricePoint, len(resp.Points)),
+	}
+	for i, point := range resp.Points {
+		price.Points[i] = toPricePoint(point)
+	}
+	return price
+}
+
+func toPricePoint(point services.PricePoint) *affarmv1.PricePoint {
+	return &affarmv1.PricePoint{
+		Price:        point.Price,
+		PriceDecimal: point.Decimal.String(),
+		Timestamp:    timestamppb.New(point.Timestamp),
+		Source:       point.Source,
+
+		ResolutionSeconds: point.ResolutionSeconds,
+	}
+}
@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	affarmv1 "affarm/api/affarm/v1"
+	"affarm/internal/repository"
+	services "affarm/internal/service"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log"
+	"strings"
+	"time"
+)
+
+// NewServer создает gRPC-сервер с сервисами affarm.v1 поверх тех же сервисов, что и HTTP-роутер.
+// Reflection включен, чтобы с сервером можно было работать через grpcurl без .proto
+func NewServer(svc *services.Services) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(actorInterceptor))
+	affarmv1.RegisterCurrencyServiceServer(server, &currencyServer{currencies: svc.Currencies})
+	affarmv1.RegisterPriceServiceServer(server, &priceServer{prices: svc.Prices, hub: svc.Hub})
+	reflection.Register(server)
+
+	for name := range server.GetServiceInfo() {
+		log.Printf("gRPC %s", name)
+	}
+	return server
+}
+
+// actorGRPC - автор изменений из gRPC API без метаданных x-actor
+const actorGRPC = "grpc"
+
+// actorInterceptor передает автора вызова из метаданных x-actor в контекст, как заголовок X-Actor в HTTP
+func actorInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	actor := actorGRPC
+	if values := metadata.ValueFromIncomingContext(ctx, "x-actor"); len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+		actor = values[0]
+	}
+	return handler(repository.WithActor(ctx, actor), req)
+}
+
+// toStatus переводит ошибку сервиса в код gRPC по ее категории, так же как HTTP-обработчики
+// переводят ее в статус. Внутренние ошибки логируются, а клиенту отдаются без подробностей
+func toStatus(err error, op string) error {
+	switch services.KindOf(err) {
+	case services.KindNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case services.KindConflict:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case services.KindInvalid:
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		log.Printf("gRPC %s: %v", op, err)
+		return status.Error(codes.Internal, "database error")
+	}
+}
+
+// asTime переводит незаданное время в нулевое, которое сервисы считают отсутствующим.
+// AsTime у nil вернул бы начало эпохи Unix
+func asTime(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.AsTime()
+}
+
+// optionalTimestamp возвращает nil для пустого времени
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
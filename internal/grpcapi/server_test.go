@@ -0,0 +1,165 @@
+package grpcapi
+
+import (
+	affarmv1 "affarm/api/affarm/v1"
+	"affarm/internal/models"
+	"affarm/internal/repository/memory"
+	services "affarm/internal/service"
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"testing"
+	"time"
+)
+
+// startServer поднимает сервер поверх хранилища в памяти на bufconn и возвращает клиент PriceService
+func startServer(t *testing.T, repo *memory.Repository, hub *services.PriceHub) affarmv1.PriceServiceClient {
+	t.Helper()
+	cache := services.NewPriceCache()
+	server := NewServer(&services.Services{
+		Currencies: services.NewCurrencyService(repo, cache),
+		Prices:     services.NewPriceService(repo),
+		Cache:      cache,
+		Hub:        hub,
+	})
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return affarmv1.NewPriceServiceClient(conn)
+}
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{services.ErrCurrencyNotFound, codes.NotFound, "Currency not found"},
+		{services.ErrCurrencyExists, codes.AlreadyExists, "Currency already exists"},
+		{&services.Error{Kind: services.KindInvalid, Message: "at is required"}, codes.InvalidArgument, "at is required"},
+		{&services.Error{Kind: services.KindNotFound, Message: "No price data available for BTC", Err: services.ErrNoPriceData}, codes.NotFound, "No price data available for BTC"},
+		// Подробности внутренней ошибки клиенту не отдаются
+		{errors.New("connection refused"), codes.Internal, "database error"},
+	}
+	for _, tt := range tests {
+		got := status.Convert(toStatus(tt.err, "test"))
+		if got.Code() != tt.code || got.Message() != tt.message {
+			t.Errorf("toStatus(%v) = %s %q, ожидалось %s %q", tt.err, got.Code(), got.Message(), tt.code, tt.message)
+		}
+	}
+}
+
+// TestGetPriceBridgeGaps запрашивает момент до начала отслеживания валюты: без bridge_gaps
+// цена берется из точки до пробела, с bridge_gaps=false момент считается неотслеживаемым
+func TestGetPriceBridgeGaps(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	currency := models.Currency{Symbol: "BTC"}
+	if err := repo.Create(ctx, &currency); err != nil {
+		t.Fatal(err)
+	}
+	point := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Second)
+	if err := repo.SavePrice(ctx, &models.Price{CurrencyID: currency.ID, Price: 100, Timestamp: point}); err != nil {
+		t.Fatal(err)
+	}
+	client := startServer(t, repo, services.NewPriceHub(10, 10))
+	at := timestamppb.New(point.Add(time.Hour))
+
+	price, err := client.GetPrice(ctx, &affarmv1.GetPriceRequest{Symbol: "BTC", At: at})
+	if err != nil {
+		t.Fatalf("без bridge_gaps: %v", err)
+	}
+	if price.GetPrice() != 100 || !price.GetTimestamp().AsTime().Equal(point) {
+		t.Fatalf("цена %v на %v, ожидалась точка до пробела", price.GetPrice(), price.GetTimestamp().AsTime())
+	}
+
+	bridge := false
+	_, err = client.GetPrice(ctx, &affarmv1.GetPriceRequest{Symbol: "BTC", At: at, BridgeGaps: &bridge})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("с bridge_gaps=false: %v, ожидалось NOT_FOUND", err)
+	}
+}
+
+func publish(hub *services.PriceHub, n int) {
+	currency := models.Currency{Symbol: "BTC"}
+	currency.ID = 1
+	for i := 0; i < n; i++ {
+		hub.OnPrice(currency, models.Price{Price: float64(i), Timestamp: time.Now().UTC()})
+	}
+}
+
+// TestSubscribePricesGap продолжает поток после события, уже вытесненного из буфера:
+// первым приходит маркер пропуска, затем оставшиеся в буфере события
+func TestSubscribePricesGap(t *testing.T) {
+	hub := services.NewPriceHub(2, 10)
+	publish(hub, 5)
+	client := startServer(t, memory.New(), hub)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.SubscribePrices(ctx, &affarmv1.SubscribePricesRequest{LastEventId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !update.GetGap() {
+		t.Fatalf("первое сообщение %v, ожидался маркер пропуска", update)
+	}
+	for _, want := range []float64{3, 4} {
+		update, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if update.GetGap() || update.GetPrice() != want || update.GetSymbol() != "BTC" {
+			t.Fatalf("событие %v, ожидалась цена %v из буфера", update, want)
+		}
+	}
+}
+
+// TestSubscribePricesSlowConsumer не читает поток, пока не заполнятся окно gRPC и очередь подписки:
+// подписка отключается, и после прочитанных событий клиент получает RESOURCE_EXHAUSTED
+func TestSubscribePricesSlowConsumer(t *testing.T) {
+	hub := services.NewPriceHub(1, 1)
+	publish(hub, 1)
+	client := startServer(t, memory.New(), hub)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Маркер пропуска приходит после подписки на хаб, поэтому события ниже ее уже застают
+	stream, err := client.SubscribePrices(ctx, &affarmv1.SubscribePricesRequest{LastEventId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update, err := stream.Recv(); err != nil || !update.GetGap() {
+		t.Fatalf("первое сообщение %v (%v), ожидался маркер пропуска", update, err)
+	}
+
+	publish(hub, 100000)
+	for {
+		_, err := stream.Recv()
+		if err == nil {
+			continue
+		}
+		if status.Code(err) != codes.ResourceExhausted {
+			t.Fatalf("поток завершился с %v, ожидалось RESOURCE_EXHAUSTED", err)
+		}
+		return
+	}
+}
@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"affarm/config"
+	"affarm/internal/archive"
+	services "affarm/internal/service"
+	"crypto/subtle"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// AdminHandler - служебные HTTP-методы: загрузка и удаление истории цен и другие операции над данными
+type AdminHandler struct {
+	db           *gorm.DB
+	currencies   *services.CurrencyService
+	correlations *services.CorrelationCache
+	archive      *archive.Reader
+	cfg          config.AdminConfig
+}
+
+// NewAdminHandler - конструктор обработчика
+func NewAdminHandler(db *gorm.DB, svc *services.Services, cfg config.AdminConfig) *AdminHandler {
+	return &AdminHandler{db: db, currencies: svc.Currencies, correlations: svc.Correlations, archive: svc.Archive, cfg: cfg}
+}
+
+// RequireToken пропускает только запросы с заголовком Authorization: Bearer <admin.token>
+func (h *AdminHandler) RequireToken(next http.HandlerFunc) http.HandlerFunc {
+	expected := []byte("Bearer " + h.cfg.Token)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,126 @@
+package admin
+
+import (
+	"affarm/internal/importer"
+	"affarm/internal/pricefile"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ImportPrices godoc
+// @Summary Загрузка истории цен
+// @Description Загружает точки цен из файла CSV (заголовок с колонками symbol, timestamp, price и необязательными source, volume)
+// @Description или NDJSON (объекты с теми же полями). Время - RFC3339 или Unix-секунды/миллисекунды.
+// @Description Файл передается телом запроса или полем file формы multipart/form-data, сжатый - с Content-Encoding: gzip или расширением .gz.
+// @Description Недостающие валюты создаются приостановленными (сбор цен включает resume), точки на уже занятые моменты времени пропускаются,
+// @Description для точек существующих валют вне периодов отслеживания добавляются периоды (extended_tracking в отчете),
+// @Description строки с ошибками и строки архивных валют не загружаются и перечисляются в отчете. Требует Authorization: Bearer с токеном admin.token.
+// @Tags admin
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "Формат файла, по умолчанию по Content-Type или расширению, иначе csv" Enums(csv, ndjson)
+// @Param source query string false "Источник для строк без колонки source" default(import)
+// @Param Authorization header string true "Bearer <токен>"
+// @Success 200 {object} importer.Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/import/prices [post]
+func (h *AdminHandler) ImportPrices(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, int64(h.cfg.MaxUploadMB)<<20)
+
+	body, filename, err := uploadedFile(r)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid upload"}`, http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = detectFormat(filename, r.Header.Get("Content-Type"))
+	}
+	if format != pricefile.FormatCSV && format != pricefile.FormatNDJSON {
+		http.Error(w, `{"error": "Invalid format, expected csv or ndjson"}`, http.StatusBadRequest)
+		return
+	}
+	if r.Header.Get("Content-Encoding") == "gzip" || strings.HasSuffix(filename, ".gz") {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			http.Error(w, `{"error": "Invalid gzip body"}`, http.StatusBadRequest)
+			return
+		}
+		defer zr.Close()
+		body = zr
+	}
+
+	report, err := importer.Import(r.Context(), h.db, body, importer.Options{Format: format, Source: r.URL.Query().Get("source")})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, `{"error": "File is too large"}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+		log.Printf("Ошибка загрузки истории цен: %v", err)
+		http.Error(w, `{"error": "Import failed, nothing was saved"}`, http.StatusInternalServerError)
+		return
+	}
+	log.Printf("Загружена история цен: строк %d, добавлено %d, повторов %d, отклонено %d, новых валют %d",
+		report.Lines, report.Accepted, report.Duplicates, report.Rejected, len(report.CreatedCurrencies))
+
+	// Новые валюты и точки должны появиться в списке валют без перезапуска, а корреляции - пересчитаться
+	if err := h.currencies.Refresh(r.Context()); err != nil {
+		log.Printf("Ошибка обновления кеша валют после загрузки: %v", err)
+	}
+	for _, symbols := range [][]string{report.Symbols, report.CreatedCurrencies, report.ExtendedTracking} {
+		for _, symbol := range symbols {
+			h.correlations.Forget(symbol)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// uploadedFile возвращает файл из поля file формы multipart или тело запроса целиком
+func uploadedFile(r *http.Request) (io.Reader, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, "", nil
+	}
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, "", err
+		}
+		if part.FormName() == "file" {
+			return part, part.FileName(), nil
+		}
+	}
+}
+
+// detectFormat определяет формат по расширению имени файла или Content-Type, по умолчанию csv
+func detectFormat(filename, contentType string) string {
+	ext := path.Ext(strings.TrimSuffix(filename, ".gz"))
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case ext == ".ndjson" || ext == ".jsonl" || mediaType == "application/x-ndjson":
+		return pricefile.FormatNDJSON
+	case ext == "" || ext == ".csv":
+		return pricefile.FormatCSV
+	default:
+		return strings.TrimPrefix(ext, ".")
+	}
+}
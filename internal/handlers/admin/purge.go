@@ -0,0 +1,135 @@
+package admin
+
+import (
+	"affarm/internal/database"
+	"affarm/internal/repository"
+	services "affarm/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxPurgeAudits - записей журнала удаления в ответе
+const maxPurgeAudits = 100
+
+// PurgeRequest - параметры удаления валюты
+type PurgeRequest struct {
+	DryRun bool   `json:"dry_run"` // только посчитать строки
+	Reason string `json:"reason"`  // причина для журнала, обязательна без dry_run
+}
+
+// PurgeCurrency godoc
+// @Summary Безвозвратное удаление валюты и ее истории
+// @Description Удаляет валюту, все ее точки цен, свертки, историю отслеживания и алерты. Точки и свертки удаляются пачками по admin.purge_batch_size строк,
+// @Description каждая пачка в своей транзакции, поэтому таблица prices не блокируется целиком. Сначала валюта переводится в archived, и чекер цен ее больше не дополняет.
+// @Description dry_run=true только считает строки. Без dry_run нужна причина (reason): каждый запуск пишется в журнал GET /admin/purges с автором из X-Actor.
+// @Description Файлы архива старых цен не изменяются, их количество возвращается в archive_files. Прерванное удаление можно запустить повторно.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param symbol path string true "Символ валюты" example(BTC)
+// @Param dry_run query bool false "Только посчитать строки"
+// @Param request body PurgeRequest false "Параметры удаления"
+// @Param Authorization header string true "Bearer <токен>"
+// @Success 200 {object} database.PurgeReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/currencies/{symbol}/purge [post]
+func (h *AdminHandler) PurgeCurrency(w http.ResponseWriter, r *http.Request) {
+	symbol := r.PathValue("symbol")
+	if err := services.ValidateSymbol(symbol); err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), http.StatusBadRequest)
+		return
+	}
+
+	var req PurgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, `{"error": "Invalid dry_run flag"}`, http.StatusBadRequest)
+			return
+		}
+		req.DryRun = req.DryRun || dryRun
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !req.DryRun && req.Reason == "" {
+		http.Error(w, `{"error": "reason is required"}`, http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(req.Reason) > 255 {
+		http.Error(w, `{"error": "reason is longer than 255 characters"}`, http.StatusBadRequest)
+		return
+	}
+
+	report, err := database.PurgeCurrency(r.Context(), h.db, symbol, database.PurgeOptions{
+		DryRun:    req.DryRun,
+		BatchSize: h.cfg.PurgeBatchSize,
+		Pause:     time.Duration(h.cfg.PurgePauseMs) * time.Millisecond,
+		Reason:    req.Reason,
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		http.Error(w, `{"error": "Currency not found"}`, http.StatusNotFound)
+		return
+	}
+	if !req.DryRun && report.AuditID != 0 {
+		// Валюта могла удалиться частично, поэтому кеши сбрасываются и после ошибки
+		if err := h.currencies.Refresh(r.Context()); err != nil {
+			log.Printf("Ошибка обновления кеша валют после удаления: %v", err)
+		}
+		h.correlations.Forget(symbol)
+		if h.archive != nil {
+			h.archive.Forget(report.CurrencyID)
+		}
+	}
+	if err != nil {
+		log.Printf("Ошибка удаления валюты %s: %v", symbol, err)
+		http.Error(w, fmt.Sprintf(`{"error": "Purge failed, see audit record", "audit_id": %d}`, report.AuditID), http.StatusInternalServerError)
+		return
+	}
+	if !req.DryRun {
+		log.Printf("Валюта %s удалена безвозвратно (журнал %d): цен %d, сверток %d, алертов %d",
+			symbol, report.AuditID, report.Prices, report.Aggregates, report.AlertRules)
+		if report.ArchiveFiles > 0 {
+			log.Printf("ВНИМАНИЕ: точки валюты %s (id %d) остаются в файлах архива (%d), их нужно удалить из хранилища вручную",
+				symbol, report.CurrencyID, report.ArchiveFiles)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ListPurges godoc
+// @Summary Журнал удаления валют
+// @Description Возвращает последние 100 запусков безвозвратного удаления валют, новые первыми: кто, когда, по какой причине и сколько строк удалено.
+// @Tags admin
+// @Produce json
+// @Param Authorization header string true "Bearer <токен>"
+// @Success 200 {array} models.PurgeAudit
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/purges [get]
+func (h *AdminHandler) ListPurges(w http.ResponseWriter, r *http.Request) {
+	audits, err := database.PurgeAudits(r.Context(), h.db, maxPurgeAudits)
+	if err != nil {
+		log.Printf("Ошибка чтения журнала удаления: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(audits)
+}
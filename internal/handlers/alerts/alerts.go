@@ -0,0 +1,343 @@
+package alerts
+
+import (
+	"affarm/internal/models"
+	services "affarm/internal/service"
+	"encoding/json"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// AlertHandler - обработчик HTTP-запросов для работы с правилами алертов
+type AlertHandler struct {
+	db       *gorm.DB
+	alerts   *services.AlertService
+	validate *validator.Validate
+}
+
+// NewAlertHandler - конструктор обработчика
+func NewAlertHandler(db *gorm.DB, alerts *services.AlertService) *AlertHandler {
+	return &AlertHandler{db: db,
+		alerts:   alerts,
+		validate: validator.New()}
+}
+
+// AlertRequest - структура запроса создания и изменения правила
+type AlertRequest struct {
+	Symbol      string  `json:"symbol" validate:"required,uppercase,max=10"`
+	Kind        string  `json:"kind" validate:"required,oneof=above below change"`
+	Threshold   float64 `json:"threshold" validate:"gt=0"`                           // уровень цены или процент изменения
+	WindowSec   int     `json:"window_sec" validate:"required_if=Kind change,gte=0"` // окно для change
+	CooldownSec int     `json:"cooldown_sec" validate:"gte=0"`
+	Webhook     string  `json:"webhook,omitempty" validate:"max=64"` // пусто - все настроенные вебхуки
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
+
+// AlertResponse - правило алерта
+type AlertResponse struct {
+	ID              uint       `json:"id"`
+	Symbol          string     `json:"symbol"`
+	Kind            string     `json:"kind" enums:"above,below,change"`
+	Threshold       float64    `json:"threshold"`
+	WindowSec       int        `json:"window_sec"`
+	CooldownSec     int        `json:"cooldown_sec"`
+	Webhook         string     `json:"webhook"`
+	Enabled         bool       `json:"enabled"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// DeliveryResponse - запись журнала доставки
+type DeliveryResponse struct {
+	ID            uint       `json:"id"`
+	Webhook       string     `json:"webhook"`
+	Status        string     `json:"status" enums:"pending,delivered,failed"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code"`
+	LastError     string     `json:"last_error,omitempty"`
+	Payload       string     `json:"payload"`
+	CreatedAt     time.Time  `json:"created_at"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+}
+
+func newAlertResponse(rule models.AlertRule) AlertResponse {
+	return AlertResponse{
+		ID:              rule.ID,
+		Symbol:          rule.Currency.Symbol,
+		Kind:            rule.Kind,
+		Threshold:       rule.Threshold,
+		WindowSec:       rule.WindowSec,
+		CooldownSec:     rule.CooldownSec,
+		Webhook:         rule.Webhook,
+		Enabled:         rule.Enabled,
+		LastTriggeredAt: rule.LastTriggeredAt,
+		CreatedAt:       rule.CreatedAt,
+	}
+}
+
+// CreateAlert godoc
+// @Summary Создать правило алерта
+// @Description Создает правило: пересечение уровня (above/below) или изменение больше чем на threshold процентов за window_sec (change).
+// @Description Уведомления отправляются на вебхуки из конфига с подписью HMAC-SHA256 в заголовке X-Affarm-Signature.
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param request body AlertRequest true "Правило"
+// @Success 201 {object} AlertResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /alerts [post]
+func (h *AlertHandler) CreateAlert(w http.ResponseWriter, r *http.Request) {
+	var req AlertRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	rule := models.AlertRule{Enabled: true}
+	if !h.apply(w, &rule, req) {
+		return
+	}
+
+	if err := h.db.Omit("Currency").Create(&rule).Error; err != nil {
+		log.Printf("ошибка сохранения алерта: %v", err)
+		http.Error(w, `{"error": "Failed to save alert"}`, http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, http.StatusCreated, newAlertResponse(rule))
+	log.Printf("Добавлен алерт %d: %s %s %v", rule.ID, req.Symbol, rule.Kind, rule.Threshold)
+}
+
+// ListAlerts godoc
+// @Summary Список правил алертов
+// @Tags alerts
+// @Produce json
+// @Param symbol query string false "Фильтр по валюте"
+// @Success 200 {array} AlertResponse
+// @Failure 500 {object} map[string]string
+// @Router /alerts [get]
+func (h *AlertHandler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	query := h.db.Joins("Currency").Order("alert_rules.id")
+	if symbol := r.URL.Query().Get("symbol"); symbol != "" {
+		query = query.Where(`"Currency".symbol = ?`, symbol)
+	}
+
+	var rules []models.AlertRule
+	if err := query.Find(&rules).Error; err != nil {
+		log.Printf("ошибка загрузки алертов: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]AlertResponse, 0, len(rules))
+	for _, rule := range rules {
+		resp = append(resp, newAlertResponse(rule))
+	}
+	jsonResponse(w, http.StatusOK, resp)
+}
+
+// GetAlert godoc
+// @Summary Получить правило алерта
+// @Tags alerts
+// @Produce json
+// @Param id path int true "ID правила"
+// @Success 200 {object} AlertResponse
+// @Failure 404 {object} map[string]string
+// @Router /alerts/{id} [get]
+func (h *AlertHandler) GetAlert(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadAlert(w, r)
+	if !ok {
+		return
+	}
+	jsonResponse(w, http.StatusOK, newAlertResponse(rule))
+}
+
+// UpdateAlert godoc
+// @Summary Изменить правило алерта
+// @Tags alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "ID правила"
+// @Param request body AlertRequest true "Правило"
+// @Success 200 {object} AlertResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /alerts/{id} [put]
+func (h *AlertHandler) UpdateAlert(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadAlert(w, r)
+	if !ok {
+		return
+	}
+
+	var req AlertRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if !h.apply(w, &rule, req) {
+		return
+	}
+	// После изменения условия пересечение отслеживается заново
+	rule.LastPrice = nil
+
+	if err := h.db.Omit("Currency").Save(&rule).Error; err != nil {
+		log.Printf("ошибка сохранения алерта: %v", err)
+		http.Error(w, `{"error": "Failed to save alert"}`, http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, http.StatusOK, newAlertResponse(rule))
+}
+
+// DeleteAlert godoc
+// @Summary Удалить правило алерта
+// @Tags alerts
+// @Produce json
+// @Param id path int true "ID правила"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /alerts/{id} [delete]
+func (h *AlertHandler) DeleteAlert(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadAlert(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Delete(&rule).Error; err != nil {
+		log.Printf("ошибка удаления алерта: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, http.StatusOK, map[string]string{"message": "Alert successfully deleted"})
+	log.Printf("Удален алерт %d", rule.ID)
+}
+
+// ListDeliveries godoc
+// @Summary Журнал доставки уведомлений алерта
+// @Tags alerts
+// @Produce json
+// @Param id path int true "ID правила"
+// @Param limit query int false "Количество записей, по умолчанию 100"
+// @Success 200 {array} DeliveryResponse
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /alerts/{id}/deliveries [get]
+func (h *AlertHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.loadAlert(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 100
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 && n <= 1000 {
+			limit = n
+		}
+	}
+
+	var deliveries []models.AlertDelivery
+	if err := h.db.Where("alert_rule_id = ?", rule.ID).Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		log.Printf("ошибка загрузки журнала доставки: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]DeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, DeliveryResponse{
+			ID:            d.ID,
+			Webhook:       d.Webhook,
+			Status:        d.Status,
+			Attempts:      d.Attempts,
+			ResponseCode:  d.ResponseCode,
+			LastError:     d.LastError,
+			Payload:       d.Payload,
+			CreatedAt:     d.CreatedAt,
+			NextAttemptAt: d.NextAttemptAt,
+			DeliveredAt:   d.DeliveredAt,
+		})
+	}
+	jsonResponse(w, http.StatusOK, resp)
+}
+
+func (h *AlertHandler) decode(w http.ResponseWriter, r *http.Request, req *AlertRequest) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return false
+	}
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return false
+	}
+	if req.Webhook != "" && !h.alerts.HasWebhook(req.Webhook) {
+		http.Error(w, `{"error": "Unknown webhook `+req.Webhook+`"}`, http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// apply переносит поля запроса в правило, находя валюту по символу
+func (h *AlertHandler) apply(w http.ResponseWriter, rule *models.AlertRule, req AlertRequest) bool {
+	var currency models.Currency
+	err := h.db.Where("symbol = ?", req.Symbol).First(&currency).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, `{"error": "Currency not found"}`, http.StatusNotFound)
+		return false
+	}
+	if err != nil {
+		log.Printf("ошибка поиска валюты: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return false
+	}
+
+	rule.CurrencyID = currency.ID
+	rule.Currency = currency
+	rule.Kind = req.Kind
+	rule.Threshold = req.Threshold
+	rule.WindowSec = req.WindowSec
+	rule.CooldownSec = req.CooldownSec
+	rule.Webhook = req.Webhook
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return true
+}
+
+func (h *AlertHandler) loadAlert(w http.ResponseWriter, r *http.Request) (models.AlertRule, bool) {
+	var rule models.AlertRule
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid alert id"}`, http.StatusBadRequest)
+		return rule, false
+	}
+
+	err = h.db.Joins("Currency").First(&rule, "alert_rules.id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		http.Error(w, `{"error": "Alert not found"}`, http.StatusNotFound)
+		return rule, false
+	}
+	if err != nil {
+		log.Printf("ошибка загрузки алерта: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return rule, false
+	}
+	return rule, true
+}
+
+func jsonResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
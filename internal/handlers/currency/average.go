@@ -0,0 +1,151 @@
+package currency
+
+import (
+	services "affarm/internal/service"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// AverageResponse - средневзвешенные цены за период
+type AverageResponse struct {
+	Symbol         string    `json:"symbol"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	MaxGap         string    `json:"max_gap"`
+	TWAP           *float64  `json:"twap"`            // средняя цена, взвешенная по времени действия точек
+	TWAPSamples    int       `json:"twap_samples"`    // точек внутри периода
+	CoveredSeconds float64   `json:"covered_seconds"` // сколько секунд периода покрыто точками
+	Coverage       float64   `json:"coverage"`        // covered_seconds / длительность периода
+	VWAP           *float64  `json:"vwap"`            // средняя цена, взвешенная по объему; null, если объемов нет
+	VWAPSamples    int       `json:"vwap_samples"`    // точек с объемом внутри периода
+	Volume         float64   `json:"volume"`
+}
+
+// averageSQL считает TWAP и VWAP за период [$2, $3).
+// Каждая точка действует от своей метки до следующей точки, но не дольше $4 секунд;
+// последняя точка перед началом периода переносится на его начало.
+const averageSQL = `
+WITH pts AS (
+    (SELECT timestamp, price::float8 AS price, volume::float8 AS volume
+     FROM prices
+     WHERE currency_id = $1 AND timestamp < $2
+     ORDER BY timestamp DESC
+     LIMIT 1)
+    UNION ALL
+    SELECT timestamp, price::float8, volume::float8
+    FROM prices
+    WHERE currency_id = $1 AND timestamp >= $2 AND timestamp < $3
+), spans AS (
+    SELECT GREATEST(timestamp, $2) AS start_at,
+           LEAST(COALESCE(lead(timestamp) OVER (ORDER BY timestamp), $3),
+                 timestamp + make_interval(secs => $4),
+                 $3) AS end_at,
+           price, volume, timestamp >= $2 AS inside
+    FROM pts
+), weighted AS (
+    SELECT price, volume, inside,
+           GREATEST(extract(epoch FROM end_at - start_at)::float8, 0) AS seconds
+    FROM spans
+)
+SELECT sum(price * seconds) / NULLIF(sum(seconds), 0),
+       COALESCE(sum(seconds), 0),
+       count(*) FILTER (WHERE inside),
+       sum(price * volume) FILTER (WHERE inside) / NULLIF(sum(volume) FILTER (WHERE inside), 0),
+       count(volume) FILTER (WHERE inside),
+       COALESCE(sum(volume) FILTER (WHERE inside), 0)
+FROM weighted`
+
+// averageRetentionSQL возвращает последний момент в периоде [$2, $3), свернутый retention.
+// TWAP и VWAP по сверткам не восстанавливаются: close на конец свечи не заменяет цены внутри нее
+const averageRetentionSQL = `
+SELECT max(last_at)
+FROM price_aggregates
+WHERE currency_id = $1 AND last_at >= $2 AND last_at < $3`
+
+// GetAverage godoc
+// @Summary TWAP и VWAP за период
+// @Description Возвращает среднюю цену за период, взвешенную по времени действия каждой точки (TWAP), и по объему (VWAP), если объемы собираются.
+// @Description Точка действует до следующей, но не дольше max_gap; coverage показывает долю периода, покрытую точками.
+// @Description Период, часть которого уже свернута retention, отклоняется с кодом 422: TWAP и VWAP считаются только по сырым точкам.
+// @Tags prices
+// @Produce json
+// @Param symbol path string true "Символ валюты" example(BTC)
+// @Param from query string true "Начало периода"
+// @Param to query string true "Конец периода (не включительно)"
+// @Param max_gap query string false "Максимальное время действия точки (Go duration), по умолчанию 10m" example(10m)
+// @Success 200 {object} AverageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Router /currency/{symbol}/average [get]
+func (h *CurrencyHandler) GetAverage(w http.ResponseWriter, r *http.Request) {
+	if !h.requirePostgres(w) {
+		return
+	}
+
+	symbol := r.PathValue("symbol")
+	query := r.URL.Query()
+
+	from, err := ParseTimestamp(query.Get("from"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid from timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	to, err := ParseTimestamp(query.Get("to"))
+	if err != nil || !to.After(from) {
+		http.Error(w, `{"error": "Invalid to timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	maxGap := services.StaleAfter
+	if raw := query.Get("max_gap"); raw != "" {
+		if maxGap, err = time.ParseDuration(raw); err != nil || maxGap <= 0 {
+			http.Error(w, `{"error": "Invalid max_gap"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	db, currencyID, ok := h.analyticsCurrency(r.Context(), w, symbol)
+	if !ok {
+		return
+	}
+
+	var downsampled sql.NullTime
+	if err := db.QueryRowContext(r.Context(), averageRetentionSQL, currencyID, from, to).Scan(&downsampled); err != nil {
+		log.Printf("Average retention query error: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if downsampled.Valid {
+		msg := fmt.Sprintf("Prices of %s up to %s are past raw retention and downsampled; TWAP and VWAP need raw prices, start the period after it",
+			symbol, downsampled.Time.UTC().Format(time.RFC3339))
+		body, _ := json.Marshal(map[string]string{"error": msg})
+		http.Error(w, string(body), http.StatusUnprocessableEntity)
+		return
+	}
+
+	resp := AverageResponse{Symbol: symbol, From: from, To: to, MaxGap: maxGap.String()}
+	var twap, vwap sql.NullFloat64
+	err = db.QueryRowContext(r.Context(), averageSQL, currencyID, from, to, maxGap.Seconds()).Scan(
+		&twap, &resp.CoveredSeconds, &resp.TWAPSamples, &vwap, &resp.VWAPSamples, &resp.Volume)
+	if err != nil {
+		log.Printf("Average query error: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	resp.TWAP = nullableFloat(twap)
+	resp.VWAP = nullableFloat(vwap)
+	resp.Coverage = resp.CoveredSeconds / to.Sub(from).Seconds()
+
+	if resp.TWAP == nil {
+		http.Error(w, `{"error": "No price data available for `+symbol+`"}`, http.StatusNotFound)
+		return
+	}
+	jsonResponse(w, resp)
+}
@@ -0,0 +1,82 @@
+package currency
+
+import (
+	services "affarm/internal/service"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// LookupItem - одна пара (валюта, момент времени) пакетного запроса
+type LookupItem struct {
+	Symbol    string    `json:"symbol" example:"BTC"`
+	Timestamp Timestamp `json:"timestamp" swaggertype:"string" example:"1736500490"`
+}
+
+// LookupRequest - структура пакетного запроса цен
+type LookupRequest struct {
+	Items       []LookupItem `json:"items"`
+	Interpolate bool         `json:"interpolate"`
+	BridgeGaps  *bool        `json:"bridge_gaps,omitempty"` // false - не брать точки из-за пробелов в отслеживании, по умолчанию true
+}
+
+// LookupResult - результат для одной пары, в том же порядке, что и в запросе.
+// При ошибке заполняется только error, остальные пары обрабатываются независимо
+type LookupResult struct {
+	Index  int                     `json:"index"`
+	Symbol string                  `json:"symbol"`
+	At     time.Time               `json:"at"`
+	Result *services.PriceResponse `json:"result,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+// LookupResponse - структура ответа пакетного запроса
+type LookupResponse struct {
+	Results []LookupResult `json:"results"`
+}
+
+// LookupPricesBatch godoc
+// @Summary Пакетный поиск цен на моменты времени
+// @Description Принимает набор пар (symbol, timestamp) и возвращает цены в порядке запроса.
+// @Description Все пары разрешаются одним SQL-запросом; ошибки возвращаются по каждой паре отдельно.
+// @Description bridge_gaps=false ограничивает поиск периодами отслеживания валют, как в GET /currency/price.
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Param request body LookupRequest true "Пары валюта/момент времени"
+// @Success 200 {object} LookupResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /prices/lookup [post]
+func (h *CurrencyHandler) LookupPricesBatch(w http.ResponseWriter, r *http.Request) {
+	var req LookupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	queries := make([]services.PriceQuery, len(req.Items))
+	for i, item := range req.Items {
+		queries[i] = services.PriceQuery{Symbol: item.Symbol, At: item.Timestamp.Time}
+	}
+
+	opts := services.LookupOptions{Interpolate: req.Interpolate, NoBridgeGaps: req.BridgeGaps != nil && !*req.BridgeGaps}
+	batch, err := h.prices.Batch(r.Context(), queries, opts)
+	if err != nil {
+		serviceError(w, err)
+		return
+	}
+
+	// Ошибки отдельных пар не влияют на остальные и возвращаются в результате пары
+	results := make([]LookupResult, len(batch))
+	for i, item := range batch {
+		results[i] = LookupResult{Index: i, Symbol: req.Items[i].Symbol, At: req.Items[i].Timestamp.Time}
+		if item.Err != nil {
+			results[i].Error = item.Err.Error()
+		} else {
+			results[i].Result = &item.Response
+		}
+	}
+
+	jsonResponse(w, LookupResponse{Results: results})
+}
@@ -0,0 +1,136 @@
+package currency
+
+import (
+	services "affarm/internal/service"
+	"github.com/shopspring/decimal"
+	"log"
+	"net/http"
+	"time"
+)
+
+// convertPrecision - количество знаков после запятой при делении курсов
+const convertPrecision = 18
+
+// ConvertLeg - цена одной валюты в опорной валюте, использованная для конвертации
+type ConvertLeg struct {
+	Symbol        string          `json:"symbol"`
+	Price         decimal.Decimal `json:"price" swaggertype:"string" example:"117000.5"`
+	Timestamp     time.Time       `json:"timestamp"`
+	OffsetSeconds float64         `json:"offset_seconds"`
+	Source        string          `json:"source"`
+	Quality       string          `json:"quality" enums:"exact,nearest,stale"`
+}
+
+// ConvertResponse - результат конвертации суммы на момент времени
+type ConvertResponse struct {
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Amount  decimal.Decimal `json:"amount" swaggertype:"string" example:"2.5"`
+	Rate    decimal.Decimal `json:"rate" swaggertype:"string"`   // сколько to стоит одна единица from
+	Result  decimal.Decimal `json:"result" swaggertype:"string"` // amount * rate
+	At      time.Time       `json:"at"`
+	Via     string          `json:"via"` // опорная валюта, через которую считается курс
+	FromLeg ConvertLeg      `json:"from_leg"`
+	ToLeg   ConvertLeg      `json:"to_leg"`
+}
+
+// Convert godoc
+// @Summary Конвертировать сумму на момент времени
+// @Description Пересчитывает amount из валюты from в валюту to по сохраненным ценам обеих валют в опорной валюте (convertation) на момент at.
+// @Description Для каждой валюты берется точное или ближайшее значение; расчет ведется в десятичной арифметике.
+// @Tags prices
+// @Produce json
+// @Param from query string true "Исходная валюта" example(ETH)
+// @Param to query string true "Целевая валюта" example(BTC)
+// @Param amount query string true "Сумма" example(2.5)
+// @Param at query string false "Момент времени (Unix-секунды, миллисекунды или RFC3339), по умолчанию - текущий"
+// @Success 200 {object} ConvertResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /convert [get]
+func (h *CurrencyHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+
+	if err := h.validate.Var(from, "required,uppercase,max=10"); err != nil {
+		http.Error(w, `{"error": "Invalid from currency"}`, http.StatusBadRequest)
+		return
+	}
+	if err := h.validate.Var(to, "required,uppercase,max=10"); err != nil {
+		http.Error(w, `{"error": "Invalid to currency"}`, http.StatusBadRequest)
+		return
+	}
+
+	amount, err := decimal.NewFromString(query.Get("amount"))
+	if err != nil || !amount.IsPositive() {
+		http.Error(w, `{"error": "amount must be a positive decimal number"}`, http.StatusBadRequest)
+		return
+	}
+
+	at := time.Now().UTC()
+	if raw := query.Get("at"); raw != "" {
+		if at, err = ParseTimestamp(raw); err != nil {
+			http.Error(w, `{"error": "Invalid at timestamp"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	lookups, err := h.prices.Lookup(r.Context(), []services.PriceQuery{{Symbol: from, At: at}, {Symbol: to, At: at}})
+	if err != nil {
+		log.Printf("Convert lookup error: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	fromLeg, status, msg := h.convertLeg(from, at, lookups[0])
+	if status != http.StatusOK {
+		http.Error(w, `{"error": "`+msg+`"}`, status)
+		return
+	}
+	toLeg, status, msg := h.convertLeg(to, at, lookups[1])
+	if status != http.StatusOK {
+		http.Error(w, `{"error": "`+msg+`"}`, status)
+		return
+	}
+
+	jsonResponse(w, ConvertResponse{
+		From:    from,
+		To:      to,
+		Amount:  amount,
+		Rate:    fromLeg.Price.DivRound(toLeg.Price, convertPrecision),
+		Result:  amount.Mul(fromLeg.Price).DivRound(toLeg.Price, convertPrecision),
+		At:      at,
+		Via:     h.quote,
+		FromLeg: fromLeg,
+		ToLeg:   toLeg,
+	})
+}
+
+// convertLeg возвращает цену валюты в опорной валюте. Сама опорная валюта стоит 1
+func (h *CurrencyHandler) convertLeg(symbol string, at time.Time, lookup services.PriceLookup) (ConvertLeg, int, string) {
+	if symbol == h.quote {
+		return ConvertLeg{Symbol: symbol, Price: decimal.NewFromInt(1), Timestamp: at, Quality: services.QualityExact}, http.StatusOK, ""
+	}
+	if !lookup.CurrencyFound {
+		return ConvertLeg{}, http.StatusNotFound, "Currency not found: " + symbol
+	}
+
+	resp, ok := lookup.Resolve(symbol, at, false)
+	if !ok {
+		return ConvertLeg{}, http.StatusNotFound, "No price data available for " + symbol
+	}
+	point := resp.Points[0]
+	if point.Decimal.IsZero() {
+		return ConvertLeg{}, http.StatusUnprocessableEntity, "Zero price stored for " + symbol
+	}
+
+	return ConvertLeg{
+		Symbol:        symbol,
+		Price:         point.Decimal,
+		Timestamp:     resp.Timestamp,
+		OffsetSeconds: resp.OffsetSeconds,
+		Source:        resp.Source,
+		Quality:       resp.Quality,
+	}, http.StatusOK, ""
+}
@@ -0,0 +1,212 @@
+package currency
+
+import (
+	services "affarm/internal/service"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MaxCorrelationSymbols - максимальное количество валют в матрице корреляций
+const MaxCorrelationSymbols = 50
+
+// CorrelationResponse - матрица корреляций доходностей и беты относительно бенчмарка
+type CorrelationResponse struct {
+	Symbols   []string            `json:"symbols"`
+	Benchmark string              `json:"benchmark"`
+	From      time.Time           `json:"from"`
+	To        time.Time           `json:"to"`
+	Interval  string              `json:"interval"`
+	Matrix    [][]*float64        `json:"matrix"`  // matrix[i][j] - корреляция symbols[i] и symbols[j], null если данных недостаточно
+	Samples   [][]int             `json:"samples"` // количество общих интервалов для каждой пары
+	Beta      map[string]*float64 `json:"beta"`    // бета каждой валюты относительно benchmark
+	Cached    bool                `json:"cached"`
+}
+
+// correlationSQL пересемплирует ряды всех валют на общую сетку date_bin, считает лог-доходности
+// и попарные корреляции (corr) и беты (regr_slope) по совпадающим интервалам.
+// Начало ряда, уже свернутое retention, берется из сверток, как в statsSQL
+const correlationSQL = `
+WITH raw AS (
+    SELECT c.symbol, p.timestamp, p.price::float8 AS price
+    FROM prices p
+    JOIN currencies c ON c.id = p.currency_id
+    WHERE c.symbol = ANY($1) AND p.timestamp >= $3 AND p.timestamp < $4
+), pts AS (
+    SELECT symbol, timestamp, price FROM raw
+    UNION ALL
+    SELECT c.symbol, g.last_at, g.close::float8
+    FROM price_aggregates g
+    JOIN currencies c ON c.id = g.currency_id
+    WHERE c.symbol = ANY($1) AND g.last_at >= $3
+      AND g.last_at < COALESCE((SELECT min(r.timestamp) FROM raw r WHERE r.symbol = c.symbol), $4)
+), buckets AS (
+    SELECT symbol, date_bin($2::interval, timestamp, $3) AS bucket,
+           (array_agg(price ORDER BY timestamp DESC))[1] AS close
+    FROM pts
+    GROUP BY 1, 2
+), returns AS (
+    SELECT symbol, bucket,
+           ln(close / NULLIF(lag(close) OVER (PARTITION BY symbol ORDER BY bucket), 0)) AS ret
+    FROM buckets
+)
+SELECT a.symbol, b.symbol, corr(a.ret, b.ret), regr_slope(a.ret, b.ret), count(*)
+FROM returns a
+JOIN returns b ON a.bucket = b.bucket
+WHERE a.ret IS NOT NULL AND b.ret IS NOT NULL
+GROUP BY 1, 2`
+
+// GetCorrelation godoc
+// @Summary Матрица корреляций валют
+// @Description Пересемплирует ряды выбранных валют на общую сетку interval за период и возвращает попарные корреляции лог-доходностей
+// @Description и бету каждой валюты относительно benchmark. Результаты кешируются и сбрасываются при поступлении новых цен внутри периода.
+// @Tags prices
+// @Produce json
+// @Param symbols query string true "Символы через запятую" example(BTC,ETH,SOL)
+// @Param benchmark query string false "Бенчмарк для беты, по умолчанию BTC" example(BTC)
+// @Param from query string true "Начало периода"
+// @Param to query string true "Конец периода (не включительно)"
+// @Param interval query string false "Шаг сетки (Go duration), по умолчанию 1h" example(1h)
+// @Success 200 {object} CorrelationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Router /correlation [get]
+func (h *CurrencyHandler) GetCorrelation(w http.ResponseWriter, r *http.Request) {
+	if !h.requirePostgres(w) {
+		return
+	}
+
+	query := r.URL.Query()
+
+	benchmark := query.Get("benchmark")
+	if benchmark == "" {
+		benchmark = "BTC"
+	}
+	if err := h.validate.Var(benchmark, "uppercase,max=10"); err != nil {
+		http.Error(w, `{"error": "Invalid benchmark"}`, http.StatusBadRequest)
+		return
+	}
+
+	symbols := []string{benchmark}
+	for _, symbol := range strings.Split(query.Get("symbols"), ",") {
+		symbol = strings.TrimSpace(symbol)
+		if symbol == "" {
+			continue
+		}
+		if err := h.validate.Var(symbol, "uppercase,max=10"); err != nil {
+			http.Error(w, `{"error": "Invalid symbol `+symbol+`"}`, http.StatusBadRequest)
+			return
+		}
+		symbols = append(symbols, symbol)
+	}
+	slices.Sort(symbols)
+	symbols = slices.Compact(symbols)
+	if len(symbols) < 2 || len(symbols) > MaxCorrelationSymbols {
+		http.Error(w, fmt.Sprintf(`{"error": "symbols must contain from 2 to %d currencies"}`, MaxCorrelationSymbols), http.StatusBadRequest)
+		return
+	}
+
+	from, err := ParseTimestamp(query.Get("from"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid from timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	to, err := ParseTimestamp(query.Get("to"))
+	if err != nil || !to.After(from) {
+		http.Error(w, `{"error": "Invalid to timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	interval := time.Hour
+	if raw := query.Get("interval"); raw != "" {
+		if interval, err = time.ParseDuration(raw); err != nil || interval < time.Second {
+			http.Error(w, `{"error": "Invalid interval"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	if to.Sub(from)/interval > MaxStatsBuckets {
+		http.Error(w, fmt.Sprintf(`{"error": "too many intervals, max %d"}`, MaxStatsBuckets), http.StatusBadRequest)
+		return
+	}
+
+	key := services.CorrelationKey{
+		Symbols:   strings.Join(symbols, ","),
+		Benchmark: benchmark,
+		From:      from,
+		To:        to,
+		Interval:  interval,
+	}
+	if cached, ok := h.correlations.Get(key); ok {
+		resp := cached.(CorrelationResponse)
+		resp.Cached = true
+		jsonResponse(w, resp)
+		return
+	}
+
+	db, err := h.db.DB()
+	if err != nil {
+		log.Printf("Failed to get DB connection: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := db.QueryContext(r.Context(), correlationSQL,
+		symbols, fmt.Sprintf("%d seconds", int64(interval/time.Second)), from, to)
+	if err != nil {
+		log.Printf("Correlation query error: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	index := make(map[string]int, len(symbols))
+	for i, symbol := range symbols {
+		index[symbol] = i
+	}
+	resp := CorrelationResponse{
+		Symbols:   symbols,
+		Benchmark: benchmark,
+		From:      from,
+		To:        to,
+		Interval:  interval.String(),
+		Matrix:    make([][]*float64, len(symbols)),
+		Samples:   make([][]int, len(symbols)),
+		Beta:      make(map[string]*float64, len(symbols)),
+	}
+	for i := range symbols {
+		resp.Matrix[i] = make([]*float64, len(symbols))
+		resp.Samples[i] = make([]int, len(symbols))
+		resp.Beta[symbols[i]] = nil
+	}
+
+	for rows.Next() {
+		var (
+			a, b        string
+			corr, slope sql.NullFloat64
+			samples     int
+		)
+		if err := rows.Scan(&a, &b, &corr, &slope, &samples); err != nil {
+			log.Printf("Correlation scan error: %v", err)
+			http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+			return
+		}
+		i, j := index[a], index[b]
+		resp.Matrix[i][j] = nullableFloat(corr)
+		resp.Samples[i][j] = samples
+		if b == benchmark {
+			resp.Beta[a] = nullableFloat(slope)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Correlation rows error: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.correlations.Put(key, symbols, resp)
+	jsonResponse(w, resp)
+}
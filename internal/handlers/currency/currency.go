@@ -1,18 +1,68 @@
 package currency
 
 import (
+	services "affarm/internal/service"
+	"context"
+	"database/sql"
 	"github.com/go-playground/validator/v10"
 	"gorm.io/gorm"
+	"log"
+	"net/http"
 )
 
 // CurrencyHandler - обработчик HTTP-запросов для работы с валютами
 type CurrencyHandler struct {
-	db       *gorm.DB
-	validate *validator.Validate
+	db           *gorm.DB
+	currencies   *services.CurrencyService
+	prices       *services.PriceService
+	cache        *services.PriceCache
+	correlations *services.CorrelationCache
+	quote        string // опорная валюта, в которой хранятся цены (convertation из конфига)
+	driver       string // хранилище из конфига (storage.driver)
+	validate     *validator.Validate
 }
 
 // NewCurrencyHandler - конструктор обработчика
-func NewCurrencyHandler(db *gorm.DB) *CurrencyHandler {
+func NewCurrencyHandler(db *gorm.DB, svc *services.Services, quote, driver string) *CurrencyHandler {
 	return &CurrencyHandler{db: db,
-		validate: validator.New()}
+		currencies:   svc.Currencies,
+		prices:       svc.Prices,
+		cache:        svc.Cache,
+		correlations: svc.Correlations,
+		quote:        quote,
+		driver:       driver,
+		validate:     validator.New()}
+}
+
+// requirePostgres отвечает 501, если хранилище не PostgreSQL: аналитика (/stats, /average, /correlation)
+// считается SQL-запросами PostgreSQL, а в memory валют и цен в базе нет вовсе
+func (h *CurrencyHandler) requirePostgres(w http.ResponseWriter) bool {
+	if h.driver == "postgres" {
+		return true
+	}
+	http.Error(w, `{"error": "Analytics is only available with storage.driver postgres"}`, http.StatusNotImplemented)
+	return false
+}
+
+// analyticsCurrency возвращает соединение для SQL-аналитики и id валюты по символу.
+// При ошибке сам пишет ответ и возвращает false
+func (h *CurrencyHandler) analyticsCurrency(ctx context.Context, w http.ResponseWriter, symbol string) (*sql.DB, uint, bool) {
+	db, err := h.db.DB()
+	if err != nil {
+		log.Printf("Failed to get DB connection: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return nil, 0, false
+	}
+
+	var currencyID uint
+	err = db.QueryRowContext(ctx, "SELECT id FROM currencies WHERE symbol = $1", symbol).Scan(&currencyID)
+	if err == sql.ErrNoRows {
+		http.Error(w, `{"error": "Currency not found"}`, http.StatusNotFound)
+		return nil, 0, false
+	} else if err != nil {
+		log.Printf("Currency lookup error: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return nil, 0, false
+	}
+	return db, currencyID, true
 }
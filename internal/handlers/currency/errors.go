@@ -0,0 +1,29 @@
+package currency
+
+import (
+	services "affarm/internal/service"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// serviceError переводит ошибку сервиса в HTTP-ответ: статус по категории, текст из доменной ошибки.
+// Внутренние ошибки логируются, а клиенту отдаются без подробностей
+func serviceError(w http.ResponseWriter, err error) {
+	var status int
+	switch services.KindOf(err) {
+	case services.KindNotFound:
+		status = http.StatusNotFound
+	case services.KindConflict:
+		status = http.StatusConflict
+	case services.KindInvalid:
+		status = http.StatusBadRequest
+	default:
+		log.Printf("Ошибка сервиса: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+	http.Error(w, string(body), status)
+}
@@ -0,0 +1,118 @@
+package currency
+
+import (
+	"affarm/internal/pricefile"
+	services "affarm/internal/service"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ExportPrices godoc
+// @Summary Выгрузка истории цен
+// @Description Выгружает сырые точки рядов в диапазоне [from, to) файлом CSV, NDJSON или Parquet: по валютам и по возрастанию времени.
+// @Description Строки читаются из бд курсором и сразу пишутся в ответ, поэтому размер выгрузки не ограничен.
+// @Description gzip=true сжимает файл целиком (application/gzip); без него текстовые форматы сжимаются при Accept-Encoding: gzip.
+// @Description Если бд отказала посреди выгрузки, соединение обрывается, и клиент получает неполный файл с ошибкой передачи.
+// @Tags prices
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.apache.parquet
+// @Param symbols query string false "Символы через запятую, по умолчанию все отслеживаемые валюты" example(BTC,ETH)
+// @Param from query string true "Начало периода"
+// @Param to query string true "Конец периода (не включительно)"
+// @Param format query string false "Формат файла" Enums(csv, ndjson, parquet) default(csv)
+// @Param gzip query bool false "Сжать файл gzip"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /export/prices [get]
+func (h *CurrencyHandler) ExportPrices(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	from, err := ParseTimestamp(query.Get("from"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid from timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	to, err := ParseTimestamp(query.Get("to"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid to timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	export := services.ExportQuery{From: from, To: to, Format: query.Get("format")}
+	if export.Format == "" {
+		export.Format = pricefile.FormatCSV
+	}
+	if raw := query.Get("symbols"); raw != "" {
+		for _, symbol := range strings.Split(raw, ",") {
+			export.Symbols = append(export.Symbols, strings.TrimSpace(symbol))
+		}
+	}
+	compress := query.Get("gzip") == "true" || query.Get("gzip") == "1"
+	if err := export.Validate(); err != nil {
+		serviceError(w, err)
+		return
+	}
+
+	ext, _ := pricefile.Extension(export.Format)
+	filename := fmt.Sprintf("prices_%s_%s%s", from.Format("20060102T150405Z"), to.Format("20060102T150405Z"), ext)
+	header := w.Header()
+	switch {
+	case compress:
+		header.Set("Content-Type", "application/gzip")
+		filename += ".gz"
+	case export.Format != pricefile.FormatParquet && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip"):
+		// Parquet уже сжат по колонкам, повторное сжатие только тратит процессор
+		header.Set("Content-Type", pricefile.ContentType(export.Format))
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		compress = true
+	default:
+		header.Set("Content-Type", pricefile.ContentType(export.Format))
+	}
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	out := &countingWriter{w: w}
+	var body io.Writer = out
+	var zw *gzip.Writer
+	if compress {
+		zw = gzip.NewWriter(out)
+		body = zw
+	}
+
+	rows, err := h.prices.Export(r.Context(), export, body)
+	if err == nil && zw != nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		return
+	}
+	if out.n == 0 {
+		// Ответ еще не начат: вместо файла отдается обычная ошибка
+		for _, key := range []string{"Content-Disposition", "Content-Encoding", "Vary"} {
+			header.Del(key)
+		}
+		serviceError(w, err)
+		return
+	}
+	// Часть файла уже ушла клиенту: соединение обрывается, чтобы обрезанный файл не сошел за полный
+	log.Printf("Выгрузка цен прервана после %d строк: %v", rows, err)
+	panic(http.ErrAbortHandler)
+}
+
+// countingWriter считает байты, ушедшие в ответ
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,57 @@
+package currency
+
+import (
+	"net/http"
+	"time"
+)
+
+// LatestPriceResponse - последняя известная цена валюты
+type LatestPriceResponse struct {
+	Symbol     string    `json:"symbol"`
+	Price      float64   `json:"price"`
+	Timestamp  time.Time `json:"timestamp"`
+	AgeSeconds float64   `json:"age_seconds"` // сколько секунд прошло с момента цены
+	Source     string    `json:"source"`
+}
+
+// ListCurrencies godoc
+// @Summary Список валют
+// @Description Возвращает все известные валюты со статусом (active, paused, archived), датой добавления, последней ценой и количеством точек. Данные отдаются из кеша в памяти.
+// @Tags currencies
+// @Produce json
+// @Success 200 {array} services.CurrencySnapshot
+// @Router /currencies [get]
+func (h *CurrencyHandler) ListCurrencies(w http.ResponseWriter, r *http.Request) {
+	jsonResponse(w, h.currencies.List())
+}
+
+// GetLatestPrice godoc
+// @Summary Последняя цена валюты
+// @Description Возвращает последнюю сохраненную цену валюты из кеша в памяти
+// @Tags prices
+// @Produce json
+// @Param symbol path string true "Символ валюты" example(BTC)
+// @Success 200 {object} LatestPriceResponse
+// @Failure 404 {object} map[string]string
+// @Router /currency/{symbol}/latest [get]
+func (h *CurrencyHandler) GetLatestPrice(w http.ResponseWriter, r *http.Request) {
+	symbol := r.PathValue("symbol")
+
+	snapshot, ok := h.cache.Get(symbol)
+	if !ok {
+		http.Error(w, `{"error": "Currency not found"}`, http.StatusNotFound)
+		return
+	}
+	if snapshot.LastPrice == nil {
+		http.Error(w, `{"error": "No price data available for `+symbol+`"}`, http.StatusNotFound)
+		return
+	}
+
+	jsonResponse(w, LatestPriceResponse{
+		Symbol:     snapshot.Symbol,
+		Price:      *snapshot.LastPrice,
+		Timestamp:  *snapshot.LastUpdate,
+		AgeSeconds: time.Since(*snapshot.LastUpdate).Seconds(),
+		Source:     snapshot.LastSource,
+	})
+}
@@ -0,0 +1,47 @@
+package currency
+
+import "net/http"
+
+// PauseCurrency godoc
+// @Summary Приостановить сбор цен валюты
+// @Description Переводит валюту в состояние paused: чекер цен ее пропускает, а история остается доступной для запросов цен, выгрузки и статистики.
+// @Description Закрывает текущий период отслеживания, автор берется из заголовка X-Actor.
+// @Tags currencies
+// @Produce json
+// @Param symbol path string true "Символ валюты" example(BTC)
+// @Success 200 {object} affarm_internal_service.CurrencySnapshot
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/pause [post]
+func (h *CurrencyHandler) PauseCurrency(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.currencies.Pause(r.Context(), r.PathValue("symbol"))
+	if err != nil {
+		serviceError(w, err)
+		return
+	}
+	jsonResponse(w, snapshot)
+}
+
+// ResumeCurrency godoc
+// @Summary Возобновить сбор цен валюты
+// @Description Возвращает приостановленную валюту в состояние active и открывает новый период отслеживания.
+// @Description Удаленная валюта возобновляется через POST /currency/add.
+// @Tags currencies
+// @Produce json
+// @Param symbol path string true "Символ валюты" example(BTC)
+// @Success 200 {object} affarm_internal_service.CurrencySnapshot
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/resume [post]
+func (h *CurrencyHandler) ResumeCurrency(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := h.currencies.Resume(r.Context(), r.PathValue("symbol"))
+	if err != nil {
+		serviceError(w, err)
+		return
+	}
+	jsonResponse(w, snapshot)
+}
@@ -1,152 +1,106 @@
 package currency
 
 import (
-	"database/sql"
+	services "affarm/internal/service"
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
-	"time"
+	"strconv"
 )
 
 // GetPriceRequest - структура запроса
 type GetPriceRequest struct {
-	Symbol    string    `json:"symbol" validate:"required,uppercase,max=10"`
-	Timestamp time.Time `json:"timestamp" validate:"required"`
+	Symbol      string    `json:"symbol" example:"BTC"`
+	Coin        string    `json:"coin,omitempty"`                                      // синоним symbol из исходного ТЗ
+	Timestamp   Timestamp `json:"timestamp" swaggertype:"string" example:"1736500490"` // Unix-секунды, миллисекунды или RFC3339
+	Interpolate bool      `json:"interpolate"`                                         // интерполировать между соседними точками вместо выбора ближайшей
+	BridgeGaps  *bool     `json:"bridge_gaps,omitempty"`                               // false - не брать точки из-за пробелов в отслеживании, по умолчанию true
 }
 
-// PriceResponse - структура ответа
-type PriceResponse struct {
-	Symbol string  `json:"symbol"`
-	Price  float64 `json:"price"`
+// options возвращает параметры поиска запроса
+func (req GetPriceRequest) options() services.LookupOptions {
+	return services.LookupOptions{Interpolate: req.Interpolate, NoBridgeGaps: req.BridgeGaps != nil && !*req.BridgeGaps}
 }
 
 // GetPriceAtTime godoc
 // @Summary Получить цену на момент времени
 // @Description Возвращает цену для указанной валютной пары на заданный момент времени. Если точное значение отсутствует, возвращает ближайшее доступное.
+// @Description В ответе указаны метки использованных точек, смещение от запрошенного момента, источник и качество ответа.
+// @Description Параметры передаются в query (symbol или coin, timestamp, interpolate) либо, для обратной совместимости, в JSON-теле.
+// @Description timestamp принимается в Unix-секундах, Unix-миллисекундах или RFC3339.
+// @Description bridge_gaps=false ограничивает поиск периодом отслеживания валюты: на момент, когда валюта не отслеживалась, вернется 404,
+// @Description а точки по другую сторону пробела в отслеживании не используются.
 // @Tags prices
 // @Accept json
 // @Produce json
-// @Param request body GetPriceRequest true "Параметры запроса"
-// @Success 200 {object} PriceResponse
+// @Param symbol query string false "Символ валюты" example(BTC)
+// @Param coin query string false "Синоним symbol"
+// @Param timestamp query string false "Момент времени" example(1736500490)
+// @Param interpolate query bool false "Интерполировать между соседними точками"
+// @Param bridge_gaps query bool false "Брать точки через пробелы в отслеживании" default(true)
+// @Param request body GetPriceRequest false "Параметры запроса (устаревший вариант)"
+// @Success 200 {object} affarm_internal_service.PriceResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /price/get [get]
+// @Router /currency/price [get]
 func (h *CurrencyHandler) GetPriceAtTime(w http.ResponseWriter, r *http.Request) {
-	var req GetPriceRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
-		return
-	}
-
-	if err := h.validate.Struct(req); err != nil {
+	req, err := parseGetPriceRequest(r)
+	if err != nil {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
 		return
 	}
 
-	// Получаем соединение с БД
-	db, err := h.db.DB()
+	resp, err := h.prices.At(r.Context(), req.Symbol, req.Timestamp.Time, req.options())
 	if err != nil {
-		log.Printf("Failed to get DB connection: %v", err)
-		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		serviceError(w, err)
 		return
 	}
+	jsonResponse(w, resp)
+}
 
-	// 1. Проверяем существование валюты
-	var currencyID uint
-	err = db.QueryRow("SELECT id FROM currencies WHERE symbol = $1 AND deleted_at IS NULL", req.Symbol).Scan(&currencyID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, `{"error": "Currency not found"}`, http.StatusNotFound)
-		} else {
-			log.Printf("Currency lookup error: %v", err)
-			http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+// parseGetPriceRequest читает параметры из query, а если их нет - из JSON-тела
+func parseGetPriceRequest(r *http.Request) (GetPriceRequest, error) {
+	var req GetPriceRequest
+	query := r.URL.Query()
+
+	if query.Has("symbol") || query.Has("coin") || query.Has("timestamp") {
+		req.Symbol = query.Get("symbol")
+		req.Coin = query.Get("coin")
+		if raw := query.Get("timestamp"); raw != "" {
+			t, err := ParseTimestamp(raw)
+			if err != nil {
+				return req, errors.New("Invalid timestamp")
+			}
+			req.Timestamp = Timestamp{Time: t}
 		}
-		return
-	}
-
-	utcTime := req.Timestamp.UTC()
-
-	// 2. Пытаемся найти точное совпадение (±1 секунда)
-	var exactPrice float64
-	var exactTimestamp time.Time
-	err = db.QueryRow(`
-        SELECT price, timestamp 
-        FROM prices 
-        WHERE currency_id = $1 
-        AND timestamp BETWEEN $2 AND $3
-        LIMIT 1`,
-		currencyID,
-		utcTime.Add(-time.Second),
-		utcTime.Add(time.Second),
-	).Scan(&exactPrice, &exactTimestamp)
-
-	if err == nil {
-		jsonResponse(w, PriceResponse{Symbol: req.Symbol, Price: exactPrice})
-		return
-	} else if err != sql.ErrNoRows {
-		log.Printf("Exact price query error: %v", err)
-		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
-		return
-	}
-
-	// 3. Ищем ближайшие цены
-	var beforePrice, afterPrice float64
-	var beforeTimestamp, afterTimestamp time.Time
-
-	// Ближайшая цена до
-	err = db.QueryRow(`
-        SELECT price, timestamp 
-        FROM prices 
-        WHERE currency_id = $1 
-        AND timestamp <= $2
-        ORDER BY timestamp DESC
-        LIMIT 1`,
-		currencyID,
-		utcTime,
-	).Scan(&beforePrice, &beforeTimestamp)
-
-	if err != nil && err != sql.ErrNoRows {
-		log.Printf("Before price query error: %v", err)
-		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
-		return
-	}
-
-	// Ближайшая цена после
-	err = db.QueryRow(`
-        SELECT price, timestamp 
-        FROM prices 
-        WHERE currency_id = $1 
-        AND timestamp >= $2
-        ORDER BY timestamp ASC
-        LIMIT 1`,
-		currencyID,
-		utcTime,
-	).Scan(&afterPrice, &afterTimestamp)
-
-	if err != nil && err != sql.ErrNoRows {
-		log.Printf("After price query error: %v", err)
-		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
-		return
+		if raw := query.Get("interpolate"); raw != "" {
+			interpolate, err := strconv.ParseBool(raw)
+			if err != nil {
+				return req, errors.New("Invalid interpolate flag")
+			}
+			req.Interpolate = interpolate
+		}
+		if raw := query.Get("bridge_gaps"); raw != "" {
+			bridgeGaps, err := strconv.ParseBool(raw)
+			if err != nil {
+				return req, errors.New("Invalid bridge_gaps flag")
+			}
+			req.BridgeGaps = &bridgeGaps
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if errors.Is(err, io.EOF) {
+			return req, errors.New("symbol and timestamp are required")
+		}
+		return req, errors.New("Invalid request body")
 	}
 
-	// 4. Выбираем результат
-	switch {
-	case beforeTimestamp.IsZero() && afterTimestamp.IsZero():
-		http.Error(w, `{"error": "No price data available for `+req.Symbol+`"}`, http.StatusNotFound)
-	case beforeTimestamp.IsZero():
-		jsonResponse(w, PriceResponse{Symbol: req.Symbol, Price: afterPrice})
-	case afterTimestamp.IsZero():
-		jsonResponse(w, PriceResponse{Symbol: req.Symbol, Price: beforePrice})
-	default:
-		beforeDiff := utcTime.Sub(beforeTimestamp)
-		afterDiff := afterTimestamp.Sub(utcTime)
-		if beforeDiff < afterDiff {
-			jsonResponse(w, PriceResponse{Symbol: req.Symbol, Price: beforePrice})
-		} else {
-			jsonResponse(w, PriceResponse{Symbol: req.Symbol, Price: afterPrice})
-		}
+	if req.Symbol == "" {
+		req.Symbol = req.Coin
 	}
+	return req, nil
 }
 
 func jsonResponse(w http.ResponseWriter, data interface{}) {
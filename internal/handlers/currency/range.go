@@ -0,0 +1,63 @@
+package currency
+
+import (
+	services "affarm/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PriceRangeResponse - сырые точки ряда цен за период
+type PriceRangeResponse struct {
+	Symbol string                `json:"symbol"`
+	From   time.Time             `json:"from"`
+	To     time.Time             `json:"to"`
+	Count  int                   `json:"count"`
+	Points []services.PricePoint `json:"points"`
+}
+
+// GetPriceRange godoc
+// @Summary Точки ряда цен за период
+// @Description Возвращает сохраненные точки ряда в диапазоне [from, to) по возрастанию времени, без пересемплирования.
+// @Description Ответ обрезается до limit точек; тот же запрос доступен в gRPC как GetPriceRange.
+// @Tags prices
+// @Produce json
+// @Param symbol path string true "Символ валюты" example(BTC)
+// @Param from query string true "Начало периода"
+// @Param to query string true "Конец периода (не включительно)"
+// @Param limit query int false "Максимум точек, по умолчанию и не больше 10000"
+// @Success 200 {object} PriceRangeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/prices [get]
+func (h *CurrencyHandler) GetPriceRange(w http.ResponseWriter, r *http.Request) {
+	symbol := r.PathValue("symbol")
+	query := r.URL.Query()
+
+	from, err := ParseTimestamp(query.Get("from"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid from timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	to, err := ParseTimestamp(query.Get("to"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid to timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	var limit int
+	if raw := query.Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 {
+			http.Error(w, `{"error": "Invalid limit"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	points, err := h.prices.Range(r.Context(), symbol, from, to, limit)
+	if err != nil {
+		serviceError(w, err)
+		return
+	}
+
+	jsonResponse(w, PriceRangeResponse{Symbol: symbol, From: from, To: to, Count: len(points), Points: points})
+}
@@ -1,7 +1,6 @@
 package currency
 
 import (
-	"affarm/internal/models"
 	"encoding/json"
 	"log"
 	"net/http"
@@ -10,7 +9,7 @@ import (
 // RemoveCurrencyRequest - структура запроса для удаления
 type RemoveCurrencyRequest struct {
 	ID     *uint   `json:"id,omitempty"`
-	Symbol *string `json:"symbol,omitempty" validate:"omitempty,uppercase,max=10"`
+	Symbol *string `json:"symbol,omitempty"`
 }
 
 // RemoveCurrency godoc
@@ -34,40 +33,21 @@ func (h *CurrencyHandler) RemoveCurrency(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Валидация
 	if req.ID == nil && req.Symbol == nil {
 		http.Error(w, `{"error": "Must provide either ID or Symbol"}`, http.StatusBadRequest)
 		return
 	}
 
-	if req.Symbol != nil {
-		if err := h.validate.Var(*req.Symbol, "uppercase,max=10"); err != nil {
-			log.Printf("Ошибка валидации: %v", err)
-			http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
-			return
-		}
-	}
-
-	// Удаление из БД
-	var currency models.Currency
-	query := h.db.Model(&models.Currency{})
-
+	var id uint
+	var symbol string
 	if req.ID != nil {
-		query = query.Where("id = ?", *req.ID)
+		id = *req.ID
 	} else {
-		query = query.Where("symbol = ?", *req.Symbol)
-	}
-
-	result := query.Delete(&currency)
-
-	if result.Error != nil {
-		log.Printf("Ошибка удаления: %v", result.Error)
-		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
-		return
+		symbol = *req.Symbol
 	}
 
-	if result.RowsAffected == 0 {
-		http.Error(w, `{"error": "Currency not found"}`, http.StatusNotFound)
+	if err := h.currencies.Remove(r.Context(), id, symbol); err != nil {
+		serviceError(w, err)
 		return
 	}
 
@@ -76,5 +56,4 @@ func (h *CurrencyHandler) RemoveCurrency(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": "Currency successfully deleted",
 	})
-	log.Printf("Валюта удалена: ID=%v, Symbol=%v", req.ID, req.Symbol)
 }
@@ -0,0 +1,211 @@
+package currency
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Ограничения запроса статистики
+const (
+	MaxStatsBuckets   = 10000
+	DefaultStatsSMA   = 20
+	MaxStatsSMAWindow = 1000
+)
+
+// StatsPoint - точка пересемплированного ряда
+type StatsPoint struct {
+	Timestamp    time.Time `json:"timestamp"`               // начало интервала
+	Close        float64   `json:"close"`                   // последняя цена в интервале
+	SimpleReturn *float64  `json:"simple_return,omitempty"` // пусто, если в предыдущем интервале не было цен
+	LogReturn    *float64  `json:"log_return,omitempty"`
+	SMA          *float64  `json:"sma,omitempty"` // пусто, пока не накоплено window точек
+	EMA          float64   `json:"ema"`
+	Drawdown     float64   `json:"drawdown"` // отношение к предыдущему максимуму минус 1
+}
+
+// StatsResponse - статистика по ряду цен за период
+type StatsResponse struct {
+	Symbol               string       `json:"symbol"`
+	From                 time.Time    `json:"from"`
+	To                   time.Time    `json:"to"`
+	Interval             string       `json:"interval"`
+	Window               int          `json:"window"`
+	Count                int          `json:"count"`
+	Min                  float64      `json:"min"`
+	Max                  float64      `json:"max"`
+	Mean                 float64      `json:"mean"`
+	TotalReturn          float64      `json:"total_return"`
+	Volatility           float64      `json:"volatility"`            // стандартное отклонение лог-доходностей соседних интервалов
+	AnnualizedVolatility float64      `json:"annualized_volatility"` // volatility * sqrt(интервалов в году)
+	MaxDrawdown          float64      `json:"max_drawdown"`
+	Points               []StatsPoint `json:"points"`
+}
+
+// statsSQL пересемплирует ряд на сетку date_bin и считает доходности, SMA, просадку
+// и агрегаты оконными функциями. %[1]d - размер окна SMA, %[2]d - он же минус один (проверенные целые).
+// Интервалы без цен в ряд не попадают, а доходность считается только от соседнего интервала:
+// иначе доходность через пропуск охватывала бы несколько интервалов и завышала волатильность.
+// Начало периода, уже свернутое retention, берется из сверток: точка свертки - close на момент last_at
+const statsSQL = `
+WITH raw AS (
+    SELECT timestamp, price::float8 AS price
+    FROM prices
+    WHERE currency_id = $1 AND timestamp >= $3 AND timestamp < $4
+), pts AS (
+    SELECT timestamp, price FROM raw
+    UNION ALL
+    SELECT last_at, close::float8
+    FROM price_aggregates
+    WHERE currency_id = $1 AND last_at >= $3 AND last_at < COALESCE((SELECT min(timestamp) FROM raw), $4)
+), buckets AS (
+    SELECT date_bin($2::interval, timestamp, $3) AS bucket,
+           (array_agg(price ORDER BY timestamp DESC))[1] AS close
+    FROM pts
+    GROUP BY 1
+), series AS (
+    SELECT bucket, close,
+           CASE WHEN lag(bucket) OVER w = bucket - $2::interval
+                THEN close / NULLIF(lag(close) OVER w, 0) - 1 END AS simple_return,
+           CASE WHEN lag(bucket) OVER w = bucket - $2::interval
+                THEN ln(close / NULLIF(lag(close) OVER w, 0)) END AS log_return,
+           CASE WHEN count(*) OVER sma_w = %[1]d THEN avg(close) OVER sma_w END AS sma,
+           close / NULLIF(max(close) OVER (ORDER BY bucket ROWS UNBOUNDED PRECEDING), 0) - 1 AS drawdown
+    FROM buckets
+    WINDOW w AS (ORDER BY bucket),
+           sma_w AS (ORDER BY bucket ROWS BETWEEN %[2]d PRECEDING AND CURRENT ROW)
+)
+SELECT bucket, close, simple_return, log_return, sma, drawdown,
+       min(close) OVER (), max(close) OVER (), avg(close) OVER (),
+       COALESCE(stddev_samp(log_return) OVER (), 0), min(drawdown) OVER ()
+FROM series
+ORDER BY bucket`
+
+// GetStats godoc
+// @Summary Статистика по ряду цен
+// @Description Пересемплирует ряд цен на сетку interval (последняя цена в интервале) и возвращает простые и логарифмические доходности
+// @Description между соседними интервалами (после интервала без цен доходность пустая),
+// @Description реализованную волатильность, максимальную просадку, min/max/mean и ряды SMA/EMA. Расчеты выполняются оконными функциями в БД, EMA - в сервисе.
+// @Tags prices
+// @Produce json
+// @Param symbol path string true "Символ валюты" example(BTC)
+// @Param from query string true "Начало периода"
+// @Param to query string true "Конец периода (не включительно)"
+// @Param interval query string false "Шаг пересемплирования (Go duration), по умолчанию 1h" example(1h)
+// @Param window query int false "Окно SMA/EMA в интервалах, по умолчанию 20"
+// @Success 200 {object} StatsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Router /currency/{symbol}/stats [get]
+func (h *CurrencyHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	if !h.requirePostgres(w) {
+		return
+	}
+
+	symbol := r.PathValue("symbol")
+	query := r.URL.Query()
+
+	from, err := ParseTimestamp(query.Get("from"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid from timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	to, err := ParseTimestamp(query.Get("to"))
+	if err != nil || !to.After(from) {
+		http.Error(w, `{"error": "Invalid to timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	interval := time.Hour
+	if raw := query.Get("interval"); raw != "" {
+		if interval, err = time.ParseDuration(raw); err != nil || interval < time.Second {
+			http.Error(w, `{"error": "Invalid interval"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	if to.Sub(from)/interval > MaxStatsBuckets {
+		http.Error(w, fmt.Sprintf(`{"error": "too many intervals, max %d"}`, MaxStatsBuckets), http.StatusBadRequest)
+		return
+	}
+	window := DefaultStatsSMA
+	if raw := query.Get("window"); raw != "" {
+		if window, err = strconv.Atoi(raw); err != nil || window < 1 || window > MaxStatsSMAWindow {
+			http.Error(w, `{"error": "Invalid window"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	db, currencyID, ok := h.analyticsCurrency(r.Context(), w, symbol)
+	if !ok {
+		return
+	}
+
+	rows, err := db.QueryContext(r.Context(), fmt.Sprintf(statsSQL, window, window-1),
+		currencyID, fmt.Sprintf("%d seconds", int64(interval/time.Second)), from, to)
+	if err != nil {
+		log.Printf("Stats query error: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	resp := StatsResponse{Symbol: symbol, From: from, To: to, Interval: interval.String(), Window: window, Points: []StatsPoint{}}
+	for rows.Next() {
+		var (
+			point                        StatsPoint
+			simpleReturn, logReturn, sma sql.NullFloat64
+		)
+		if err := rows.Scan(&point.Timestamp, &point.Close, &simpleReturn, &logReturn, &sma, &point.Drawdown,
+			&resp.Min, &resp.Max, &resp.Mean, &resp.Volatility, &resp.MaxDrawdown); err != nil {
+			log.Printf("Stats scan error: %v", err)
+			http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+			return
+		}
+		point.Timestamp = point.Timestamp.UTC()
+		point.SimpleReturn = nullableFloat(simpleReturn)
+		point.LogReturn = nullableFloat(logReturn)
+		point.SMA = nullableFloat(sma)
+		resp.Points = append(resp.Points, point)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Stats rows error: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if len(resp.Points) == 0 {
+		http.Error(w, `{"error": "No price data available for `+symbol+`"}`, http.StatusNotFound)
+		return
+	}
+
+	// EMA рекурсивна и не выражается оконными функциями, поэтому считается здесь
+	alpha := 2 / float64(window+1)
+	for i := range resp.Points {
+		if i == 0 {
+			resp.Points[i].EMA = resp.Points[i].Close
+			continue
+		}
+		resp.Points[i].EMA = alpha*resp.Points[i].Close + (1-alpha)*resp.Points[i-1].EMA
+	}
+
+	first, last := resp.Points[0].Close, resp.Points[len(resp.Points)-1].Close
+	if first != 0 {
+		resp.TotalReturn = last/first - 1
+	}
+	resp.Count = len(resp.Points)
+	resp.AnnualizedVolatility = resp.Volatility * math.Sqrt(float64(365*24*time.Hour)/float64(interval))
+
+	jsonResponse(w, resp)
+}
+
+func nullableFloat(v sql.NullFloat64) *float64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Float64
+}
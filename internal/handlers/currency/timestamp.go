@@ -0,0 +1,74 @@
+package currency
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unixMillisThreshold - числа по модулю не меньше этого значения считаются миллисекундами.
+// 1e12 секунд - это 33658 год, а 1e12 миллисекунд - 2001 год.
+const unixMillisThreshold = 1e12
+
+// Timestamp - момент времени, который принимается в виде Unix-секунд, Unix-миллисекунд
+// или строки RFC3339 (формат определяется автоматически)
+type Timestamp struct {
+	time.Time
+}
+
+// ParseTimestamp разбирает Unix-секунды, Unix-миллисекунды или RFC3339 и возвращает время в UTC
+func ParseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("пустая метка времени")
+	}
+
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		return fromUnixNumber(n)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("метка времени %q не является Unix-временем или RFC3339", value)
+	}
+	return t.UTC(), nil
+}
+
+func fromUnixNumber(n float64) (time.Time, error) {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return time.Time{}, fmt.Errorf("некорректная метка времени")
+	}
+	if math.Abs(n) >= unixMillisThreshold {
+		return time.UnixMilli(int64(n)).UTC(), nil
+	}
+	sec, frac := math.Modf(n)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC(), nil
+}
+
+// UnmarshalJSON принимает как число, так и строку
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var raw string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	} else {
+		raw = string(data)
+	}
+
+	parsed, err := ParseTimestamp(raw)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
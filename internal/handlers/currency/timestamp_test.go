@@ -0,0 +1,98 @@
+package currency
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2025-01-10T09:00:00Z", want: time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)},
+		{value: "2025-01-10T12:00:00.5+03:00", want: time.Date(2025, 1, 10, 9, 0, 0, 5e8, time.UTC)},
+		{value: " 1736499600 ", want: time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)},
+		{value: "1736499600.25", want: time.Date(2025, 1, 10, 9, 0, 0, 25e7, time.UTC)},
+		{value: "1736499600000", want: time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)},
+		// По разные стороны unixMillisThreshold: последняя секунда и первая миллисекунда
+		{value: "999999999999", want: time.Unix(999999999999, 0).UTC()},
+		{value: "1000000000000", want: time.UnixMilli(1e12).UTC()},
+		// Отрицательные значения - время до 1970 года, порог действует по модулю
+		{value: "-1", want: time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{value: "-1.5", want: time.Date(1969, 12, 31, 23, 59, 58, 5e8, time.UTC)},
+		{value: "-1000000000000", want: time.UnixMilli(-1e12).UTC()},
+		{value: "", wantErr: true},
+		{value: "   ", wantErr: true},
+		{value: "NaN", wantErr: true},
+		{value: "Inf", wantErr: true},
+		{value: "2025-01-10", wantErr: true},
+		{value: "yesterday", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimestamp(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseTimestamp(%q) = %s, ожидалась ошибка", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTimestamp(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("ParseTimestamp(%q) = %s, ожидалось %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	want := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	for _, raw := range []string{`1736499600`, `1736499600000`, `"1736499600"`, `"2025-01-10T09:00:00Z"`} {
+		var ts Timestamp
+		if err := ts.UnmarshalJSON([]byte(raw)); err != nil || !ts.Equal(want) {
+			t.Errorf("UnmarshalJSON(%s) = %s (%v), ожидалось %s", raw, ts.Time, err, want)
+		}
+	}
+	var ts Timestamp
+	if err := ts.UnmarshalJSON([]byte(`null`)); err != nil || !ts.IsZero() {
+		t.Errorf("UnmarshalJSON(null) = %s (%v), ожидалось пустое время", ts.Time, err)
+	}
+}
+
+// TestParseGetPriceRequestPrecedence проверяет, что параметры запроса имеют приоритет над телом:
+// если в строке запроса есть symbol, coin или timestamp, тело не читается
+func TestParseGetPriceRequestPrecedence(t *testing.T) {
+	body := `{"symbol": "ETH", "timestamp": "2024-06-01T00:00:00Z", "interpolate": true}`
+
+	r := httptest.NewRequest("POST", "/api/v1/currency/price?coin=BTC&timestamp=1736499600000", strings.NewReader(body))
+	req, err := parseGetPriceRequest(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Symbol != "BTC" || !req.Timestamp.Equal(time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)) || req.Interpolate {
+		t.Fatalf("из строки запроса разобрано %+v, ожидалось BTC на 2025-01-10T09:00:00Z без тела", req)
+	}
+
+	r = httptest.NewRequest("POST", "/api/v1/currency/price", strings.NewReader(body))
+	if req, err = parseGetPriceRequest(r); err != nil {
+		t.Fatal(err)
+	}
+	if req.Symbol != "ETH" || !req.Timestamp.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) || !req.Interpolate {
+		t.Fatalf("из тела разобрано %+v", req)
+	}
+
+	r = httptest.NewRequest("POST", "/api/v1/currency/price?symbol=BTC&timestamp=soon", strings.NewReader(body))
+	if _, err = parseGetPriceRequest(r); err == nil {
+		t.Fatal("неверный timestamp в строке запроса должен давать ошибку, а не брать время из тела")
+	}
+
+	r = httptest.NewRequest("POST", "/api/v1/currency/price", strings.NewReader(""))
+	if _, err = parseGetPriceRequest(r); err == nil || err.Error() != "symbol and timestamp are required" {
+		t.Fatalf("пустое тело: %v", err)
+	}
+}
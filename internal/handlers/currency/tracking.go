@@ -0,0 +1,25 @@
+package currency
+
+import "net/http"
+
+// GetTrackingHistory godoc
+// @Summary История отслеживания валюты
+// @Description Возвращает периоды, когда валюта отслеживалась: кто и когда включил и выключил сбор цен.
+// @Description Автор берется из заголовка X-Actor запроса, изменившего валюту; у загрузки истории из командной строки это cli, у миграции - migration.
+// @Description Удаленная валюта тоже возвращается, с tracked=false.
+// @Tags currencies
+// @Produce json
+// @Param symbol path string true "Символ валюты" example(BTC)
+// @Success 200 {object} affarm_internal_service.TrackingHistory
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/tracking [get]
+func (h *CurrencyHandler) GetTrackingHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := h.currencies.TrackingHistory(r.Context(), r.PathValue("symbol"))
+	if err != nil {
+		serviceError(w, err)
+		return
+	}
+	jsonResponse(w, history)
+}
@@ -0,0 +1,257 @@
+package portfolio
+
+import (
+	"affarm/internal/models"
+	"encoding/json"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"net/http"
+)
+
+// CreatePortfolioRequest - структура запроса создания портфеля
+type CreatePortfolioRequest struct {
+	Name      string        `json:"name" validate:"required,max=64"`
+	Positions []PositionDTO `json:"positions" validate:"dive"`
+}
+
+// CreatePortfolio godoc
+// @Summary Создать портфель
+// @Description Создает портфель с начальными позициями
+// @Tags portfolios
+// @Accept json
+// @Produce json
+// @Param request body CreatePortfolioRequest true "Данные портфеля"
+// @Success 201 {object} PortfolioResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolios [post]
+func (h *PortfolioHandler) CreatePortfolio(w http.ResponseWriter, r *http.Request) {
+	var req CreatePortfolioRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	portfolio := models.Portfolio{Name: req.Name}
+	seen := make(map[string]bool, len(req.Positions))
+	for _, position := range req.Positions {
+		if seen[position.Symbol] {
+			http.Error(w, `{"error": "Duplicate position `+position.Symbol+`"}`, http.StatusBadRequest)
+			return
+		}
+		seen[position.Symbol] = true
+		portfolio.Positions = append(portfolio.Positions, models.Position{Symbol: position.Symbol, Quantity: position.Quantity})
+	}
+
+	var existing int64
+	if err := h.db.Model(&models.Portfolio{}).Where("name = ?", req.Name).Count(&existing).Error; err != nil {
+		log.Printf("ошибка проверки портфеля: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+	if existing > 0 {
+		http.Error(w, `{"error": "Portfolio already exists"}`, http.StatusConflict)
+		return
+	}
+
+	if err := h.db.Create(&portfolio).Error; err != nil {
+		log.Printf("ошибка сохранения портфеля: %v", err)
+		http.Error(w, `{"error": "Failed to save portfolio"}`, http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, http.StatusCreated, newPortfolioResponse(portfolio))
+	log.Printf("Создан портфель %s", portfolio.Name)
+}
+
+// ListPortfolios godoc
+// @Summary Список портфелей
+// @Tags portfolios
+// @Produce json
+// @Success 200 {array} PortfolioResponse
+// @Failure 500 {object} map[string]string
+// @Router /portfolios [get]
+func (h *PortfolioHandler) ListPortfolios(w http.ResponseWriter, r *http.Request) {
+	var portfolios []models.Portfolio
+	err := h.db.Preload("Positions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("symbol")
+	}).Order("name").Find(&portfolios).Error
+	if err != nil {
+		log.Printf("ошибка загрузки портфелей: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]PortfolioResponse, 0, len(portfolios))
+	for _, portfolio := range portfolios {
+		resp = append(resp, newPortfolioResponse(portfolio))
+	}
+	jsonResponse(w, http.StatusOK, resp)
+}
+
+// GetPortfolio godoc
+// @Summary Получить портфель
+// @Tags portfolios
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Success 200 {object} PortfolioResponse
+// @Failure 404 {object} map[string]string
+// @Router /portfolios/{id} [get]
+func (h *PortfolioHandler) GetPortfolio(w http.ResponseWriter, r *http.Request) {
+	portfolio, ok := h.loadPortfolio(w, r)
+	if !ok {
+		return
+	}
+	jsonResponse(w, http.StatusOK, newPortfolioResponse(portfolio))
+}
+
+// DeletePortfolio godoc
+// @Summary Удалить портфель
+// @Description Удаляет портфель вместе с позициями и историей транзакций
+// @Tags portfolios
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolios/{id} [delete]
+func (h *PortfolioHandler) DeletePortfolio(w http.ResponseWriter, r *http.Request) {
+	portfolio, ok := h.loadPortfolio(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("portfolio_id = ?", portfolio.ID).Delete(&models.Transaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("portfolio_id = ?", portfolio.ID).Delete(&models.Position{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&portfolio).Error
+	})
+	if err != nil {
+		log.Printf("ошибка удаления портфеля: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, http.StatusOK, map[string]string{"message": "Portfolio successfully deleted"})
+	log.Printf("Удален портфель %s", portfolio.Name)
+}
+
+// SetPosition godoc
+// @Summary Установить позицию
+// @Description Устанавливает текущее количество валюты в портфеле без записи транзакции
+// @Tags portfolios
+// @Accept json
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Param request body PositionDTO true "Позиция"
+// @Success 200 {object} PortfolioResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolios/{id}/positions [put]
+func (h *PortfolioHandler) SetPosition(w http.ResponseWriter, r *http.Request) {
+	portfolio, ok := h.loadPortfolio(w, r)
+	if !ok {
+		return
+	}
+
+	var req PositionDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	position := models.Position{PortfolioID: portfolio.ID, Symbol: req.Symbol, Quantity: req.Quantity}
+	err := h.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "portfolio_id"}, {Name: "symbol"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+	}).Create(&position).Error
+	if err != nil {
+		log.Printf("ошибка сохранения позиции: %v", err)
+		http.Error(w, `{"error": "Failed to save position"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if portfolio, ok = h.loadPortfolio(w, r); ok {
+		jsonResponse(w, http.StatusOK, newPortfolioResponse(portfolio))
+	}
+}
+
+// AddTransaction godoc
+// @Summary Добавить транзакцию
+// @Description Записывает изменение позиции в историю и применяет его к текущей позиции
+// @Tags portfolios
+// @Accept json
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Param request body TransactionDTO true "Транзакция"
+// @Success 201 {object} TransactionDTO
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolios/{id}/transactions [post]
+func (h *PortfolioHandler) AddTransaction(w http.ResponseWriter, r *http.Request) {
+	portfolio, ok := h.loadPortfolio(w, r)
+	if !ok {
+		return
+	}
+
+	var req TransactionDTO
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+	if req.Quantity.IsZero() {
+		http.Error(w, `{"error": "quantity must not be zero"}`, http.StatusBadRequest)
+		return
+	}
+
+	record := models.Transaction{
+		PortfolioID: portfolio.ID,
+		Symbol:      req.Symbol,
+		Quantity:    req.Quantity,
+		ExecutedAt:  req.ExecutedAt.UTC(),
+		Note:        req.Note,
+	}
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		// Транзакция сразу применяется к текущей позиции
+		position := models.Position{PortfolioID: portfolio.ID, Symbol: req.Symbol, Quantity: req.Quantity}
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "portfolio_id"}, {Name: "symbol"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"quantity":   gorm.Expr("positions.quantity + EXCLUDED.quantity"),
+				"updated_at": gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}).Create(&position).Error
+	})
+	if err != nil {
+		log.Printf("ошибка сохранения транзакции: %v", err)
+		http.Error(w, `{"error": "Failed to save transaction"}`, http.StatusInternalServerError)
+		return
+	}
+
+	req.ID = record.ID
+	req.ExecutedAt = record.ExecutedAt
+	jsonResponse(w, http.StatusCreated, req)
+}
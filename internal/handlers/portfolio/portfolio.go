@@ -0,0 +1,119 @@
+package portfolio
+
+import (
+	"affarm/internal/models"
+	services "affarm/internal/service"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// PortfolioHandler - обработчик HTTP-запросов для работы с портфелями
+type PortfolioHandler struct {
+	db       *gorm.DB
+	prices   *services.PriceService
+	quote    string // опорная валюта, в которой считается стоимость портфеля
+	validate *validator.Validate
+}
+
+// NewPortfolioHandler - конструктор обработчика
+func NewPortfolioHandler(db *gorm.DB, prices *services.PriceService, quote string) *PortfolioHandler {
+	return &PortfolioHandler{db: db,
+		prices:   prices,
+		quote:    quote,
+		validate: validator.New()}
+}
+
+// PositionDTO - позиция портфеля в запросах и ответах
+type PositionDTO struct {
+	Symbol   string          `json:"symbol" validate:"required,uppercase,max=10"`
+	Quantity decimal.Decimal `json:"quantity" swaggertype:"string" example:"2.5"`
+}
+
+// TransactionDTO - транзакция портфеля в запросах и ответах
+type TransactionDTO struct {
+	ID         uint            `json:"id,omitempty"`
+	Symbol     string          `json:"symbol" validate:"required,uppercase,max=10"`
+	Quantity   decimal.Decimal `json:"quantity" swaggertype:"string" example:"-0.5"`
+	ExecutedAt time.Time       `json:"executed_at" validate:"required"`
+	Note       string          `json:"note,omitempty" validate:"max=255"`
+}
+
+// PortfolioResponse - портфель с текущими позициями
+type PortfolioResponse struct {
+	ID        uint          `json:"id"`
+	Name      string        `json:"name"`
+	CreatedAt time.Time     `json:"created_at"`
+	Positions []PositionDTO `json:"positions"`
+}
+
+func newPortfolioResponse(p models.Portfolio) PortfolioResponse {
+	resp := PortfolioResponse{ID: p.ID, Name: p.Name, CreatedAt: p.CreatedAt, Positions: make([]PositionDTO, 0, len(p.Positions))}
+	for _, position := range p.Positions {
+		resp.Positions = append(resp.Positions, PositionDTO{Symbol: position.Symbol, Quantity: position.Quantity})
+	}
+	return resp
+}
+
+// holdingsAt восстанавливает позиции на момент at: из текущих позиций вычитаются
+// транзакции, совершенные позже at. Нулевые позиции не возвращаются
+func holdingsAt(positions []models.Position, transactions []models.Transaction, at time.Time) []PositionDTO {
+	quantities := make(map[string]decimal.Decimal, len(positions))
+	for _, position := range positions {
+		quantities[position.Symbol] = quantities[position.Symbol].Add(position.Quantity)
+	}
+	for _, tx := range transactions {
+		if tx.ExecutedAt.After(at) {
+			quantities[tx.Symbol] = quantities[tx.Symbol].Sub(tx.Quantity)
+		}
+	}
+
+	holdings := make([]PositionDTO, 0, len(quantities))
+	for symbol, quantity := range quantities {
+		if !quantity.IsZero() {
+			holdings = append(holdings, PositionDTO{Symbol: symbol, Quantity: quantity})
+		}
+	}
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].Symbol < holdings[j].Symbol })
+	return holdings
+}
+
+// loadPortfolio загружает портфель с позициями по id из пути запроса.
+// При ошибке сам пишет ответ и возвращает false
+func (h *PortfolioHandler) loadPortfolio(w http.ResponseWriter, r *http.Request) (models.Portfolio, bool) {
+	var portfolio models.Portfolio
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error": "Invalid portfolio id"}`, http.StatusBadRequest)
+		return portfolio, false
+	}
+
+	err = h.db.Preload("Positions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("symbol")
+	}).First(&portfolio, id).Error
+	if err == gorm.ErrRecordNotFound {
+		http.Error(w, `{"error": "Portfolio not found"}`, http.StatusNotFound)
+		return portfolio, false
+	}
+	if err != nil {
+		log.Printf("ошибка загрузки портфеля: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return portfolio, false
+	}
+	return portfolio, true
+}
+
+func jsonResponse(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
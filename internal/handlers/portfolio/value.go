@@ -0,0 +1,241 @@
+package portfolio
+
+import (
+	"affarm/internal/handlers/currency"
+	"affarm/internal/models"
+	services "affarm/internal/service"
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"log"
+	"net/http"
+	"time"
+)
+
+// MaxHistoryPoints - максимальное количество точек в ряду стоимости портфеля
+const MaxHistoryPoints = 1000
+
+// HoldingValue - стоимость одной позиции. Если цену найти не удалось,
+// price и value пустые, а причина записана в error
+type HoldingValue struct {
+	Symbol         string           `json:"symbol"`
+	Quantity       decimal.Decimal  `json:"quantity" swaggertype:"string"`
+	Price          *decimal.Decimal `json:"price,omitempty" swaggertype:"string"`
+	Value          *decimal.Decimal `json:"value,omitempty" swaggertype:"string"`
+	PriceTimestamp *time.Time       `json:"price_timestamp,omitempty"`
+	Quality        string           `json:"quality,omitempty" enums:"exact,nearest,stale"`
+	Error          string           `json:"error,omitempty"`
+}
+
+// ValuationResponse - стоимость портфеля на момент времени.
+// Total включает только позиции с найденной ценой; complete=false означает, что есть позиции из missing
+type ValuationResponse struct {
+	PortfolioID uint            `json:"portfolio_id"`
+	At          time.Time       `json:"at"`
+	Currency    string          `json:"currency"`
+	Total       decimal.Decimal `json:"total" swaggertype:"string"`
+	Complete    bool            `json:"complete"`
+	Missing     []string        `json:"missing"`
+	Holdings    []HoldingValue  `json:"holdings"`
+}
+
+// HistoryPoint - точка ряда стоимости портфеля
+type HistoryPoint struct {
+	At       time.Time       `json:"at"`
+	Total    decimal.Decimal `json:"total" swaggertype:"string"`
+	Complete bool            `json:"complete"`
+	Missing  []string        `json:"missing"`
+}
+
+// HistoryResponse - ряд стоимости портфеля на сетке моментов времени
+type HistoryResponse struct {
+	PortfolioID uint           `json:"portfolio_id"`
+	Currency    string         `json:"currency"`
+	From        time.Time      `json:"from"`
+	To          time.Time      `json:"to"`
+	Step        string         `json:"step"`
+	Points      []HistoryPoint `json:"points"`
+}
+
+// GetValue godoc
+// @Summary Стоимость портфеля на момент времени
+// @Description Считает стоимость позиций портфеля на момент at по сохраненным ценам в опорной валюте.
+// @Description Позиции без цены не оцениваются нулем, а перечисляются в missing.
+// @Tags portfolios
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Param at query string false "Момент времени (Unix-секунды, миллисекунды или RFC3339), по умолчанию - текущий"
+// @Success 200 {object} ValuationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolios/{id}/value [get]
+func (h *PortfolioHandler) GetValue(w http.ResponseWriter, r *http.Request) {
+	portfolio, ok := h.loadPortfolio(w, r)
+	if !ok {
+		return
+	}
+
+	at := time.Now().UTC()
+	if raw := r.URL.Query().Get("at"); raw != "" {
+		var err error
+		if at, err = currency.ParseTimestamp(raw); err != nil {
+			http.Error(w, `{"error": "Invalid at timestamp"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	valuations, err := h.valuate(r.Context(), portfolio, []time.Time{at})
+	if err != nil {
+		log.Printf("ошибка оценки портфеля: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, http.StatusOK, valuations[0])
+}
+
+// GetHistory godoc
+// @Summary Ряд стоимости портфеля
+// @Description Считает стоимость портфеля на сетке from, from+step, ..., to с учетом истории транзакций
+// @Tags portfolios
+// @Produce json
+// @Param id path int true "ID портфеля"
+// @Param from query string true "Начало диапазона"
+// @Param to query string true "Конец диапазона"
+// @Param step query string false "Шаг сетки (Go duration), по умолчанию 1h" example(1h)
+// @Success 200 {object} HistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /portfolios/{id}/history [get]
+func (h *PortfolioHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	portfolio, ok := h.loadPortfolio(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := currency.ParseTimestamp(query.Get("from"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid from timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	to, err := currency.ParseTimestamp(query.Get("to"))
+	if err != nil || to.Before(from) {
+		http.Error(w, `{"error": "Invalid to timestamp"}`, http.StatusBadRequest)
+		return
+	}
+	step := time.Hour
+	if raw := query.Get("step"); raw != "" {
+		if step, err = time.ParseDuration(raw); err != nil || step <= 0 {
+			http.Error(w, `{"error": "Invalid step"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if to.Sub(from)/step+1 > MaxHistoryPoints {
+		http.Error(w, fmt.Sprintf(`{"error": "too many points, max %d"}`, MaxHistoryPoints), http.StatusBadRequest)
+		return
+	}
+	var moments []time.Time
+	for t := from; !t.After(to); t = t.Add(step) {
+		moments = append(moments, t)
+	}
+
+	valuations, err := h.valuate(r.Context(), portfolio, moments)
+	if err != nil {
+		log.Printf("ошибка оценки портфеля: %v", err)
+		http.Error(w, `{"error": "Database error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	resp := HistoryResponse{PortfolioID: portfolio.ID, Currency: h.quote, From: from, To: to, Step: step.String()}
+	for _, valuation := range valuations {
+		resp.Points = append(resp.Points, HistoryPoint{
+			At:       valuation.At,
+			Total:    valuation.Total,
+			Complete: valuation.Complete,
+			Missing:  valuation.Missing,
+		})
+	}
+	jsonResponse(w, http.StatusOK, resp)
+}
+
+// valuate оценивает портфель на каждый из моментов. Цены всех позиций
+// на все моменты ищутся одним пакетным запросом, как в /prices/lookup
+func (h *PortfolioHandler) valuate(ctx context.Context, portfolio models.Portfolio, moments []time.Time) ([]ValuationResponse, error) {
+	var transactions []models.Transaction
+	if err := h.db.WithContext(ctx).
+		Where("portfolio_id = ? AND executed_at > ?", portfolio.ID, moments[0]).
+		Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("ошибка загрузки транзакций: %w", err)
+	}
+
+	holdings := make([][]PositionDTO, len(moments))
+	var queries []services.PriceQuery
+	for i, at := range moments {
+		holdings[i] = holdingsAt(portfolio.Positions, transactions, at)
+		for _, holding := range holdings[i] {
+			if holding.Symbol != h.quote {
+				queries = append(queries, services.PriceQuery{Symbol: holding.Symbol, At: at})
+			}
+		}
+	}
+	if len(queries) > services.MaxLookupItems {
+		return nil, fmt.Errorf("слишком много запросов цен: %d", len(queries))
+	}
+
+	lookups, err := h.prices.Lookup(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ValuationResponse, len(moments))
+	next := 0
+	for i, at := range moments {
+		valuation := ValuationResponse{
+			PortfolioID: portfolio.ID,
+			At:          at,
+			Currency:    h.quote,
+			Total:       decimal.Zero,
+			Complete:    true,
+			Missing:     []string{},
+			Holdings:    make([]HoldingValue, 0, len(holdings[i])),
+		}
+
+		for _, holding := range holdings[i] {
+			item := HoldingValue{Symbol: holding.Symbol, Quantity: holding.Quantity}
+
+			if holding.Symbol == h.quote {
+				price, stamp := decimal.NewFromInt(1), at
+				item.Price, item.PriceTimestamp, item.Quality = &price, &stamp, services.QualityExact
+			} else {
+				lookup := lookups[next]
+				next++
+				resp, ok := lookup.Resolve(holding.Symbol, at, false)
+				switch {
+				case !lookup.CurrencyFound:
+					item.Error = "Currency not found"
+				case !ok:
+					item.Error = "No price data available"
+				default:
+					price, stamp := resp.Points[0].Decimal, resp.Timestamp
+					item.Price, item.PriceTimestamp, item.Quality = &price, &stamp, resp.Quality
+				}
+			}
+
+			if item.Price == nil {
+				valuation.Complete = false
+				valuation.Missing = append(valuation.Missing, holding.Symbol)
+			} else {
+				value := holding.Quantity.Mul(*item.Price)
+				item.Value = &value
+				valuation.Total = valuation.Total.Add(value)
+			}
+			valuation.Holdings = append(valuation.Holdings, item)
+		}
+		result[i] = valuation
+	}
+	return result, nil
+}
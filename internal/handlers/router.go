@@ -1,32 +1,119 @@
 package handlers
 
 import (
+	"affarm/config"
 	_ "affarm/docs"
+	"affarm/internal/handlers/admin"
+	"affarm/internal/handlers/alerts"
 	"affarm/internal/handlers/currency"
+	"affarm/internal/handlers/portfolio"
+	"affarm/internal/handlers/stream"
+	"affarm/internal/repository"
+	services "affarm/internal/service"
 	"github.com/swaggo/http-swagger"
 	"gorm.io/gorm"
 	"log"
 	"net/http"
+	"strings"
 )
 
-func NewRouter(db *gorm.DB) *http.ServeMux {
+// route - маршрут API и его обработчик
+type route struct {
+	pattern string
+	handler http.HandlerFunc
+}
+
+func NewRouter(db *gorm.DB, svc *services.Services, cfg *config.Config) *http.ServeMux {
 	mux := http.NewServeMux()
 
-	currencyHandler := currency.NewCurrencyHandler(db)
+	currencyHandler := currency.NewCurrencyHandler(db, svc, cfg.Convertation, cfg.Storage.Driver)
+	portfolioHandler := portfolio.NewPortfolioHandler(db, svc.Prices, cfg.Convertation)
+	adminHandler := admin.NewAdminHandler(db, svc, cfg.Admin)
+	streamHandler := stream.NewStreamHandler(svc.Hub, cfg.Stream.AllowedOrigins)
 
-	// Регистрация маршрутов API v1
-	mux.HandleFunc("POST /api/v1/currency/add", currencyHandler.AddCurrency)
-	mux.HandleFunc("POST /api/v1/currency/remove", currencyHandler.RemoveCurrency)
-	mux.HandleFunc("GET /api/v1/currency/price", currencyHandler.GetPriceAtTime)
-	mux.HandleFunc("GET /swagger/", httpSwagger.WrapHandler)
+	// Регистрация маршрутов API v1.
+	// Маршруты логируются из той же таблицы, чтобы лог не расходился с реальными путями
+	routes := []route{
+		{"POST /api/v1/currency/add", currencyHandler.AddCurrency},
+		{"POST /api/v1/currency/remove", currencyHandler.RemoveCurrency},
+		{"GET /api/v1/currency/price", currencyHandler.GetPriceAtTime},
+		{"POST /api/v1/prices/lookup", currencyHandler.LookupPricesBatch},
+		{"GET /api/v1/currencies", currencyHandler.ListCurrencies},
+		{"GET /api/v1/currency/{symbol}/latest", currencyHandler.GetLatestPrice},
+		{"GET /api/v1/currency/{symbol}/prices", currencyHandler.GetPriceRange},
+		{"GET /api/v1/currency/{symbol}/stats", currencyHandler.GetStats},
+		{"GET /api/v1/currency/{symbol}/average", currencyHandler.GetAverage},
+		{"GET /api/v1/currency/{symbol}/tracking", currencyHandler.GetTrackingHistory},
+		{"POST /api/v1/currency/{symbol}/pause", currencyHandler.PauseCurrency},
+		{"POST /api/v1/currency/{symbol}/resume", currencyHandler.ResumeCurrency},
+		{"GET /api/v1/export/prices", currencyHandler.ExportPrices},
+		{"GET /api/v1/convert", currencyHandler.Convert},
+		{"GET /api/v1/correlation", currencyHandler.GetCorrelation},
+		{"POST /api/v1/portfolios", portfolioHandler.CreatePortfolio},
+		{"GET /api/v1/portfolios", portfolioHandler.ListPortfolios},
+		{"GET /api/v1/portfolios/{id}", portfolioHandler.GetPortfolio},
+		{"DELETE /api/v1/portfolios/{id}", portfolioHandler.DeletePortfolio},
+		{"PUT /api/v1/portfolios/{id}/positions", portfolioHandler.SetPosition},
+		{"POST /api/v1/portfolios/{id}/transactions", portfolioHandler.AddTransaction},
+		{"GET /api/v1/portfolios/{id}/value", portfolioHandler.GetValue},
+		{"GET /api/v1/portfolios/{id}/history", portfolioHandler.GetHistory},
+		{"GET /api/v1/stream/prices", streamHandler.StreamSSE},
+		{"GET /api/v1/stream/prices/ws", streamHandler.StreamWebSocket},
+		{"GET /swagger/", httpSwagger.WrapHandler},
+	}
 
-	log.Print("POST /api/v1/currency/add")
-	log.Print("POST /api/v1/currency/remove")
-	log.Print("GET /api/v1/currency/{symbol}")
-	log.Print("GET API /swagger/")
+	// Правила алертов ссылаются на валюты и цены в db, а в хранилище memory их там нет
+	if cfg.Storage.Driver == "memory" {
+		log.Print("Алерты /api/v1/alerts выключены: не поддерживаются с storage.driver memory")
+	} else {
+		alertHandler := alerts.NewAlertHandler(db, svc.Alerts)
+		routes = append(routes,
+			route{"POST /api/v1/alerts", alertHandler.CreateAlert},
+			route{"GET /api/v1/alerts", alertHandler.ListAlerts},
+			route{"GET /api/v1/alerts/{id}", alertHandler.GetAlert},
+			route{"PUT /api/v1/alerts/{id}", alertHandler.UpdateAlert},
+			route{"DELETE /api/v1/alerts/{id}", alertHandler.DeleteAlert},
+			route{"GET /api/v1/alerts/{id}/deliveries", alertHandler.ListDeliveries},
+		)
+	}
+
+	// Служебные методы доступны только с токеном; в хранилище memory загрузка в бд не видна сервисам
+	switch {
+	case cfg.Admin.Token == "":
+		log.Print("Служебные методы /api/v1/admin выключены: не задан admin.token")
+	case cfg.Storage.Driver == "memory":
+		log.Print("Служебные методы /api/v1/admin выключены: не поддерживаются с storage.driver memory")
+	default:
+		routes = append(routes,
+			route{"POST /api/v1/admin/import/prices", adminHandler.RequireToken(adminHandler.ImportPrices)},
+			route{"POST /api/v1/admin/currencies/{symbol}/purge", adminHandler.RequireToken(adminHandler.PurgeCurrency)},
+			route{"GET /api/v1/admin/purges", adminHandler.RequireToken(adminHandler.ListPurges)},
+		)
+	}
+	for _, route := range routes {
+		mux.HandleFunc(route.pattern, withActor(route.handler))
+		log.Print(route.pattern)
+	}
 
 	// Статические файлы (опционально)
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static"))))
 
 	return mux
 }
+
+// ActorHeader - заголовок с автором изменения для истории отслеживания валют
+const ActorHeader = "X-Actor"
+
+// actorHTTP - автор изменений из HTTP API без заголовка X-Actor
+const actorHTTP = "http"
+
+// withActor передает автора запроса из заголовка X-Actor в контекст
+func withActor(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		actor := r.Header.Get(ActorHeader)
+		if strings.TrimSpace(actor) == "" {
+			actor = actorHTTP
+		}
+		next(w, r.WithContext(repository.WithActor(r.Context(), actor)))
+	}
+}
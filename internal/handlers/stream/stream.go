@@ -0,0 +1,235 @@
+package stream
+
+import (
+	services "affarm/internal/service"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Таймауты потоковых соединений
+const (
+	HeartbeatInterval = 15 * time.Second
+	writeTimeout      = 10 * time.Second
+)
+
+// StreamHandler - обработчик потоковой выдачи новых цен
+type StreamHandler struct {
+	hub      *services.PriceHub
+	upgrader websocket.Upgrader
+}
+
+// NewStreamHandler - конструктор обработчика. allowedOrigins - источники страниц других сайтов,
+// которым разрешен WebSocket; остальные сайты получают 403, чтобы чужая страница не открывала поток от имени пользователя
+func NewStreamHandler(hub *services.PriceHub, allowedOrigins []string) *StreamHandler {
+	return &StreamHandler{hub: hub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			CheckOrigin:     checkOrigin(allowedOrigins),
+		}}
+}
+
+// checkOrigin допускает запросы без Origin, с того же хоста и из allowed. Без списка - проверка gorilla по умолчанию
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return nil
+	}
+	origins := make(map[string]bool, len(allowed))
+	for _, origin := range allowed {
+		origins[strings.ToLower(strings.TrimRight(origin, "/"))] = true
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" || origins[strings.ToLower(origin)] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+// PriceMessage - сообщение о новой цене в потоке
+type PriceMessage struct {
+	ID uint64 `json:"id"`
+	services.PriceEvent
+}
+
+// parseSubscription читает фильтр валют и позицию продолжения из запроса
+func parseSubscription(r *http.Request) ([]string, uint64, error) {
+	var symbols []string
+	for _, symbol := range strings.Split(r.URL.Query().Get("symbols"), ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, strings.ToUpper(symbol))
+		}
+	}
+
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	var lastEventID uint64
+	if raw != "" {
+		var err error
+		if lastEventID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			return nil, 0, fmt.Errorf("invalid Last-Event-ID")
+		}
+	}
+	return symbols, lastEventID, nil
+}
+
+// StreamSSE godoc
+// @Summary Поток новых цен (Server-Sent Events)
+// @Description Отправляет каждую новую сохраненную цену событием price. Поддерживает фильтр по валютам,
+// @Description продолжение с заголовка Last-Event-ID из короткого буфера и heartbeat-комментарии.
+// @Description Клиент, не успевающий читать события, отключается.
+// @Tags stream
+// @Produce text/event-stream
+// @Param symbols query string false "Символы через запятую, по умолчанию все" example(BTC,ETH)
+// @Param Last-Event-ID header string false "ID последнего полученного события"
+// @Success 200 {object} PriceMessage
+// @Failure 400 {object} map[string]string
+// @Router /stream/prices [get]
+func (h *StreamHandler) StreamSSE(w http.ResponseWriter, r *http.Request) {
+	symbols, lastEventID, err := parseSubscription(r)
+	if err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, `{"error": "Streaming unsupported"}`, http.StatusInternalServerError)
+		return
+	}
+
+	sub, complete := h.hub.Subscribe(symbols, lastEventID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	if !complete {
+		// Часть событий после Last-Event-ID уже вытеснена из буфера
+		fmt.Fprint(w, "event: gap\ndata: {}\n\n")
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped {
+					fmt.Fprint(w, "event: error\ndata: {\"error\": \"slow consumer\"}\n\n")
+					flusher.Flush()
+					log.Printf("SSE-клиент %s отключен: не успевает читать события", r.RemoteAddr)
+				}
+				return
+			}
+			data, _ := json.Marshal(PriceMessage{ID: event.ID, PriceEvent: event.Price})
+			controller.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: price\ndata: %s\n\n", event.ID, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			controller.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// StreamWebSocket godoc
+// @Summary Поток новых цен (WebSocket)
+// @Description То же, что /stream/prices, но через WebSocket: каждое сообщение - JSON PriceMessage.
+// @Description Продолжение - параметром last_event_id, heartbeat - ping-кадрами.
+// @Description Страницы других сайтов получают 403, если их источника нет в stream.allowed_origins.
+// @Tags stream
+// @Param symbols query string false "Символы через запятую, по умолчанию все" example(BTC,ETH)
+// @Param last_event_id query int false "ID последнего полученного события"
+// @Success 101 {object} PriceMessage
+// @Failure 400 {object} map[string]string
+// @Router /stream/prices/ws [get]
+func (h *StreamHandler) StreamWebSocket(w http.ResponseWriter, r *http.Request) {
+	symbols, lastEventID, err := parseSubscription(r)
+	if err != nil {
+		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("ошибка установки WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	sub, complete := h.hub.Subscribe(symbols, lastEventID)
+	defer sub.Close()
+
+	// Чтение нужно, чтобы обрабатывать pong и закрытие соединения клиентом
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(2 * HeartbeatInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * HeartbeatInterval))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if !complete {
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		conn.WriteJSON(map[string]string{"event": "gap"})
+	}
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped {
+					conn.WriteControl(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "slow consumer"),
+						time.Now().Add(writeTimeout))
+					log.Printf("WebSocket-клиент %s отключен: не успевает читать события", r.RemoteAddr)
+				}
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteJSON(PriceMessage{ID: event.ID, PriceEvent: event.Price}); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Package importer загружает историю цен из файлов CSV и NDJSON других систем.
+// В PostgreSQL строки идут через COPY во временную таблицу и переносятся в prices одним запросом,
+// в SQLite (для разработки) вставляются по одной
+package importer
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/pricefile"
+	"affarm/internal/repository"
+	services "affarm/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// SourceImport - источник точек по умолчанию, если в файле и в Options он не указан
+const SourceImport = "import"
+
+// MaxReportedErrors - сколько отклоненных строк перечисляется в отчете; счетчик Rejected учитывает все
+const MaxReportedErrors = 100
+
+// maxPriceDigits - знаков до запятой в колонке prices.price decimal(20,8)
+const maxPriceDigits = 12
+
+// Options - параметры загрузки
+type Options struct {
+	Format string // pricefile.FormatCSV или FormatNDJSON
+	Source string // источник для строк без колонки source
+	Actor  string // автор периодов отслеживания новых валют, по умолчанию из контекста (repository.Actor)
+}
+
+// LineError - отклоненная строка файла
+type LineError struct {
+	Line  int64  `json:"line"`
+	Error string `json:"error"`
+}
+
+// Report - итог загрузки файла
+type Report struct {
+	Lines             int64    `json:"lines"`              // строк с данными в файле
+	Accepted          int64    `json:"accepted"`           // добавлено новых точек
+	Duplicates        int64    `json:"duplicates"`         // точки на уже занятый момент времени (в бд или выше в файле)
+	Rejected          int64    `json:"rejected"`           // строки с ошибками, не загружены
+	CreatedCurrencies []string `json:"created_currencies"` // валюты, которых не было в бд
+	Symbols           []string `json:"symbols"`            // валюты, в ряды которых добавлены точки
+	// ExtendedTracking - существующие валюты, для точек которых вне периодов отслеживания добавлены периоды
+	ExtendedTracking []string    `json:"extended_tracking"`
+	Errors           []LineError `json:"errors"` // первые MaxReportedErrors отклоненных строк
+}
+
+func (r *Report) reject(line int64, err error) {
+	r.Rejected++
+	if len(r.Errors) < MaxReportedErrors {
+		r.Errors = append(r.Errors, LineError{Line: line, Error: err.Error()})
+	}
+}
+
+// record - проверенная строка файла
+type record struct {
+	line   int64
+	symbol string
+	at     time.Time
+	price  decimal.Decimal
+	source string
+	volume *float64
+}
+
+// Import загружает файл из r в prices. Недостающие валюты создаются приостановленными, чтобы чекер цен
+// не начал их опрашивать, с закрытым периодом отслеживания от первой до последней загруженной точки;
+// точки на уже занятые моменты времени пропускаются, строки архивных валют отклоняются: их история только для чтения. Для точек существующих валют вне их периодов
+// отслеживания добавляются закрытые периоды от автора загрузки, иначе поиск цены без перехода через
+// пробелы эти точки бы не видел. Ошибка возвращается только при сбое чтения файла или бд; неверные строки попадают в отчет
+func Import(ctx context.Context, db *gorm.DB, r io.Reader, opts Options) (Report, error) {
+	report := Report{CreatedCurrencies: []string{}, Symbols: []string{}, ExtendedTracking: []string{}, Errors: []LineError{}}
+	if opts.Format != pricefile.FormatCSV && opts.Format != pricefile.FormatNDJSON {
+		return report, fmt.Errorf("неизвестный формат загрузки %q, ожидается csv или ndjson", opts.Format)
+	}
+	if opts.Source == "" {
+		opts.Source = SourceImport
+	}
+	if opts.Actor == "" {
+		opts.Actor = repository.Actor(ctx)
+	}
+
+	var err error
+	if db.Dialector.Name() == "postgres" {
+		err = importPostgres(ctx, db, r, opts, &report)
+	} else {
+		err = importGorm(ctx, db, r, opts, &report)
+	}
+	return report, err
+}
+
+// parse читает файл и передает fn проверенные строки, отклоненные записывает в отчет
+func parse(r io.Reader, opts Options, report *Report, fn func(record) error) error {
+	return pricefile.ReadLines(opts.Format, r, func(line int64, row pricefile.Row, lineErr error) error {
+		report.Lines++
+		if lineErr != nil {
+			report.reject(line, lineErr)
+			return nil
+		}
+		rec, err := validate(line, row, opts.Source)
+		if err != nil {
+			report.reject(line, err)
+			return nil
+		}
+		return fn(rec)
+	})
+}
+
+// validate проверяет строку по ограничениям колонок prices и currencies
+func validate(line int64, row pricefile.Row, defaultSource string) (record, error) {
+	rec := record{line: line, symbol: strings.ToUpper(strings.TrimSpace(row.Symbol)), at: row.Timestamp.UTC(), source: row.Source, volume: row.Volume}
+	if err := services.ValidateSymbol(rec.symbol); err != nil {
+		return rec, err
+	}
+	if rec.at.IsZero() {
+		return rec, fmt.Errorf("timestamp is required")
+	}
+
+	price, err := decimal.NewFromString(strings.TrimSpace(row.Price))
+	if err != nil {
+		return rec, fmt.Errorf("invalid price %q", row.Price)
+	}
+	if !price.IsPositive() {
+		return rec, fmt.Errorf("price must be positive, got %s", price)
+	}
+	if price.Truncate(0).NumDigits() > maxPriceDigits {
+		return rec, fmt.Errorf("price %s is out of range", price)
+	}
+	rec.price = price.Round(8)
+
+	if rec.source == "" {
+		rec.source = defaultSource
+	}
+	if utf8.RuneCountInString(rec.source) > 32 {
+		return rec, fmt.Errorf("source %q is longer than 32 characters", rec.source)
+	}
+	if rec.volume != nil && *rec.volume < 0 {
+		return rec, fmt.Errorf("volume must not be negative")
+	}
+	return rec, nil
+}
+
+// gapKey - пробел в отслеживании валюты, определяется началом следующего периода (нулевое - следующего нет)
+type gapKey struct {
+	currencyID uint
+	next       time.Time
+}
+
+// gapSpan - загруженные точки, попавшие в один пробел отслеживания или в ряд новой валюты
+type gapSpan struct {
+	symbol   string
+	from, to time.Time
+}
+
+// extend расширяет промежуток до момента at
+func (s gapSpan) extend(at time.Time) gapSpan {
+	if at.Before(s.from) {
+		s.from = at
+	}
+	if at.After(s.to) {
+		s.to = at
+	}
+	return s
+}
+
+// uncoveredGap возвращает пробел, в который попадает момент at, или false, если at входит в период
+func uncoveredGap(currencyID uint, periods []models.TrackingPeriod, at time.Time) (gapKey, bool) {
+	key := gapKey{currencyID: currencyID}
+	for _, period := range periods {
+		if period.Covers(at) {
+			return key, false
+		}
+		if period.StartedAt.After(at) && (key.next.IsZero() || period.StartedAt.Before(key.next)) {
+			key.next = period.StartedAt
+		}
+	}
+	return key, true
+}
+
+// gapPeriod - закрытый период, покрывающий точки пробела: до начала следующего периода
+// или, если его нет, сразу после последней точки
+func gapPeriod(key gapKey, span gapSpan, actor string) models.TrackingPeriod {
+	stopped := key.next
+	if stopped.IsZero() {
+		stopped = span.to.Add(time.Microsecond)
+	}
+	return models.TrackingPeriod{CurrencyID: key.currencyID, StartedAt: span.from, StartedBy: actor, StoppedAt: &stopped, StoppedBy: actor}
+}
+
+func archivedError(symbol string) error {
+	return fmt.Errorf("валюта %s в архиве, ее история только для чтения", symbol)
+}
+
+// importGorm вставляет строки по одной в одной транзакции. Подходит для небольших баз разработки
+func importGorm(ctx context.Context, db *gorm.DB, r io.Reader, opts Options, report *Report) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := make(map[string]uint)
+		created := make(map[uint]gapSpan)                 // точки новых валют - их период отслеживания
+		periods := make(map[uint][]models.TrackingPeriod) // периоды существующих валют
+		gaps := make(map[gapKey]gapSpan)                  // точки существующих валют вне периодов
+		archived := make(map[string]bool)
+		updated := make(map[string]bool)
+		err := parse(r, opts, report, func(rec record) error {
+			if archived[rec.symbol] {
+				report.reject(rec.line, archivedError(rec.symbol))
+				return nil
+			}
+			id, ok := ids[rec.symbol]
+			if !ok {
+				var currency models.Currency
+				err := tx.Unscoped().Where("symbol = ?", rec.symbol).First(&currency).Error
+				if err == nil && currency.DeletedAt.Valid {
+					archived[rec.symbol] = true
+					report.reject(rec.line, archivedError(rec.symbol))
+					return nil
+				}
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					currency = models.Currency{Symbol: rec.symbol, Status: models.CurrencyPaused}
+					if err = tx.Create(&currency).Error; err == nil {
+						report.CreatedCurrencies = append(report.CreatedCurrencies, rec.symbol)
+						created[currency.ID] = gapSpan{symbol: rec.symbol, from: rec.at, to: rec.at}
+					}
+				} else if err == nil {
+					var existing []models.TrackingPeriod
+					err = tx.Where("currency_id = ?", currency.ID).Find(&existing).Error
+					periods[currency.ID] = existing
+				}
+				if err != nil {
+					return fmt.Errorf("ошибка создания валюты %s: %w", rec.symbol, err)
+				}
+				id, ids[rec.symbol] = currency.ID, currency.ID
+			}
+			if span, ok := created[id]; ok {
+				created[id] = span.extend(rec.at)
+			}
+			if existing, ok := periods[id]; ok {
+				if key, uncovered := uncoveredGap(id, existing, rec.at); uncovered {
+					span, seen := gaps[key]
+					if !seen {
+						span = gapSpan{symbol: rec.symbol, from: rec.at, to: rec.at}
+					}
+					gaps[key] = span.extend(rec.at)
+				}
+			}
+
+			price := models.Price{
+				CurrencyID: id,
+				Price:      rec.price.InexactFloat64(),
+				Timestamp:  rec.at,
+				Source:     rec.source,
+				Volume:     rec.volume,
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&price)
+			if result.Error != nil {
+				return fmt.Errorf("ошибка сохранения цены: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				report.Duplicates++
+			} else {
+				report.Accepted++
+				updated[rec.symbol] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for symbol := range updated {
+			report.Symbols = append(report.Symbols, symbol)
+		}
+		slices.Sort(report.Symbols)
+		for id, span := range created {
+			period := gapPeriod(gapKey{currencyID: id}, span, opts.Actor)
+			if err := tx.Create(&period).Error; err != nil {
+				return fmt.Errorf("ошибка записи истории отслеживания: %w", err)
+			}
+		}
+		extended := make(map[string]bool)
+		for key, span := range gaps {
+			period := gapPeriod(key, span, opts.Actor)
+			if err := tx.Create(&period).Error; err != nil {
+				return fmt.Errorf("ошибка записи истории отслеживания: %w", err)
+			}
+			if !extended[span.symbol] {
+				extended[span.symbol] = true
+				report.ExtendedTracking = append(report.ExtendedTracking, span.symbol)
+			}
+		}
+		slices.Sort(report.ExtendedTracking)
+		return nil
+	})
+}
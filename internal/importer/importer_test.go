@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"affarm/internal/database"
+	"affarm/internal/models"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestImportCreatesPausedCurrency проверяет, что валюта из файла не начинает опрашиваться чекером:
+// она приостановлена, а ее период отслеживания закрыт сразу после последней загруженной точки
+func TestImportCreatesPausedCurrency(t *testing.T) {
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "import.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	file := "symbol,timestamp,price\n" +
+		"NEW,2025-01-10T10:00:00Z,2\n" +
+		"NEW,2025-01-10T09:00:00Z,1\n" +
+		"NEW,2025-01-10T11:00:00Z,3\n"
+	report, err := Import(context.Background(), db, strings.NewReader(file), Options{Format: "csv", Actor: "tester"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Accepted != 3 || len(report.CreatedCurrencies) != 1 || len(report.Symbols) != 1 || report.Symbols[0] != "NEW" {
+		t.Fatalf("отчет %+v", report)
+	}
+
+	var currency models.Currency
+	if err := db.Where("symbol = ?", "NEW").First(&currency).Error; err != nil {
+		t.Fatal(err)
+	}
+	if currency.Status != models.CurrencyPaused {
+		t.Fatalf("новая валюта в состоянии %q, ожидалось paused", currency.Status)
+	}
+	var periods []models.TrackingPeriod
+	if err := db.Where("currency_id = ?", currency.ID).Find(&periods).Error; err != nil {
+		t.Fatal(err)
+	}
+	first := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	last := time.Date(2025, 1, 10, 11, 0, 0, 0, time.UTC)
+	if len(periods) != 1 || !periods[0].StartedAt.Equal(first) || periods[0].StoppedAt == nil ||
+		!periods[0].StoppedAt.Equal(last.Add(time.Microsecond)) || periods[0].StoppedBy != "tester" {
+		t.Fatalf("периоды отслеживания %+v, ожидался один закрытый от %s до последней точки", periods, first)
+	}
+	if !periods[0].Covers(last) {
+		t.Fatalf("период не покрывает последнюю загруженную точку %s", last)
+	}
+}
@@ -0,0 +1,259 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+	"io"
+	"strconv"
+	"time"
+)
+
+// stagingSQL создает временную таблицу сеанса для COPY. Прежняя таблица удаляется на случай,
+// если прошлая загрузка в этом соединении оборвалась до очистки
+const stagingSQL = `
+DROP TABLE IF EXISTS pg_temp.price_import;
+CREATE TEMP TABLE price_import (
+    line        bigint NOT NULL,
+    symbol      varchar(10) NOT NULL,
+    "timestamp" timestamptz NOT NULL,
+    price       numeric(20,8) NOT NULL,
+    source      varchar(32) NOT NULL,
+    volume      numeric(30,8)
+)`
+
+// rejectArchivedSQL убирает из таблицы загрузки строки архивных валют: их история только для чтения
+const rejectArchivedSQL = `
+WITH rejected AS (
+    DELETE FROM price_import i
+    USING currencies c
+    WHERE c.symbol = i.symbol AND c.deleted_at IS NOT NULL
+    RETURNING i.line, i.symbol
+)
+SELECT line, symbol FROM rejected ORDER BY line`
+
+// createCurrenciesSQL добавляет валюты, которых нет в бд, в том числе среди удаленных. Новые валюты
+// приостановлены, чтобы чекер цен не начал их опрашивать, а их закрытый период отслеживания покрывает
+// загруженные точки, чтобы история не считалась пробелом (как gapPeriod); $1 - автор
+const createCurrenciesSQL = `
+WITH created AS (
+    INSERT INTO currencies (symbol, status, created_at, updated_at)
+    SELECT DISTINCT symbol, 'paused', now(), now() FROM price_import
+    ON CONFLICT (symbol) DO NOTHING
+    RETURNING id, symbol
+), periods AS (
+    INSERT INTO tracking_periods (currency_id, started_at, started_by, stopped_at, stopped_by)
+    SELECT c.id, min(i."timestamp"), $1, max(i."timestamp") + interval '1 microsecond', $1
+    FROM created c
+    JOIN price_import i ON i.symbol = c.symbol
+    GROUP BY c.id
+)
+SELECT symbol FROM created ORDER BY symbol`
+
+// extendTrackingSQL добавляет закрытые периоды отслеживания для загруженных точек существующих валют,
+// не попавших ни в один период: по периоду на каждый пробел, до начала следующего периода
+// или сразу после последней точки пробела (как gapPeriod). $1 - автор
+const extendTrackingSQL = `
+WITH uncovered AS (
+    SELECT c.id AS currency_id, c.symbol, i."timestamp",
+           (SELECT min(t.started_at) FROM tracking_periods t
+            WHERE t.currency_id = c.id AND t.started_at > i."timestamp") AS next_start
+    FROM price_import i
+    JOIN currencies c ON c.symbol = i.symbol
+    WHERE NOT EXISTS (
+        SELECT 1 FROM tracking_periods t
+        WHERE t.currency_id = c.id AND t.started_at <= i."timestamp"
+          AND (t.stopped_at IS NULL OR t.stopped_at > i."timestamp")
+    )
+), inserted AS (
+    INSERT INTO tracking_periods (currency_id, started_at, started_by, stopped_at, stopped_by)
+    SELECT currency_id, min("timestamp"), $1, COALESCE(next_start, max("timestamp") + interval '1 microsecond'), $1
+    FROM uncovered
+    GROUP BY currency_id, next_start
+)
+SELECT DISTINCT symbol FROM uncovered ORDER BY symbol`
+
+// mergePricesSQL переносит строки в prices. Из повторов внутри файла остается первая строка,
+// точки на уже занятые моменты времени пропускаются. Возвращает число добавленных точек по валютам
+const mergePricesSQL = `
+WITH merged AS (
+    INSERT INTO prices (created_at, updated_at, currency_id, price, "timestamp", source, volume)
+    SELECT now(), now(), c.id, i.price, i."timestamp", i.source, i.volume
+    FROM (
+        SELECT DISTINCT ON (symbol, "timestamp") *
+        FROM price_import
+        ORDER BY symbol, "timestamp", line
+    ) i
+    JOIN currencies c ON c.symbol = i.symbol
+    ON CONFLICT (currency_id, "timestamp") DO NOTHING
+    RETURNING currency_id
+)
+SELECT c.symbol, count(*) FROM merged m JOIN currencies c ON c.id = m.currency_id
+GROUP BY c.symbol ORDER BY c.symbol`
+
+var stagingColumns = []string{"line", "symbol", "timestamp", "price", "source", "volume"}
+
+// importPostgres загружает файл через COPY на отдельном соединении пула: временная таблица
+// живет в сеансе, а COPY доступен только через pgx напрямую
+func importPostgres(ctx context.Context, db *gorm.DB, r io.Reader, opts Options, report *Report) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к бд: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgConn.Exec(ctx, stagingSQL); err != nil {
+			return fmt.Errorf("ошибка создания таблицы загрузки: %w", err)
+		}
+		defer pgConn.Exec(context.Background(), "DROP TABLE IF EXISTS pg_temp.price_import")
+
+		if err := copyRecords(ctx, pgConn, r, opts, report); err != nil {
+			return err
+		}
+		return merge(ctx, pgConn, opts, report)
+	})
+}
+
+// copyRecords передает проверенные строки в COPY по мере чтения файла: файл разбирается
+// в отдельной горутине, COPY забирает строки из канала
+func copyRecords(ctx context.Context, conn *pgx.Conn, r io.Reader, opts Options, report *Report) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	records := make(chan record, 1024)
+	parsed := make(chan error, 1)
+	go func() {
+		defer close(records)
+		parsed <- parse(r, opts, report, func(rec record) error {
+			select {
+			case records <- rec:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	_, copyErr := conn.CopyFrom(ctx, pgx.Identifier{"price_import"}, stagingColumns, pgx.CopyFromFunc(func() ([]any, error) {
+		rec, ok := <-records
+		if !ok {
+			return nil, nil
+		}
+		return copyRow(rec)
+	}))
+	// Горутина разбора завершается и при ошибке COPY: ее запись в канал прерывает cancel
+	cancel()
+	for range records {
+	}
+	if err := <-parsed; err != nil && copyErr == nil {
+		return fmt.Errorf("ошибка чтения файла: %w", err)
+	}
+	if copyErr != nil {
+		return fmt.Errorf("ошибка загрузки строк в бд: %w", copyErr)
+	}
+	return nil
+}
+
+func copyRow(rec record) ([]any, error) {
+	var price pgtype.Numeric
+	if err := price.Scan(rec.price.String()); err != nil {
+		return nil, err
+	}
+	var volume pgtype.Numeric
+	if rec.volume != nil {
+		if err := volume.Scan(strconv.FormatFloat(*rec.volume, 'f', -1, 64)); err != nil {
+			return nil, err
+		}
+	}
+	return []any{rec.line, rec.symbol, rec.at, price, rec.source, volume}, nil
+}
+
+// merge создает секции prices на период файла, затем в одной транзакции добавляет валюты,
+// периоды отслеживания и точки
+func merge(ctx context.Context, conn *pgx.Conn, opts Options, report *Report) error {
+	if err := rejectArchived(ctx, conn, report); err != nil {
+		return err
+	}
+
+	var staged int64
+	var from, to *time.Time
+	err := conn.QueryRow(ctx, `SELECT count(*), min("timestamp"), max("timestamp") FROM price_import`).Scan(&staged, &from, &to)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения таблицы загрузки: %w", err)
+	}
+	if staged == 0 {
+		return nil
+	}
+
+	// Секции создаются до транзакции, чтобы не держать блокировку prices на время переноса
+	if _, err := conn.Exec(ctx, "SELECT prices_ensure_partitions($1, $2)", *from, to.Add(time.Microsecond)); err != nil {
+		return fmt.Errorf("ошибка создания секций prices: %w", err)
+	}
+
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, createCurrenciesSQL, opts.Actor)
+		if err != nil {
+			return fmt.Errorf("ошибка создания валют: %w", err)
+		}
+		created, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("ошибка создания валют: %w", err)
+		}
+		report.CreatedCurrencies = append(report.CreatedCurrencies, created...)
+
+		// Точки новых валют уже покрыты их периодом, пробелы бывают только у существующих
+		rows, err = tx.Query(ctx, extendTrackingSQL, opts.Actor)
+		if err != nil {
+			return fmt.Errorf("ошибка записи истории отслеживания: %w", err)
+		}
+		extended, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("ошибка записи истории отслеживания: %w", err)
+		}
+		report.ExtendedTracking = append(report.ExtendedTracking, extended...)
+
+		rows, err = tx.Query(ctx, mergePricesSQL)
+		if err != nil {
+			return fmt.Errorf("ошибка переноса цен: %w", err)
+		}
+		var symbol string
+		var accepted int64
+		_, err = pgx.ForEachRow(rows, []any{&symbol, &accepted}, func() error {
+			report.Symbols = append(report.Symbols, symbol)
+			report.Accepted += accepted
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("ошибка переноса цен: %w", err)
+		}
+		report.Duplicates = staged - report.Accepted
+		return nil
+	})
+}
+
+// rejectArchived отклоняет строки архивных валют до переноса, отчет получает их номера строк
+func rejectArchived(ctx context.Context, conn *pgx.Conn, report *Report) error {
+	rows, err := conn.Query(ctx, rejectArchivedSQL)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки архивных валют: %w", err)
+	}
+	var line int64
+	var symbol string
+	_, err = pgx.ForEachRow(rows, []any{&line, &symbol}, func() error {
+		report.reject(line, archivedError(symbol))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка проверки архивных валют: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Типы правил алертов
+const (
+	AlertAbove  = "above"  // цена пересекла уровень снизу вверх
+	AlertBelow  = "below"  // цена пересекла уровень сверху вниз
+	AlertChange = "change" // цена изменилась больше чем на Threshold процентов за WindowSec
+)
+
+// Статусы доставки уведомлений
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// AlertRule - правило алерта по валюте
+type AlertRule struct {
+	gorm.Model      `swaggerignore:"true"`
+	CurrencyID      uint     `gorm:"index"`
+	Currency        Currency `gorm:"foreignKey:CurrencyID"`
+	Kind            string   `gorm:"size:16"`
+	Threshold       float64  `gorm:"type:decimal(20,8)"` // уровень цены или процент изменения
+	WindowSec       int      // окно для правила change
+	CooldownSec     int      // минимальная пауза между срабатываниями
+	Webhook         string   `gorm:"size:64"` // имя вебхука из конфига, пусто - все вебхуки
+	Enabled         bool     `gorm:"default:true"`
+	LastPrice       *float64 `gorm:"type:decimal(20,8)"` // предыдущая цена для определения пересечения; в паузе после срабатывания - только по другую сторону уровня
+	LastTriggeredAt *time.Time
+}
+
+// AlertDelivery - журнал доставки уведомления на вебхук
+type AlertDelivery struct {
+	gorm.Model    `swaggerignore:"true"`
+	AlertRuleID   uint   `gorm:"index"`
+	Webhook       string `gorm:"size:64"`
+	Payload       string `gorm:"type:text"`
+	Status        string `gorm:"size:16;index"`
+	Attempts      int
+	ResponseCode  int
+	LastError     string `gorm:"type:text"`
+	NextAttemptAt time.Time
+	DeliveredAt   *time.Time
+}
@@ -4,12 +4,20 @@ import (
 	"gorm.io/gorm"
 )
 
+// Состояния отслеживания валюты
+const (
+	CurrencyActive   = "active"   // цены собираются
+	CurrencyPaused   = "paused"   // сбор цен приостановлен, история доступна
+	CurrencyArchived = "archived" // валюта удалена из отслеживания (DeletedAt), история доступна только для чтения
+)
+
 // Currency represents a currency model
 // @Description Currency entity
 // @Success 200 {object} CurrencyResponse
 type Currency struct {
 	gorm.Model `swaggerignore:"true"`
 	Symbol     string         `gorm:"uniqueIndex;size:10"`
+	Status     string         `gorm:"size:16;not null;default:active;index"`
 	DeletedAt  gorm.DeletedAt `swaggerignore:"true"`
 	Prices     []Price        `swaggerignore:"true"` // Связь один-ко-многим
 }
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Топики событий outbox
+const (
+	TopicPriceCreated = "price.created"
+)
+
+// OutboxEvent - событие, записанное в одной транзакции с изменением данных.
+// ID служит порядковым номером события для получателей
+type OutboxEvent struct {
+	ID        uint64    `gorm:"primaryKey"`
+	Topic     string    `gorm:"size:64"`
+	Payload   string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// OutboxCursor - номер последнего события, подтвержденного получателем
+type OutboxCursor struct {
+	Sink      string `gorm:"primaryKey;size:64"`
+	LastSeq   uint64
+	UpdatedAt time.Time
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Portfolio - портфель с набором позиций
+type Portfolio struct {
+	gorm.Model   `swaggerignore:"true"`
+	Name         string        `gorm:"uniqueIndex;size:64"`
+	Positions    []Position    `swaggerignore:"true"` // Связь один-ко-многим
+	Transactions []Transaction `swaggerignore:"true"` // Связь один-ко-многим
+}
+
+// Position - текущее количество валюты в портфеле
+type Position struct {
+	gorm.Model  `swaggerignore:"true"`
+	PortfolioID uint            `gorm:"uniqueIndex:idx_position_portfolio_symbol"`
+	Symbol      string          `gorm:"uniqueIndex:idx_position_portfolio_symbol;size:10"`
+	Quantity    decimal.Decimal `gorm:"type:numeric(38,18)"`
+}
+
+// Transaction - изменение позиции портфеля в момент времени.
+// Quantity со знаком: покупка положительная, продажа отрицательная
+type Transaction struct {
+	gorm.Model  `swaggerignore:"true"`
+	PortfolioID uint            `gorm:"index"`
+	Symbol      string          `gorm:"size:10"`
+	Quantity    decimal.Decimal `gorm:"type:numeric(38,18)"`
+	ExecutedAt  time.Time       `gorm:"index"`
+	Note        string          `gorm:"size:255"`
+}
@@ -5,12 +5,52 @@ import (
 	"time"
 )
 
-// Price - Модель цены с временной меткой для валюты
+// PriceSourceBinance - источник цен по умолчанию (публичное API binance)
+const PriceSourceBinance = "binance"
+
+// Price - Модель цены с временной меткой для валюты.
+// Индекс (currency_id, timestamp) уникален и служит поиску ближайших точек ряда
 type Price struct {
 	gorm.Model `swaggerignore:"true"`
-	Price      float64 `gorm:"type:decimal(20,8)"`
-	Timestamp  time.Time
+	Price      float64   `gorm:"type:decimal(20,8)"`
+	Timestamp  time.Time `gorm:"uniqueIndex:idx_prices_currency_timestamp,priority:2"`
+	Source     string    `gorm:"size:32;default:binance"` // откуда получена цена
+	Volume     *float64  `gorm:"type:decimal(30,8)"`      // объем торгов за точку, если источник его передает
 	// FK
-	CurrencyID uint     // Внешний ключ (обязательное поле)
-	Currency   Currency `gorm:"foreignKey:CurrencyID"` // Явное указание связи
+	CurrencyID uint     `gorm:"uniqueIndex:idx_prices_currency_timestamp,priority:1"` // Внешний ключ; в ряду одна точка на момент времени
+	Currency   Currency `gorm:"foreignKey:CurrencyID"`                                // Явное указание связи
+}
+
+// PriceSourceDownsampled - источник точек, взятых из сверток старых цен (PriceAggregate)
+const PriceSourceDownsampled = "downsampled"
+
+// PriceAggregate - свертка точек ряда за интервал ResolutionSec, начинающийся в Bucket.
+// Сырые точки старше срока хранения заменяются свертками, которые затем укрупняются
+type PriceAggregate struct {
+	CurrencyID    uint      `gorm:"primaryKey;autoIncrement:false;index:idx_price_aggregates_currency_last_at,priority:1"`
+	ResolutionSec int       `gorm:"primaryKey;autoIncrement:false"`
+	Bucket        time.Time `gorm:"primaryKey"`
+	Open          float64   `gorm:"type:decimal(20,8)"`
+	High          float64   `gorm:"type:decimal(20,8)"`
+	Low           float64   `gorm:"type:decimal(20,8)"`
+	Close         float64   `gorm:"type:decimal(20,8)"`
+	Volume        *float64  `gorm:"type:decimal(30,8)"`
+	Points        int64     // сколько сырых точек свернуто
+	FirstAt       time.Time // метка первой свернутой точки, к ней относится Open
+	LastAt        time.Time `gorm:"index:idx_price_aggregates_currency_last_at,priority:2"` // метка последней точки, к ней относится Close
+	Currency      Currency  `gorm:"foreignKey:CurrencyID"`
+}
+
+// PriceArchive - запись манифеста архива: месячная секция prices, выгруженная в файл холодного хранилища
+type PriceArchive struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	Partition  string    `gorm:"size:64;uniqueIndex" json:"partition"` // имя секции, например prices_2025_01
+	PeriodFrom time.Time `gorm:"index" json:"period_from"`
+	PeriodTo   time.Time `json:"period_to"` // конец периода, не включительно
+	Format     string    `gorm:"size:16" json:"format"`
+	Key        string    `gorm:"size:255" json:"key"` // путь файла в хранилище
+	Rows       int64     `json:"rows"`
+	Bytes      int64     `json:"bytes"`
+	SHA256     string    `gorm:"column:sha256;size:64" json:"sha256"`
+	CreatedAt  time.Time `json:"created_at"`
 }
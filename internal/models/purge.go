@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Итоги безвозвратного удаления валюты
+const (
+	PurgeRunning   = "running"
+	PurgeCompleted = "completed"
+	PurgeFailed    = "failed" // удаление прервано, часть строк могла остаться; повторный запуск продолжит его
+)
+
+// PurgeCounts - количество строк по таблицам, удаленных (или подлежащих удалению) вместе с валютой
+type PurgeCounts struct {
+	Prices          int64 `json:"prices"`
+	Aggregates      int64 `json:"aggregates"` // свертки price_aggregates
+	TrackingPeriods int64 `json:"tracking_periods"`
+	AlertRules      int64 `json:"alert_rules"`
+	AlertDeliveries int64 `json:"alert_deliveries"`
+}
+
+// PurgeAudit - запись журнала безвозвратного удаления валюты и ее истории.
+// Не ссылается на currencies, чтобы пережить удаление валюты
+type PurgeAudit struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Symbol      string `gorm:"size:10;index" json:"symbol"`
+	CurrencyID  uint   `json:"currency_id"`
+	Actor       string `gorm:"size:64" json:"actor"`
+	Reason      string `gorm:"size:255" json:"reason"`
+	Status      string `gorm:"size:16" json:"status" enums:"running,completed,failed"`
+	Error       string `gorm:"type:text" json:"error,omitempty"`
+	PurgeCounts `gorm:"embedded"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// TrackingPeriod - период, когда валюта отслеживалась: от добавления или восстановления до удаления.
+// Пустой StoppedAt означает, что валюта отслеживается сейчас; открытый период у валюты один
+type TrackingPeriod struct {
+	ID         uint       `gorm:"primaryKey" json:"-"`
+	CurrencyID uint       `gorm:"index:idx_tracking_periods_currency_started,priority:1;uniqueIndex:idx_tracking_periods_open,where:stopped_at IS NULL" json:"-"`
+	StartedAt  time.Time  `gorm:"index:idx_tracking_periods_currency_started,priority:2" json:"started_at"`
+	StartedBy  string     `gorm:"size:64" json:"started_by"` // кто включил отслеживание: X-Actor запроса, import и т.п.
+	StoppedAt  *time.Time `json:"stopped_at,omitempty"`
+	StoppedBy  string     `gorm:"size:64" json:"stopped_by,omitempty"`
+	Currency   Currency   `gorm:"foreignKey:CurrencyID" json:"-"`
+}
+
+// Covers сообщает, входит ли момент at в период. Момент остановки в период не входит
+func (p TrackingPeriod) Covers(at time.Time) bool {
+	return !at.Before(p.StartedAt) && (p.StoppedAt == nil || at.Before(*p.StoppedAt))
+}
@@ -0,0 +1,354 @@
+// Package pricefile - файловые форматы точек рядов цен (CSV, NDJSON, Parquet),
+// общие для выгрузки, архива и импорта. Сжатие gzip для текстовых форматов добавляет вызывающий код
+package pricefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/parquet-go/parquet-go"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы файлов
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet" // колонки сжимаются zstd
+)
+
+// parquetRowGroupRows - строк в группе parquet: писатель держит в памяти не больше одной группы
+const parquetRowGroupRows = 64 * 1024
+
+// Row - строка файла: точка ряда с символом валюты, чтобы файл читался без бд
+type Row struct {
+	Symbol     string    `parquet:"symbol,dict" json:"symbol"`
+	CurrencyID int64     `parquet:"currency_id" json:"currency_id"`
+	Timestamp  time.Time `parquet:"timestamp,timestamp(microsecond)" json:"timestamp"`
+	Price      string    `parquet:"price" json:"price"` // точное десятичное значение, как в бд
+	Source     string    `parquet:"source,dict" json:"source"`
+	Volume     *float64  `parquet:"volume,optional" json:"volume,omitempty"`
+}
+
+var csvHeader = []string{"symbol", "currency_id", "timestamp", "price", "source", "volume"}
+
+// Extension возвращает расширение файла формата или ошибку для неизвестного формата
+func Extension(format string) (string, error) {
+	switch format {
+	case FormatCSV, FormatNDJSON, FormatParquet:
+		return "." + format, nil
+	default:
+		return "", fmt.Errorf("неизвестный формат %q, ожидается csv, ndjson или parquet", format)
+	}
+}
+
+// ContentType возвращает MIME-тип формата
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Writer - запись строк в файл одного из форматов. Close дописывает хвост файла,
+// но не закрывает исходный io.Writer
+type Writer interface {
+	Write(row Row) error
+	Close() error
+}
+
+// NewWriter создает писатель формата format поверх w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		writer := &csvWriter{w: csv.NewWriter(w)}
+		writer.err = writer.w.Write(csvHeader)
+		return writer, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetWriter{w: parquet.NewGenericWriter[Row](w,
+			parquet.Compression(&parquet.Zstd),
+			parquet.MaxRowsPerRowGroup(parquetRowGroupRows))}, nil
+	default:
+		_, err := Extension(format)
+		return nil, err
+	}
+}
+
+// parquetWriter копит строки пачками, чтобы не писать в parquet по одной
+type parquetWriter struct {
+	w     *parquet.GenericWriter[Row]
+	batch []Row
+}
+
+func (p *parquetWriter) Write(row Row) error {
+	p.batch = append(p.batch, row)
+	if len(p.batch) < 1024 {
+		return nil
+	}
+	return p.flush()
+}
+
+func (p *parquetWriter) flush() error {
+	_, err := p.w.Write(p.batch)
+	p.batch = p.batch[:0]
+	return err
+}
+
+func (p *parquetWriter) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+	return p.w.Close()
+}
+
+type csvWriter struct {
+	w   *csv.Writer
+	err error
+}
+
+func (c *csvWriter) Write(row Row) error {
+	if c.err != nil {
+		return c.err
+	}
+	volume := ""
+	if row.Volume != nil {
+		volume = strconv.FormatFloat(*row.Volume, 'f', -1, 64)
+	}
+	c.err = c.w.Write([]string{
+		row.Symbol,
+		strconv.FormatInt(row.CurrencyID, 10),
+		row.Timestamp.UTC().Format(time.RFC3339Nano),
+		row.Price,
+		row.Source,
+		volume,
+	})
+	return c.err
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return errors.Join(c.err, c.w.Error())
+}
+
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(row Row) error {
+	row.Timestamp = row.Timestamp.UTC()
+	return n.enc.Encode(row)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+// Read читает строки файла формата format и передает их fn. Первая неверная строка
+// или ошибка fn прерывает чтение. Parquet читается с произвольным доступом, поэтому для него r должен быть *os.File
+func Read(format string, r io.Reader, fn func(Row) error) error {
+	return ReadLines(format, r, func(line int64, row Row, err error) error {
+		if err != nil {
+			return fmt.Errorf("строка %d: %w", line, err)
+		}
+		return fn(row)
+	})
+}
+
+// ReadLines читает файл построчно и передает fn каждую строку с ее номером. Неверная строка
+// приходит с ошибкой разбора lineErr и не прерывает чтение, если fn не вернет ошибку.
+// В CSV колонки ищутся по заголовку: обязательны symbol, timestamp и price, остальные колонки Row необязательны.
+// Время принимается в RFC3339 или Unix-секундах/миллисекундах, цена в NDJSON - строкой или числом
+func ReadLines(format string, r io.Reader, fn func(line int64, row Row, lineErr error) error) error {
+	switch format {
+	case FormatCSV:
+		return readCSV(r, fn)
+	case FormatNDJSON:
+		return readNDJSON(r, fn)
+	case FormatParquet:
+		file, ok := r.(*os.File)
+		if !ok {
+			return fmt.Errorf("parquet читается только из файла")
+		}
+		return readParquet(file, fn)
+	default:
+		_, err := Extension(format)
+		return err
+	}
+}
+
+func readParquet(file *os.File, fn func(int64, Row, error) error) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	pf, err := parquet.OpenFile(file, info.Size())
+	if err != nil {
+		return fmt.Errorf("ошибка чтения parquet: %w", err)
+	}
+	reader := parquet.NewGenericReader[Row](pf)
+	defer reader.Close()
+
+	rows := make([]Row, 1024)
+	var line int64
+	for {
+		n, err := reader.Read(rows)
+		for _, row := range rows[:n] {
+			line++
+			if err := fn(line, row, nil); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения parquet: %w", err)
+		}
+	}
+}
+
+func readCSV(r io.Reader, fn func(int64, Row, error) error) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("ошибка чтения заголовка csv: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"symbol", "timestamp", "price"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("в заголовке csv нет колонки %s", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// Ошибка одной записи (лишние поля, кавычки) не мешает читать следующие
+			if err := fn(int64(parseErr.StartLine), Row{}, parseErr.Err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := Row{Symbol: field(record, "symbol"), Price: field(record, "price"), Source: field(record, "source")}
+		lineErr := func() error {
+			if raw := field(record, "currency_id"); raw != "" {
+				if row.CurrencyID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+					return fmt.Errorf("неверный currency_id %q", raw)
+				}
+			}
+			if row.Timestamp, err = parseTime(field(record, "timestamp")); err != nil {
+				return err
+			}
+			if raw := field(record, "volume"); raw != "" {
+				volume, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					return fmt.Errorf("неверный объем %q", raw)
+				}
+				row.Volume = &volume
+			}
+			return nil
+		}()
+		if err := fn(int64(line), row, lineErr); err != nil {
+			return err
+		}
+	}
+}
+
+// ndjsonRow - строка NDJSON до разбора: цена и время бывают и строками, и числами
+type ndjsonRow struct {
+	Symbol     string          `json:"symbol"`
+	CurrencyID int64           `json:"currency_id"`
+	Timestamp  json.RawMessage `json:"timestamp"`
+	Price      json.Number     `json:"price"`
+	Source     string          `json:"source"`
+	Volume     *float64        `json:"volume"`
+}
+
+func readNDJSON(r io.Reader, fn func(int64, Row, error) error) error {
+	reader := bufio.NewReader(r)
+	for line := int64(1); ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			row, lineErr := parseNDJSON(data)
+			if err := fn(line, row, lineErr); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения ndjson: %w", err)
+		}
+	}
+}
+
+func parseNDJSON(data []byte) (Row, error) {
+	var raw ndjsonRow
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Row{}, fmt.Errorf("неверный json: %w", err)
+	}
+	row := Row{Symbol: raw.Symbol, CurrencyID: raw.CurrencyID, Price: raw.Price.String(), Source: raw.Source, Volume: raw.Volume}
+
+	var timestamp string
+	if err := json.Unmarshal(raw.Timestamp, &timestamp); err != nil {
+		timestamp = string(raw.Timestamp) // число Unix-времени
+	}
+	var err error
+	row.Timestamp, err = parseTime(timestamp)
+	return row, err
+}
+
+// unixMillisThreshold - числа по модулю не меньше этого значения считаются миллисекундами, как в HTTP API
+const unixMillisThreshold = 1e12
+
+// parseTime разбирает RFC3339 или Unix-секунды/миллисекунды и возвращает время в UTC
+func parseTime(value string) (time.Time, error) {
+	if value == "" || value == "null" {
+		return time.Time{}, fmt.Errorf("нет метки времени")
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		if math.Abs(n) >= unixMillisThreshold {
+			n /= 1000
+		}
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e9))).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("метка времени %q не является RFC3339 или Unix-временем", value)
+	}
+	return t.UTC(), nil
+}
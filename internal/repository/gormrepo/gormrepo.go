@@ -0,0 +1,379 @@
+// Package gormrepo - переносимая реализация repository.Repository на GORM без SQL конкретной СУБД.
+// Используется для SQLite; postgres встраивает ее и заменяет пакетные запросы на специфичные для PostgreSQL
+package gormrepo
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Repository - хранилище поверх GORM. Подключение должно быть открыто с TranslateError,
+// чтобы нарушение уникальности приходило как gorm.ErrDuplicatedKey
+type Repository struct {
+	db *gorm.DB
+}
+
+// New - конструктор хранилища
+func New(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// DB возвращает подключение, с которым работает хранилище
+func (r *Repository) DB() *gorm.DB {
+	return r.db
+}
+
+func (r *Repository) Active(ctx context.Context) ([]models.Currency, error) {
+	var currencies []models.Currency
+	err := r.db.WithContext(ctx).Where("status = ?", models.CurrencyActive).Order("id").Find(&currencies).Error
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки валют: %w", err)
+	}
+	return currencies, nil
+}
+
+func (r *Repository) Stats(ctx context.Context) ([]repository.CurrencyStats, error) {
+	db := r.db.WithContext(ctx)
+
+	var currencies []models.Currency
+	if err := db.Unscoped().Order("id").Find(&currencies).Error; err != nil {
+		return nil, fmt.Errorf("ошибка загрузки валют: %w", err)
+	}
+
+	var counts []struct {
+		CurrencyID uint
+		PointCount int64
+	}
+	err := db.Model(&models.Price{}).Select("currency_id, count(*) AS point_count").Group("currency_id").Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета точек: %w", err)
+	}
+	pointCounts := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		pointCounts[c.CurrencyID] = c.PointCount
+	}
+
+	stats := make([]repository.CurrencyStats, len(currencies))
+	for i, currency := range currencies {
+		stats[i] = repository.CurrencyStats{Currency: currency, PointCount: pointCounts[currency.ID]}
+		if stats[i].PointCount == 0 {
+			continue
+		}
+		if stats[i].Last, err = r.nearest(db, currency.ID, "timestamp DESC"); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+func (r *Repository) FindBySymbol(ctx context.Context, symbol string) (models.Currency, error) {
+	var currency models.Currency
+	err := r.db.WithContext(ctx).Unscoped().Where("symbol = ?", symbol).First(&currency).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return currency, repository.ErrNotFound
+	}
+	if err != nil {
+		return currency, fmt.Errorf("ошибка поиска валюты: %w", err)
+	}
+	return currency, nil
+}
+
+func (r *Repository) Create(ctx context.Context, currency *models.Currency) error {
+	currency.Status = models.CurrencyActive
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(currency).Error; err != nil {
+			return err
+		}
+		return StartTracking(tx, currency.ID, repository.Actor(ctx))
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return repository.ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения валюты: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Restore(ctx context.Context, currency *models.Currency) error {
+	currency.DeletedAt = gorm.DeletedAt{Valid: false}
+	currency.Status = models.CurrencyActive
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Save(currency).Error; err != nil {
+			return err
+		}
+		return StartTracking(tx, currency.ID, repository.Actor(ctx))
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка восстановления валюты: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id uint, symbol string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var currency models.Currency
+		query := tx
+		if id != 0 {
+			query = query.Where("id = ?", id)
+		} else {
+			query = query.Where("symbol = ?", symbol)
+		}
+		err := query.First(&currency).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return repository.ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка удаления валюты: %w", err)
+		}
+
+		if err := tx.Model(&currency).Update("status", models.CurrencyArchived).Error; err != nil {
+			return fmt.Errorf("ошибка удаления валюты: %w", err)
+		}
+		if err := tx.Delete(&currency).Error; err != nil {
+			return fmt.Errorf("ошибка удаления валюты: %w", err)
+		}
+		if err := StopTracking(tx, currency.ID, repository.Actor(ctx)); err != nil {
+			return fmt.Errorf("ошибка удаления валюты: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *Repository) SetStatus(ctx context.Context, id uint, from, to string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Условие на прежнее состояние не дает двум параллельным запросам открыть или закрыть период дважды
+		result := tx.Model(&models.Currency{}).Where("id = ? AND status = ?", id, from).Update("status", to)
+		if result.Error != nil {
+			return fmt.Errorf("ошибка смены состояния валюты: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return repository.ErrConflict
+		}
+
+		var err error
+		switch {
+		case to == models.CurrencyActive:
+			err = StartTracking(tx, id, repository.Actor(ctx))
+		case from == models.CurrencyActive:
+			err = StopTracking(tx, id, repository.Actor(ctx))
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка смены состояния валюты: %w", err)
+		}
+		return nil
+	})
+}
+
+// StartTracking открывает период отслеживания валюты через db (в том числе внутри транзакции)
+func StartTracking(db *gorm.DB, currencyID uint, actor string) error {
+	return db.Create(&models.TrackingPeriod{CurrencyID: currencyID, StartedAt: time.Now().UTC(), StartedBy: actor}).Error
+}
+
+// StopTracking закрывает открытый период отслеживания валюты, если он есть
+func StopTracking(db *gorm.DB, currencyID uint, actor string) error {
+	return db.Model(&models.TrackingPeriod{}).
+		Where("currency_id = ? AND stopped_at IS NULL", currencyID).
+		Updates(map[string]any{"stopped_at": time.Now().UTC(), "stopped_by": actor}).Error
+}
+
+func (r *Repository) TrackingPeriods(ctx context.Context, symbols []string) (map[string][]models.TrackingPeriod, error) {
+	var rows []struct {
+		models.TrackingPeriod
+		Symbol string
+	}
+	err := r.db.WithContext(ctx).Model(&models.TrackingPeriod{}).
+		Select("tracking_periods.*, currencies.symbol").
+		Joins("JOIN currencies ON currencies.id = tracking_periods.currency_id").
+		Where("currencies.symbol IN ?", symbols).
+		Order("tracking_periods.currency_id, tracking_periods.started_at").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки истории отслеживания: %w", err)
+	}
+
+	periods := make(map[string][]models.TrackingPeriod)
+	for _, row := range rows {
+		period := row.TrackingPeriod
+		period.StartedAt = period.StartedAt.UTC()
+		if period.StoppedAt != nil {
+			stopped := period.StoppedAt.UTC()
+			period.StoppedAt = &stopped
+		}
+		periods[row.Symbol] = append(periods[row.Symbol], period)
+	}
+	return periods, nil
+}
+
+func (r *Repository) SavePrice(ctx context.Context, price *models.Price) error {
+	if err := UpsertPrice(r.db.WithContext(ctx), price); err != nil {
+		return fmt.Errorf("ошибка сохранения цены: %w", err)
+	}
+	return nil
+}
+
+// UpsertPrice сохраняет точку ряда через db (в том числе внутри транзакции). Точка на уже
+// занятый момент времени заменяет прежнюю, сохраняя ее ID: (currency_id, timestamp) уникален
+func UpsertPrice(db *gorm.DB, price *models.Price) error {
+	price.Timestamp = price.Timestamp.UTC()
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency_id"}, {Name: "timestamp"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "source", "volume", "updated_at", "deleted_at"}),
+	}).Create(price).Error
+}
+
+// Neighbors ищет соседние точки отдельными запросами на каждую пару.
+// Для больших пакетов postgres заменяет его одним запросом
+func (r *Repository) Neighbors(ctx context.Context, queries []repository.PriceQuery) ([]repository.Neighbors, error) {
+	db := r.db.WithContext(ctx)
+	result := make([]repository.Neighbors, len(queries))
+	ids := make(map[string]uint) // id валют, в том числе архивных, 0 - валюты нет
+
+	for i, q := range queries {
+		id, ok := ids[q.Symbol]
+		if !ok {
+			currency, err := r.FindBySymbol(ctx, q.Symbol)
+			switch {
+			case err == nil:
+				id = currency.ID
+			case err != nil && !errors.Is(err, repository.ErrNotFound):
+				return nil, err
+			}
+			ids[q.Symbol] = id
+		}
+		if id == 0 {
+			continue
+		}
+
+		at := q.At.UTC()
+		neighbors := repository.Neighbors{CurrencyFound: true}
+		var err error
+		if neighbors.Before, err = r.nearest(db.Where("timestamp <= ?", at), id, "timestamp DESC"); err != nil {
+			return nil, err
+		}
+		if neighbors.After, err = r.nearest(db.Where("timestamp >= ?", at), id, "timestamp ASC"); err != nil {
+			return nil, err
+		}
+		result[i] = neighbors
+	}
+	return result, nil
+}
+
+// nearest возвращает первую точку ряда в порядке order с учетом уже наложенных на db условий
+func (r *Repository) nearest(db *gorm.DB, currencyID uint, order string) (*repository.Point, error) {
+	var points []repository.Point
+	err := db.Model(&models.Price{}).
+		Select("price", "timestamp", "source").
+		Where("currency_id = ?", currencyID).
+		Order(order).Limit(1).
+		Scan(&points).Error
+	if err != nil {
+		return nil, fmt.Errorf("ошибка поиска точки ряда: %w", err)
+	}
+	if len(points) == 0 {
+		return nil, nil
+	}
+	points[0].Timestamp = points[0].Timestamp.UTC()
+	return &points[0], nil
+}
+
+func (r *Repository) Range(ctx context.Context, symbol string, from, to time.Time, limit int) ([]repository.Point, error) {
+	currency, err := r.FindBySymbol(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	points := []repository.Point{}
+	err = r.db.WithContext(ctx).Model(&models.Price{}).
+		Select("price", "timestamp", "source").
+		Where("currency_id = ? AND timestamp >= ? AND timestamp < ?", currency.ID, from.UTC(), to.UTC()).
+		Order("timestamp").Limit(limit).
+		Scan(&points).Error
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса диапазона цен: %w", err)
+	}
+	for i := range points {
+		points[i].Timestamp = points[i].Timestamp.UTC()
+	}
+	return points, nil
+}
+
+// Export читает точки через курсор database/sql: драйвер отдает строки по мере получения
+// от сервера, поэтому память не зависит от размера выгрузки
+func (r *Repository) Export(ctx context.Context, q repository.ExportQuery, fn func(repository.ExportPoint) error) error {
+	currencies, err := r.exportCurrencies(ctx, q.Symbols)
+	if err != nil {
+		return err
+	}
+	if len(currencies) == 0 {
+		return nil
+	}
+	symbols := make(map[uint]string, len(currencies))
+	ids := make([]uint, 0, len(currencies))
+	for _, currency := range currencies {
+		symbols[currency.ID] = currency.Symbol
+		ids = append(ids, currency.ID)
+	}
+
+	rows, err := r.db.WithContext(ctx).Model(&models.Price{}).
+		Select("currency_id", "price", "timestamp", "source", "volume").
+		Where("currency_id IN ? AND timestamp >= ? AND timestamp < ?", ids, q.From.UTC(), q.To.UTC()).
+		Order("currency_id, timestamp").
+		Rows()
+	if err != nil {
+		return fmt.Errorf("ошибка выгрузки цен: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			point  repository.ExportPoint
+			volume sql.NullFloat64
+		)
+		if err := rows.Scan(&point.CurrencyID, &point.Price, &point.Timestamp, &point.Source, &volume); err != nil {
+			return fmt.Errorf("ошибка выгрузки цен: %w", err)
+		}
+		point.Symbol = symbols[point.CurrencyID]
+		point.Timestamp = point.Timestamp.UTC()
+		if volume.Valid {
+			point.Volume = &volume.Float64
+		}
+		if err := fn(point); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка выгрузки цен: %w", err)
+	}
+	return nil
+}
+
+// exportCurrencies возвращает валюты по символам (в том числе архивные) в порядке id
+// или, если символов нет, все неархивные
+func (r *Repository) exportCurrencies(ctx context.Context, symbols []string) ([]models.Currency, error) {
+	query := r.db.WithContext(ctx)
+	if len(symbols) > 0 {
+		query = query.Unscoped().Where("symbol IN ?", symbols)
+	}
+	var currencies []models.Currency
+	if err := query.Order("id").Find(&currencies).Error; err != nil {
+		return nil, fmt.Errorf("ошибка загрузки валют: %w", err)
+	}
+	found := make(map[string]bool, len(currencies))
+	for _, currency := range currencies {
+		found[currency.Symbol] = true
+	}
+	for _, symbol := range symbols {
+		if !found[symbol] {
+			return nil, fmt.Errorf("%w: %s", repository.ErrNotFound, symbol)
+		}
+	}
+	return currencies, nil
+}
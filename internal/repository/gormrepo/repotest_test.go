@@ -0,0 +1,34 @@
+package gormrepo_test
+
+import (
+	"affarm/internal/database"
+	"affarm/internal/repository"
+	"affarm/internal/repository/gormrepo"
+	"affarm/internal/repository/repotest"
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestRepository(t *testing.T) {
+	for _, c := range repotest.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			// Каждая проверка получает свой файл SQLite, чтобы данные проверок не смешивались
+			newRepo := func() (repository.Repository, error) {
+				db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "repo.db"))
+				if err != nil {
+					return nil, err
+				}
+				t.Cleanup(func() {
+					if sqlDB, err := db.DB(); err == nil {
+						sqlDB.Close()
+					}
+				})
+				return gormrepo.New(db), nil
+			}
+			if err := repotest.TestCase(context.Background(), c, newRepo); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+// Package memory - реализация repository.Repository в памяти процесса для локальной разработки и проверок.
+// Данные теряются при перезапуске
+package memory
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"context"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Repository - хранилище валют и рядов цен в памяти
+type Repository struct {
+	mu           sync.RWMutex
+	currencies   map[uint]*models.Currency
+	bySymbol     map[string]uint
+	prices       map[uint][]models.Price // ряды по id валюты, по возрастанию времени
+	periods      map[uint][]models.TrackingPeriod
+	nextCurrency uint
+	nextPrice    uint
+}
+
+// New - конструктор пустого хранилища
+func New() *Repository {
+	return &Repository{
+		currencies: make(map[uint]*models.Currency),
+		bySymbol:   make(map[string]uint),
+		prices:     make(map[uint][]models.Price),
+		periods:    make(map[uint][]models.TrackingPeriod),
+	}
+}
+
+func (r *Repository) Active(ctx context.Context) ([]models.Currency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]models.Currency, 0, len(r.currencies))
+	for _, currency := range r.sortedLocked() {
+		if !currency.DeletedAt.Valid && currency.Status == models.CurrencyActive {
+			result = append(result, currency)
+		}
+	}
+	return result, nil
+}
+
+func (r *Repository) Stats(ctx context.Context) ([]repository.CurrencyStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	currencies := r.sortedLocked()
+	stats := make([]repository.CurrencyStats, len(currencies))
+	for i, currency := range currencies {
+		series := r.prices[currency.ID]
+		stats[i] = repository.CurrencyStats{Currency: currency, PointCount: int64(len(series))}
+		if len(series) > 0 {
+			stats[i].Last = toPoint(series[len(series)-1])
+		}
+	}
+	return stats, nil
+}
+
+func (r *Repository) FindBySymbol(ctx context.Context, symbol string) (models.Currency, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.bySymbol[symbol]
+	if !ok {
+		return models.Currency{}, repository.ErrNotFound
+	}
+	return *r.currencies[id], nil
+}
+
+func (r *Repository) Create(ctx context.Context, currency *models.Currency) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.bySymbol[currency.Symbol]; ok {
+		return repository.ErrConflict
+	}
+	r.nextCurrency++
+	now := time.Now()
+	currency.ID = r.nextCurrency
+	currency.CreatedAt, currency.UpdatedAt = now, now
+	currency.Status = models.CurrencyActive
+
+	stored := *currency
+	r.currencies[stored.ID] = &stored
+	r.bySymbol[stored.Symbol] = stored.ID
+	r.startLocked(stored.ID, repository.Actor(ctx))
+	return nil
+}
+
+func (r *Repository) Restore(ctx context.Context, currency *models.Currency) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.currencies[currency.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.DeletedAt = gorm.DeletedAt{}
+	stored.Status = models.CurrencyActive
+	stored.UpdatedAt = time.Now()
+	*currency = *stored
+	r.startLocked(stored.ID, repository.Actor(ctx))
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id uint, symbol string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id == 0 {
+		id = r.bySymbol[symbol]
+	}
+	stored, ok := r.currencies[id]
+	if !ok || stored.DeletedAt.Valid {
+		return repository.ErrNotFound
+	}
+	now := time.Now()
+	stored.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+	stored.Status = models.CurrencyArchived
+	r.stopLocked(id, repository.Actor(ctx))
+	return nil
+}
+
+func (r *Repository) SetStatus(ctx context.Context, id uint, from, to string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.currencies[id]
+	if !ok || stored.DeletedAt.Valid || stored.Status != from {
+		return repository.ErrConflict
+	}
+	stored.Status = to
+	stored.UpdatedAt = time.Now()
+	switch {
+	case to == models.CurrencyActive:
+		r.startLocked(id, repository.Actor(ctx))
+	case from == models.CurrencyActive:
+		r.stopLocked(id, repository.Actor(ctx))
+	}
+	return nil
+}
+
+// startLocked открывает период отслеживания валюты
+func (r *Repository) startLocked(id uint, actor string) {
+	r.periods[id] = append(r.periods[id], models.TrackingPeriod{CurrencyID: id, StartedAt: time.Now().UTC(), StartedBy: actor})
+}
+
+// stopLocked закрывает открытый период отслеживания валюты, если он есть
+func (r *Repository) stopLocked(id uint, actor string) {
+	periods := r.periods[id]
+	if n := len(periods); n > 0 && periods[n-1].StoppedAt == nil {
+		stopped := time.Now().UTC()
+		periods[n-1].StoppedAt, periods[n-1].StoppedBy = &stopped, actor
+	}
+}
+
+func (r *Repository) TrackingPeriods(ctx context.Context, symbols []string) (map[string][]models.TrackingPeriod, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string][]models.TrackingPeriod)
+	for _, symbol := range symbols {
+		if id, ok := r.bySymbol[symbol]; ok && len(r.periods[id]) > 0 {
+			result[symbol] = append([]models.TrackingPeriod(nil), r.periods[id]...)
+		}
+	}
+	return result, nil
+}
+
+func (r *Repository) SavePrice(ctx context.Context, price *models.Price) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	price.CreatedAt, price.UpdatedAt = now, now
+	price.Timestamp = price.Timestamp.UTC()
+	if price.Source == "" {
+		price.Source = models.PriceSourceBinance
+	}
+
+	// Вставка с сохранением порядка по времени; обычно точка добавляется в конец
+	series := r.prices[price.CurrencyID]
+	i := sort.Search(len(series), func(i int) bool { return series[i].Timestamp.After(price.Timestamp) })
+
+	// Точка на уже занятый момент заменяет прежнюю, как upsert по (currency_id, timestamp)
+	if i > 0 && series[i-1].Timestamp.Equal(price.Timestamp) {
+		price.ID, price.CreatedAt = series[i-1].ID, series[i-1].CreatedAt
+		series[i-1] = *price
+		return nil
+	}
+
+	r.nextPrice++
+	price.ID = r.nextPrice
+	series = append(series, models.Price{})
+	copy(series[i+1:], series[i:])
+	series[i] = *price
+	r.prices[price.CurrencyID] = series
+	return nil
+}
+
+func (r *Repository) Neighbors(ctx context.Context, queries []repository.PriceQuery) ([]repository.Neighbors, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]repository.Neighbors, len(queries))
+	for i, q := range queries {
+		currency, ok := r.currencyLocked(q.Symbol)
+		if !ok {
+			continue
+		}
+		series := r.prices[currency.ID]
+		at := q.At.UTC()
+
+		// Первая точка строго позже at; все точки до нее - не позже at
+		after := sort.Search(len(series), func(i int) bool { return series[i].Timestamp.After(at) })
+		result[i].CurrencyFound = true
+		if after > 0 {
+			result[i].Before = toPoint(series[after-1])
+			if series[after-1].Timestamp.Equal(at) {
+				result[i].After = result[i].Before
+			}
+		}
+		if result[i].After == nil && after < len(series) {
+			result[i].After = toPoint(series[after])
+		}
+	}
+	return result, nil
+}
+
+func (r *Repository) Range(ctx context.Context, symbol string, from, to time.Time, limit int) ([]repository.Point, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	currency, ok := r.currencyLocked(symbol)
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+
+	series := r.prices[currency.ID]
+	start := sort.Search(len(series), func(i int) bool { return !series[i].Timestamp.Before(from) })
+	points := []repository.Point{}
+	for _, price := range series[start:] {
+		if !price.Timestamp.Before(to) || len(points) == limit {
+			break
+		}
+		points = append(points, *toPoint(price))
+	}
+	return points, nil
+}
+
+// Export копирует точки под блокировкой и передает их fn уже без нее,
+// чтобы медленный получатель не задерживал запись новых цен
+func (r *Repository) Export(ctx context.Context, q repository.ExportQuery, fn func(repository.ExportPoint) error) error {
+	points, err := r.exportPoints(q)
+	if err != nil {
+		return err
+	}
+	for _, point := range points {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(point); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) exportPoints(q repository.ExportQuery) ([]repository.ExportPoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var currencies []models.Currency
+	if len(q.Symbols) == 0 {
+		for _, currency := range r.sortedLocked() {
+			if !currency.DeletedAt.Valid {
+				currencies = append(currencies, currency)
+			}
+		}
+	} else {
+		for _, symbol := range q.Symbols {
+			currency, ok := r.currencyLocked(symbol)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", repository.ErrNotFound, symbol)
+			}
+			currencies = append(currencies, *currency)
+		}
+		sort.Slice(currencies, func(i, j int) bool { return currencies[i].ID < currencies[j].ID })
+	}
+
+	var points []repository.ExportPoint
+	for _, currency := range currencies {
+		series := r.prices[currency.ID]
+		start := sort.Search(len(series), func(i int) bool { return !series[i].Timestamp.Before(q.From) })
+		for _, price := range series[start:] {
+			if !price.Timestamp.Before(q.To) {
+				break
+			}
+			points = append(points, repository.ExportPoint{
+				Symbol:     currency.Symbol,
+				CurrencyID: currency.ID,
+				Point:      *toPoint(price),
+				Volume:     price.Volume,
+			})
+		}
+	}
+	return points, nil
+}
+
+// currencyLocked ищет валюту по символу, в том числе архивную: ее история доступна для чтения
+func (r *Repository) currencyLocked(symbol string) (*models.Currency, bool) {
+	id, ok := r.bySymbol[symbol]
+	if !ok {
+		return nil, false
+	}
+	return r.currencies[id], true
+}
+
+func (r *Repository) sortedLocked() []models.Currency {
+	result := make([]models.Currency, 0, len(r.currencies))
+	for _, currency := range r.currencies {
+		result = append(result, *currency)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+func toPoint(price models.Price) *repository.Point {
+	return &repository.Point{
+		Price:     decimal.NewFromFloat(price.Price),
+		Timestamp: price.Timestamp,
+		Source:    price.Source,
+	}
+}
@@ -0,0 +1,20 @@
+package memory_test
+
+import (
+	"affarm/internal/repository"
+	"affarm/internal/repository/memory"
+	"affarm/internal/repository/repotest"
+	"context"
+	"testing"
+)
+
+func TestRepository(t *testing.T) {
+	newRepo := func() (repository.Repository, error) { return memory.New(), nil }
+	for _, c := range repotest.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if err := repotest.TestCase(context.Background(), c, newRepo); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
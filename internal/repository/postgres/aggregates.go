@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Старые сырые цены сворачиваются в price_aggregates (см. database.Retention). Уровни сверток
+// покрывают непересекающиеся периоды, более старые - более крупным шагом, поэтому ближайшая
+// по времени свертка всегда берется из самого подробного уровня, покрывающего момент.
+// Точкой свертки считается цена close на момент last_at - последняя реально наблюдавшаяся цена
+
+// aggregateNeighborsSQL ищет соседние точки в свертках для запросов, не найденных среди сырых точек
+const aggregateNeighborsSQL = `
+WITH req AS (
+    SELECT * FROM unnest($1::bigint[], $2::text[], $3::timestamptz[]) AS r(idx, symbol, ts)
+)
+SELECT r.idx,
+       b.close, b.last_at, b.resolution_sec,
+       a.close, a.last_at, a.resolution_sec
+FROM req r
+JOIN currencies c ON c.symbol = r.symbol
+LEFT JOIN LATERAL (
+    SELECT close, last_at, resolution_sec
+    FROM price_aggregates g
+    WHERE g.currency_id = c.id AND g.last_at <= r.ts
+    ORDER BY g.last_at DESC
+    LIMIT 1
+) b ON true
+LEFT JOIN LATERAL (
+    SELECT close, last_at, resolution_sec
+    FROM price_aggregates g
+    WHERE g.currency_id = c.id AND g.last_at >= r.ts
+    ORDER BY g.last_at ASC
+    LIMIT 1
+) a ON true`
+
+// aggregateRangeSQL возвращает точки сверток валюты в диапазоне [$2, $3) по возрастанию времени
+const aggregateRangeSQL = `
+SELECT g.close, g.last_at, g.resolution_sec
+FROM price_aggregates g
+JOIN currencies c ON c.id = g.currency_id
+WHERE c.symbol = $1 AND g.last_at >= $2 AND g.last_at < $3
+ORDER BY g.last_at
+LIMIT $4`
+
+// aggregateNeighbors дополняет result соседними точками из сверток для запросов missing.
+// Последующая точка из сверток заменяет сырую, только если она ближе к моменту запроса
+func (r *Repository) aggregateNeighbors(ctx context.Context, db *sql.DB, queries []repository.PriceQuery, missing []int, result []repository.Neighbors) error {
+	indexes := make([]int64, len(missing))
+	symbols := make([]string, len(missing))
+	moments := make([]time.Time, len(missing))
+	for i, idx := range missing {
+		indexes[i] = int64(idx)
+		symbols[i] = queries[idx].Symbol
+		moments[i] = queries[idx].At.UTC()
+	}
+
+	rows, err := db.QueryContext(ctx, aggregateNeighborsSQL, indexes, symbols, moments)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса сверток цен: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			idx                               int64
+			beforePrice, afterPrice           decimal.NullDecimal
+			beforeTime, afterTime             sql.NullTime
+			beforeResolution, afterResolution sql.NullInt64
+		)
+		if err := rows.Scan(&idx,
+			&beforePrice, &beforeTime, &beforeResolution,
+			&afterPrice, &afterTime, &afterResolution); err != nil {
+			return fmt.Errorf("ошибка чтения сверток цен: %w", err)
+		}
+
+		neighbors := &result[idx]
+		if beforeTime.Valid {
+			neighbors.Before = aggregatePoint(beforePrice.Decimal, beforeTime.Time, beforeResolution.Int64)
+		}
+		if afterTime.Valid && (neighbors.After == nil || afterTime.Time.Before(neighbors.After.Timestamp)) {
+			neighbors.After = aggregatePoint(afterPrice.Decimal, afterTime.Time, afterResolution.Int64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка чтения сверток цен: %w", err)
+	}
+	return nil
+}
+
+// Range возвращает сырые точки диапазона, а его начало, уже свернутое, - точками сверток
+func (r *Repository) Range(ctx context.Context, symbol string, from, to time.Time, limit int) ([]repository.Point, error) {
+	raw, err := r.Repository.Range(ctx, symbol, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Свертки старше сырых точек: берутся только до первой сырой точки диапазона
+	until := to.UTC()
+	if len(raw) > 0 {
+		until = raw[0].Timestamp
+	}
+	db, err := r.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения соединения с бд: %w", err)
+	}
+	rows, err := db.QueryContext(ctx, aggregateRangeSQL, symbol, from.UTC(), until, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса диапазона сверток: %w", err)
+	}
+	defer rows.Close()
+
+	var points []repository.Point
+	for rows.Next() {
+		var (
+			price      decimal.NullDecimal
+			stamp      time.Time
+			resolution int64
+		)
+		if err := rows.Scan(&price, &stamp, &resolution); err != nil {
+			return nil, fmt.Errorf("ошибка чтения диапазона сверток: %w", err)
+		}
+		points = append(points, *aggregatePoint(price.Decimal, stamp, resolution))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения диапазона сверток: %w", err)
+	}
+	if len(points) == 0 {
+		return raw, nil
+	}
+
+	points = append(points, raw...)
+	if len(points) > limit {
+		points = points[:limit]
+	}
+	return points, nil
+}
+
+func aggregatePoint(price decimal.Decimal, at time.Time, resolutionSec int64) *repository.Point {
+	return &repository.Point{
+		Price:      price,
+		Timestamp:  at.UTC(),
+		Source:     models.PriceSourceDownsampled,
+		Resolution: time.Duration(resolutionSec) * time.Second,
+	}
+}
@@ -0,0 +1,191 @@
+// Package postgres - реализация repository.Repository на PostgreSQL через GORM
+package postgres
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"affarm/internal/repository/gormrepo"
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"time"
+)
+
+// lookupPricesSQL находит соседние точки для всего набора запросов за один проход:
+// массивы разворачиваются через unnest, а ближайшие точки ищутся LATERAL-подзапросами,
+// каждый из которых использует индекс по (currency_id, timestamp). prices секционирована по месяцам:
+// условие на p.timestamp отсекает секции во время выполнения, а ORDER BY по ключу секционирования
+// читает оставшиеся секции по порядку и останавливается на первой найденной точке
+const lookupPricesSQL = `
+WITH req AS (
+    SELECT * FROM unnest($1::bigint[], $2::text[], $3::timestamptz[]) AS r(idx, symbol, ts)
+)
+SELECT r.idx, c.id IS NOT NULL,
+       b.price, b.timestamp, b.source,
+       a.price, a.timestamp, a.source
+FROM req r
+LEFT JOIN currencies c ON c.symbol = r.symbol
+LEFT JOIN LATERAL (
+    SELECT price, timestamp, source
+    FROM prices p
+    WHERE p.currency_id = c.id AND p.timestamp <= r.ts
+    ORDER BY p.timestamp DESC
+    LIMIT 1
+) b ON true
+LEFT JOIN LATERAL (
+    SELECT price, timestamp, source
+    FROM prices p
+    WHERE p.currency_id = c.id AND p.timestamp >= r.ts
+    ORDER BY p.timestamp ASC
+    LIMIT 1
+) a ON true
+ORDER BY r.idx`
+
+// statsSQL собирает сводку по всем валютам одним запросом, последние точки ищутся по индексу
+const statsSQL = `
+SELECT c.id, s.point_count, l.price, l.timestamp, l.source
+FROM currencies c
+LEFT JOIN LATERAL (
+    SELECT count(*) AS point_count FROM prices p WHERE p.currency_id = c.id
+) s ON true
+LEFT JOIN LATERAL (
+    SELECT price, timestamp, source FROM prices p
+    WHERE p.currency_id = c.id
+    ORDER BY timestamp DESC
+    LIMIT 1
+) l ON true
+ORDER BY c.id`
+
+// Repository - хранилище поверх GORM-подключения к PostgreSQL. Простые операции берутся
+// из gormrepo, пакетные запросы выполняются специфичным для PostgreSQL SQL
+type Repository struct {
+	*gormrepo.Repository
+	db *gorm.DB
+}
+
+// New - конструктор хранилища
+func New(db *gorm.DB) *Repository {
+	return &Repository{Repository: gormrepo.New(db), db: db}
+}
+
+func (r *Repository) Stats(ctx context.Context) ([]repository.CurrencyStats, error) {
+	var currencies []models.Currency
+	if err := r.db.WithContext(ctx).Unscoped().Order("id").Find(&currencies).Error; err != nil {
+		return nil, fmt.Errorf("ошибка загрузки валют: %w", err)
+	}
+
+	db, err := r.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения соединения с бд: %w", err)
+	}
+	rows, err := db.QueryContext(ctx, statsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки сводки по валютам: %w", err)
+	}
+	defer rows.Close()
+
+	type summary struct {
+		count int64
+		last  *repository.Point
+	}
+	summaries := make(map[uint]summary, len(currencies))
+	for rows.Next() {
+		var (
+			id     uint
+			count  int64
+			price  decimal.NullDecimal
+			stamp  sql.NullTime
+			source sql.NullString
+		)
+		if err := rows.Scan(&id, &count, &price, &stamp, &source); err != nil {
+			return nil, fmt.Errorf("ошибка чтения сводки по валютам: %w", err)
+		}
+		item := summary{count: count}
+		if stamp.Valid {
+			item.last = &repository.Point{Price: price.Decimal, Timestamp: stamp.Time.UTC(), Source: source.String}
+		}
+		summaries[id] = item
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения сводки по валютам: %w", err)
+	}
+
+	stats := make([]repository.CurrencyStats, len(currencies))
+	for i, currency := range currencies {
+		item := summaries[currency.ID]
+		stats[i] = repository.CurrencyStats{Currency: currency, PointCount: item.count, Last: item.last}
+	}
+	return stats, nil
+}
+
+// Neighbors ищет соседние точки для всего пакета одним запросом
+func (r *Repository) Neighbors(ctx context.Context, queries []repository.PriceQuery) ([]repository.Neighbors, error) {
+	result := make([]repository.Neighbors, len(queries))
+	if len(queries) == 0 {
+		return result, nil
+	}
+
+	indexes := make([]int64, len(queries))
+	symbols := make([]string, len(queries))
+	moments := make([]time.Time, len(queries))
+	for i, q := range queries {
+		indexes[i] = int64(i)
+		symbols[i] = q.Symbol
+		moments[i] = q.At.UTC()
+	}
+
+	db, err := r.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения соединения с бд: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, lookupPricesSQL, indexes, symbols, moments)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка пакетного запроса цен: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			idx                       int64
+			found                     bool
+			beforePrice, afterPrice   decimal.NullDecimal
+			beforeTime, afterTime     sql.NullTime
+			beforeSource, afterSource sql.NullString
+		)
+		if err := rows.Scan(&idx, &found,
+			&beforePrice, &beforeTime, &beforeSource,
+			&afterPrice, &afterTime, &afterSource); err != nil {
+			return nil, fmt.Errorf("ошибка чтения результата пакетного запроса: %w", err)
+		}
+
+		neighbors := repository.Neighbors{CurrencyFound: found}
+		if beforeTime.Valid {
+			neighbors.Before = &repository.Point{Price: beforePrice.Decimal, Timestamp: beforeTime.Time.UTC(), Source: beforeSource.String}
+		}
+		if afterTime.Valid {
+			neighbors.After = &repository.Point{Price: afterPrice.Decimal, Timestamp: afterTime.Time.UTC(), Source: afterSource.String}
+		}
+		result[idx] = neighbors
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения результата пакетного запроса: %w", err)
+	}
+
+	// Сырые точки до момента запроса уже свернуты - ищем в свертках
+	var missing []int
+	for i, neighbors := range result {
+		if neighbors.CurrencyFound && neighbors.Before == nil {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) > 0 {
+		if err := r.aggregateNeighbors(ctx, db, queries, missing, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package postgres_test
+
+import (
+	"affarm/internal/database"
+	"affarm/internal/repository"
+	pgrepo "affarm/internal/repository/postgres"
+	"affarm/internal/repository/repotest"
+	"context"
+	"os"
+	"testing"
+)
+
+// Проверки оставляют в базе тестовые валюты, поэтому запускаются только при явно заданном PG_HOST,
+// который должен указывать на тестовую базу
+func TestRepository(t *testing.T) {
+	if testing.Short() || os.Getenv("PG_HOST") == "" {
+		t.Skip("нужна тестовая база PostgreSQL: задайте PG_HOST и остальные переменные PG_*")
+	}
+
+	ctx := context.Background()
+	db, err := database.GetGormDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	migrator, err := database.NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	newRepo := func() (repository.Repository, error) { return pgrepo.New(db), nil }
+	for _, c := range repotest.Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if err := repotest.TestCase(ctx, c, newRepo); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
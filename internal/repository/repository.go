@@ -0,0 +1,133 @@
+// Package repository описывает хранилище валют и рядов цен, с которым работают сервисы.
+// Реализации лежат во вложенных пакетах: postgres, gormrepo (переносимая, для SQLite) и memory.
+// Все реализации должны проходить проверки из repotest
+package repository
+
+import (
+	"affarm/internal/models"
+	"context"
+	"errors"
+	"github.com/shopspring/decimal"
+	"strings"
+	"time"
+)
+
+// Ошибки хранилища, общие для всех реализаций
+var (
+	ErrNotFound = errors.New("record not found")
+	ErrConflict = errors.New("record already exists")
+)
+
+// actorKey - ключ контекста с автором изменения
+type actorKey struct{}
+
+// ActorSystem - автор изменений, сделанных без явного автора в контексте
+const ActorSystem = "system"
+
+// maxActorLength - длина колонок started_by и stopped_by
+const maxActorLength = 64
+
+// WithActor добавляет в контекст автора изменения: он записывается в историю отслеживания валют.
+// Автор приходит от клиента, поэтому обрезается до длины колонки
+func WithActor(ctx context.Context, actor string) context.Context {
+	actor = strings.TrimSpace(actor)
+	if runes := []rune(actor); len(runes) > maxActorLength {
+		actor = string(runes[:maxActorLength])
+	}
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor возвращает автора изменения из контекста или ActorSystem
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return ActorSystem
+}
+
+// PriceQuery - запрос цены одной валюты на момент времени
+type PriceQuery struct {
+	Symbol string
+	At     time.Time
+}
+
+// Point - точка ряда цен с точным значением из хранилища
+type Point struct {
+	Price      decimal.Decimal
+	Timestamp  time.Time
+	Source     string
+	Resolution time.Duration // 0 - сырая точка, иначе шаг свертки старых цен, из которой взята точка
+}
+
+// Neighbors - соседние точки ряда для одного PriceQuery
+type Neighbors struct {
+	CurrencyFound bool   // валюта существует, в том числе архивная
+	Before        *Point // последняя точка не позже запрошенного момента
+	After         *Point // первая точка не раньше запрошенного момента
+}
+
+// ExportQuery - выгрузка сырых точек нескольких валют за период [From, To)
+type ExportQuery struct {
+	Symbols []string // пусто - все неархивные валюты, включая приостановленные; архивные выгружаются по символу
+	From    time.Time
+	To      time.Time
+}
+
+// ExportPoint - точка ряда при выгрузке вместе с валютой и объемом торгов
+type ExportPoint struct {
+	Symbol     string
+	CurrencyID uint
+	Point
+	Volume *float64
+}
+
+// CurrencyStats - валюта (включая удаленные) со сводкой по ее ряду цен
+type CurrencyStats struct {
+	Currency   models.Currency
+	PointCount int64
+	Last       *Point // последняя точка ряда, пусто если точек нет
+}
+
+// Currencies - хранилище отслеживаемых валют
+type Currencies interface {
+	// Active возвращает валюты, цены которых сейчас собираются (в состоянии active)
+	Active(ctx context.Context) ([]models.Currency, error)
+	// Stats возвращает все валюты, включая удаленные, со сводкой по рядам цен
+	Stats(ctx context.Context) ([]CurrencyStats, error)
+	// FindBySymbol ищет валюту по символу, включая удаленные. ErrNotFound, если записи нет
+	FindBySymbol(ctx context.Context, symbol string) (models.Currency, error)
+	// Create сохраняет новую валюту и открывает период отслеживания. ErrConflict, если символ уже занят
+	Create(ctx context.Context, currency *models.Currency) error
+	// Restore снимает пометку удаления с валюты и открывает новый период отслеживания
+	Restore(ctx context.Context, currency *models.Currency) error
+	// Delete помечает удаленной валюту по id (если задан) или символу и закрывает ее период отслеживания.
+	// ErrNotFound, если активной валюты нет
+	Delete(ctx context.Context, id uint, symbol string) error
+	// SetStatus переводит неудаленную валюту из состояния from в to, закрывая период отслеживания при уходе
+	// из active и открывая новый при возврате. ErrConflict, если валюта не в состоянии from или удалена
+	SetStatus(ctx context.Context, id uint, from, to string) error
+	// TrackingPeriods возвращает периоды отслеживания валют по символам (включая удаленные) по возрастанию начала.
+	// Валют, которых нет, в результате нет
+	TrackingPeriods(ctx context.Context, symbols []string) (map[string][]models.TrackingPeriod, error)
+}
+
+// Prices - хранилище рядов цен
+type Prices interface {
+	// SavePrice добавляет точку в ряд, заполняя ID. Точка на уже занятый момент времени
+	// заменяет прежнюю и получает ее ID
+	SavePrice(ctx context.Context, price *models.Price) error
+	// Neighbors возвращает соседние точки для каждого запроса, в порядке запросов
+	Neighbors(ctx context.Context, queries []PriceQuery) ([]Neighbors, error)
+	// Range возвращает точки ряда валюты (в том числе архивной) в диапазоне [from, to) по возрастанию времени,
+	// не больше limit. ErrNotFound, если валюты нет
+	Range(ctx context.Context, symbol string, from, to time.Time, limit int) ([]Point, error)
+	// Export передает fn сырые точки валют запроса по порядку валют и по возрастанию времени,
+	// не загружая весь результат в память. Ошибка fn прерывает выгрузку. ErrNotFound, если какой-то валюты нет
+	Export(ctx context.Context, q ExportQuery, fn func(ExportPoint) error) error
+}
+
+// Repository - полное хранилище сервиса
+type Repository interface {
+	Currencies
+	Prices
+}
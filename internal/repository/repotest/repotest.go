@@ -0,0 +1,571 @@
+// Package repotest - общий набор проверок поведения repository.Repository.
+// Проверки одинаково запускаются для всех реализаций, по образцу testing/fstest:
+// TestRepository и TestCase возвращают ошибку со списком расхождений, а не зависят от пакета testing.
+// Запускаются они из repotest_test.go пакетов memory, gormrepo и postgres через go test
+package repotest
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"math/rand"
+	"time"
+)
+
+// Case - одна проверка. Символы валют проверка получает через sym, чтобы не пересекаться
+// с данными, уже лежащими в хранилище
+type Case struct {
+	Name string
+	Run  func(ctx context.Context, repo repository.Repository, sym func(string) string) error
+}
+
+// Cases - все проверки набора
+var Cases = []Case{
+	{"currencies/create-find", testCreateFind},
+	{"currencies/delete-restore", testDeleteRestore},
+	{"currencies/stats", testStats},
+	{"currencies/tracking", testTracking},
+	{"currencies/status", testStatus},
+	{"prices/neighbors", testNeighbors},
+	{"prices/neighbors-missing", testNeighborsMissing},
+	{"prices/range", testRange},
+	{"prices/precision", testPrecision},
+	{"prices/upsert", testUpsert},
+	{"prices/export", testExport},
+}
+
+// TestRepository прогоняет все проверки, вызывая newRepo перед каждой.
+// Возвращает nil или объединенную ошибку с названиями непройденных проверок
+func TestRepository(ctx context.Context, newRepo func() (repository.Repository, error)) error {
+	var errs []error
+	for _, c := range Cases {
+		if err := TestCase(ctx, c, newRepo); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// TestCase прогоняет одну проверку на хранилище из newRepo. Обертки в _test.go пакетов реализаций
+// вызывают ее из t.Run, чтобы каждая проверка была отдельным подтестом
+func TestCase(ctx context.Context, c Case, newRepo func() (repository.Repository, error)) error {
+	repo, err := newRepo()
+	if err != nil {
+		return fmt.Errorf("создание хранилища: %w", err)
+	}
+	run := rand.Intn(1_000_000)
+	sym := func(name string) string { return fmt.Sprintf("%s%06d", name, run) }
+	return c.Run(ctx, repo, sym)
+}
+
+// base - точка отсчета времени для рядов цен, с точностью до секунды,
+// чтобы сравнение не зависело от точности хранения времени
+var base = time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+
+func addCurrency(ctx context.Context, repo repository.Repository, symbol string) (models.Currency, error) {
+	currency := models.Currency{Symbol: symbol}
+	if err := repo.Create(ctx, &currency); err != nil {
+		return currency, fmt.Errorf("Create(%s): %w", symbol, err)
+	}
+	if currency.ID == 0 {
+		return currency, fmt.Errorf("Create(%s) не заполнил ID", symbol)
+	}
+	return currency, nil
+}
+
+// addSeries сохраняет цены price[i] в моменты base + i минут
+func addSeries(ctx context.Context, repo repository.Repository, currency models.Currency, prices ...float64) error {
+	for i, p := range prices {
+		price := models.Price{CurrencyID: currency.ID, Price: p, Timestamp: base.Add(time.Duration(i) * time.Minute), Source: "test"}
+		if err := repo.SavePrice(ctx, &price); err != nil {
+			return fmt.Errorf("SavePrice: %w", err)
+		}
+		if price.ID == 0 {
+			return fmt.Errorf("SavePrice не заполнил ID")
+		}
+	}
+	return nil
+}
+
+func containsSymbol(currencies []models.Currency, symbol string) bool {
+	for _, c := range currencies {
+		if c.Symbol == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+func checkPoint(name string, got *repository.Point, price float64, at time.Time) error {
+	if got == nil {
+		return fmt.Errorf("%s: точки нет, ожидалась %v на %s", name, price, at)
+	}
+	if !got.Price.Equal(decimal.NewFromFloat(price)) || !got.Timestamp.Equal(at) || got.Source != "test" {
+		return fmt.Errorf("%s: получено %s на %s (%s), ожидалось %v на %s", name, got.Price, got.Timestamp, got.Source, price, at)
+	}
+	if got.Timestamp.Location() != time.UTC {
+		return fmt.Errorf("%s: время не в UTC: %s", name, got.Timestamp)
+	}
+	return nil
+}
+
+func testCreateFind(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	symbol := sym("A")
+	if _, err := repo.FindBySymbol(ctx, symbol); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("FindBySymbol несуществующей валюты: %v, ожидалось ErrNotFound", err)
+	}
+
+	created, err := addCurrency(ctx, repo, symbol)
+	if err != nil {
+		return err
+	}
+	found, err := repo.FindBySymbol(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("FindBySymbol: %w", err)
+	}
+	if found.ID != created.ID || found.Symbol != symbol || found.DeletedAt.Valid {
+		return fmt.Errorf("FindBySymbol вернул %+v, ожидалась активная валюта с ID %d", found, created.ID)
+	}
+
+	duplicate := models.Currency{Symbol: symbol}
+	if err := repo.Create(ctx, &duplicate); !errors.Is(err, repository.ErrConflict) {
+		return fmt.Errorf("повторный Create: %v, ожидалось ErrConflict", err)
+	}
+
+	active, err := repo.Active(ctx)
+	if err != nil {
+		return fmt.Errorf("Active: %w", err)
+	}
+	if !containsSymbol(active, symbol) {
+		return fmt.Errorf("Active не содержит %s", symbol)
+	}
+	return nil
+}
+
+func testDeleteRestore(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	bySymbol, byID := sym("D"), sym("E")
+	if _, err := addCurrency(ctx, repo, bySymbol); err != nil {
+		return err
+	}
+	second, err := addCurrency(ctx, repo, byID)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.Delete(ctx, 0, bySymbol); err != nil {
+		return fmt.Errorf("Delete по символу: %w", err)
+	}
+	if err := repo.Delete(ctx, second.ID, ""); err != nil {
+		return fmt.Errorf("Delete по id: %w", err)
+	}
+	if err := repo.Delete(ctx, 0, bySymbol); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("повторный Delete: %v, ожидалось ErrNotFound", err)
+	}
+
+	deleted, err := repo.FindBySymbol(ctx, bySymbol)
+	if err != nil {
+		return fmt.Errorf("FindBySymbol удаленной валюты: %w", err)
+	}
+	if !deleted.DeletedAt.Valid {
+		return fmt.Errorf("FindBySymbol вернул удаленную валюту без пометки удаления")
+	}
+	active, err := repo.Active(ctx)
+	if err != nil {
+		return fmt.Errorf("Active: %w", err)
+	}
+	if containsSymbol(active, bySymbol) || containsSymbol(active, byID) {
+		return fmt.Errorf("Active содержит удаленные валюты")
+	}
+
+	if err := repo.Restore(ctx, &deleted); err != nil {
+		return fmt.Errorf("Restore: %w", err)
+	}
+	if deleted.DeletedAt.Valid {
+		return fmt.Errorf("Restore не снял пометку удаления с переданной валюты")
+	}
+	restored, err := repo.FindBySymbol(ctx, bySymbol)
+	if err != nil || restored.DeletedAt.Valid || restored.ID != deleted.ID {
+		return fmt.Errorf("после Restore FindBySymbol вернул %+v, %v", restored, err)
+	}
+	return nil
+}
+
+func testTracking(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	symbol := sym("T")
+	if _, err := addCurrency(repository.WithActor(ctx, "alice"), repo, symbol); err != nil {
+		return err
+	}
+	if err := repo.Delete(repository.WithActor(ctx, "bob"), 0, symbol); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+	currency, err := repo.FindBySymbol(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("FindBySymbol: %w", err)
+	}
+	if err := repo.Restore(ctx, &currency); err != nil {
+		return fmt.Errorf("Restore: %w", err)
+	}
+
+	// Добавление и удаление - закрытый период с авторами, восстановление без автора - открытый период system
+	periods, err := repo.TrackingPeriods(ctx, []string{symbol, sym("X")})
+	if err != nil {
+		return fmt.Errorf("TrackingPeriods: %w", err)
+	}
+	if _, ok := periods[sym("X")]; ok || len(periods[symbol]) != 2 {
+		return fmt.Errorf("TrackingPeriods вернул %v, ожидалось два периода %s", periods, symbol)
+	}
+	first, second := periods[symbol][0], periods[symbol][1]
+	if first.StartedBy != "alice" || first.StoppedAt == nil || first.StoppedBy != "bob" {
+		return fmt.Errorf("первый период %+v, ожидался закрытый alice - bob", first)
+	}
+	if second.StartedBy != repository.ActorSystem || second.StoppedAt != nil || second.StartedAt.Before(*first.StoppedAt) {
+		return fmt.Errorf("второй период %+v, ожидался открытый после первого", second)
+	}
+	if !second.Covers(second.StartedAt) || first.Covers(*first.StoppedAt) {
+		return fmt.Errorf("Covers: начало периода должно входить в период, а остановка - нет")
+	}
+	return nil
+}
+
+func testStatus(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	symbol := sym("P")
+	currency, err := addCurrency(ctx, repo, symbol)
+	if err != nil {
+		return err
+	}
+	if currency.Status != models.CurrencyActive {
+		return fmt.Errorf("Create: состояние %q, ожидалось active", currency.Status)
+	}
+
+	paused := repository.WithActor(ctx, "carol")
+	if err := repo.SetStatus(paused, currency.ID, models.CurrencyActive, models.CurrencyPaused); err != nil {
+		return fmt.Errorf("SetStatus active -> paused: %w", err)
+	}
+	if err := repo.SetStatus(ctx, currency.ID, models.CurrencyActive, models.CurrencyPaused); !errors.Is(err, repository.ErrConflict) {
+		return fmt.Errorf("повторная приостановка: %v, ожидалось ErrConflict", err)
+	}
+	found, err := repo.FindBySymbol(ctx, symbol)
+	if err != nil || found.Status != models.CurrencyPaused || found.DeletedAt.Valid {
+		return fmt.Errorf("после приостановки FindBySymbol вернул %+v, %v", found, err)
+	}
+	active, err := repo.Active(ctx)
+	if err != nil {
+		return fmt.Errorf("Active: %w", err)
+	}
+	if containsSymbol(active, symbol) {
+		return fmt.Errorf("Active содержит приостановленную валюту")
+	}
+
+	if err := repo.SetStatus(ctx, currency.ID, models.CurrencyPaused, models.CurrencyActive); err != nil {
+		return fmt.Errorf("SetStatus paused -> active: %w", err)
+	}
+	if active, err = repo.Active(ctx); err != nil || !containsSymbol(active, symbol) {
+		return fmt.Errorf("после возобновления Active не содержит валюту: %v", err)
+	}
+	periods, err := repo.TrackingPeriods(ctx, []string{symbol})
+	if err != nil {
+		return fmt.Errorf("TrackingPeriods: %w", err)
+	}
+	if got := periods[symbol]; len(got) != 2 || got[0].StoppedBy != "carol" || got[1].StoppedAt != nil {
+		return fmt.Errorf("TrackingPeriods вернул %+v, ожидались период до приостановки carol и открытый", got)
+	}
+
+	if err := repo.Delete(ctx, currency.ID, ""); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+	if found, err = repo.FindBySymbol(ctx, symbol); err != nil || found.Status != models.CurrencyArchived {
+		return fmt.Errorf("после Delete FindBySymbol вернул %+v, %v, ожидалось archived", found, err)
+	}
+	if err := repo.SetStatus(ctx, currency.ID, models.CurrencyArchived, models.CurrencyActive); !errors.Is(err, repository.ErrConflict) {
+		return fmt.Errorf("SetStatus удаленной валюты: %v, ожидалось ErrConflict", err)
+	}
+	if err := repo.Restore(ctx, &found); err != nil || found.Status != models.CurrencyActive {
+		return fmt.Errorf("Restore: состояние %q, %v, ожидалось active", found.Status, err)
+	}
+	return nil
+}
+
+func testStats(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	withPrices, empty, removed := sym("S"), sym("T"), sym("U")
+	currency, err := addCurrency(ctx, repo, withPrices)
+	if err != nil {
+		return err
+	}
+	if err := addSeries(ctx, repo, currency, 1, 2, 3); err != nil {
+		return err
+	}
+	if _, err := addCurrency(ctx, repo, empty); err != nil {
+		return err
+	}
+	if _, err := addCurrency(ctx, repo, removed); err != nil {
+		return err
+	}
+	if err := repo.Delete(ctx, 0, removed); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+
+	stats, err := repo.Stats(ctx)
+	if err != nil {
+		return fmt.Errorf("Stats: %w", err)
+	}
+	seen := map[string]repository.CurrencyStats{}
+	for _, s := range stats {
+		seen[s.Currency.Symbol] = s
+	}
+
+	s, ok := seen[withPrices]
+	if !ok || s.PointCount != 3 {
+		return fmt.Errorf("Stats для %s: %+v, ожидалось 3 точки", withPrices, s)
+	}
+	if err := checkPoint("последняя точка", s.Last, 3, base.Add(2*time.Minute)); err != nil {
+		return err
+	}
+	if s, ok := seen[empty]; !ok || s.PointCount != 0 || s.Last != nil {
+		return fmt.Errorf("Stats для валюты без цен: %+v", s)
+	}
+	if s, ok := seen[removed]; !ok || !s.Currency.DeletedAt.Valid {
+		return fmt.Errorf("Stats не вернул удаленную валюту с пометкой удаления")
+	}
+	return nil
+}
+
+func testNeighbors(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	symbol := sym("N")
+	currency, err := addCurrency(ctx, repo, symbol)
+	if err != nil {
+		return err
+	}
+	// Точки в base, base+1m, base+2m
+	if err := addSeries(ctx, repo, currency, 10, 20, 30); err != nil {
+		return err
+	}
+
+	queries := []repository.PriceQuery{
+		{Symbol: symbol, At: base.Add(90 * time.Second)},                         // между второй и третьей
+		{Symbol: symbol, At: base.Add(time.Minute)},                              // точно на второй
+		{Symbol: symbol, At: base.Add(-time.Hour)},                               // раньше всех
+		{Symbol: symbol, At: base.Add(time.Hour).In(time.FixedZone("", 3*3600))}, // позже всех, не в UTC
+	}
+	result, err := repo.Neighbors(ctx, queries)
+	if err != nil {
+		return fmt.Errorf("Neighbors: %w", err)
+	}
+	if len(result) != len(queries) {
+		return fmt.Errorf("Neighbors вернул %d результатов на %d запросов", len(result), len(queries))
+	}
+	for i, r := range result {
+		if !r.CurrencyFound {
+			return fmt.Errorf("запрос %d: валюта не найдена", i)
+		}
+	}
+
+	checks := []error{
+		checkPoint("между точками, до", result[0].Before, 20, base.Add(time.Minute)),
+		checkPoint("между точками, после", result[0].After, 30, base.Add(2*time.Minute)),
+		checkPoint("точное совпадение, до", result[1].Before, 20, base.Add(time.Minute)),
+		checkPoint("точное совпадение, после", result[1].After, 20, base.Add(time.Minute)),
+		checkPoint("раньше всех, после", result[2].After, 10, base),
+		checkPoint("позже всех, до", result[3].Before, 30, base.Add(2*time.Minute)),
+	}
+	if result[2].Before != nil {
+		checks = append(checks, fmt.Errorf("раньше всех: найдена точка до"))
+	}
+	if result[3].After != nil {
+		checks = append(checks, fmt.Errorf("позже всех: найдена точка после"))
+	}
+	return errors.Join(checks...)
+}
+
+func testNeighborsMissing(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	empty, removed := sym("M"), sym("R")
+	if _, err := addCurrency(ctx, repo, empty); err != nil {
+		return err
+	}
+	currency, err := addCurrency(ctx, repo, removed)
+	if err != nil {
+		return err
+	}
+	if err := addSeries(ctx, repo, currency, 1); err != nil {
+		return err
+	}
+	if err := repo.Delete(ctx, currency.ID, ""); err != nil {
+		return fmt.Errorf("Delete: %w", err)
+	}
+
+	result, err := repo.Neighbors(ctx, []repository.PriceQuery{
+		{Symbol: sym("X"), At: base},
+		{Symbol: empty, At: base},
+		{Symbol: removed, At: base},
+	})
+	if err != nil {
+		return fmt.Errorf("Neighbors: %w", err)
+	}
+	if result[0].CurrencyFound {
+		return fmt.Errorf("несуществующая валюта помечена найденной")
+	}
+	if !result[1].CurrencyFound || result[1].Before != nil || result[1].After != nil {
+		return fmt.Errorf("валюта без цен: %+v, ожидалась найденная валюта без точек", result[1])
+	}
+	if !result[2].CurrencyFound || result[2].Before == nil {
+		return fmt.Errorf("архивная валюта: %+v, ожидалась найденная валюта с историей", result[2])
+	}
+	points, err := repo.Range(ctx, removed, base, base.Add(time.Hour), 10)
+	if err != nil || len(points) != 1 {
+		return fmt.Errorf("Range архивной валюты: %d точек, %v, ожидалась 1", len(points), err)
+	}
+
+	if result, err := repo.Neighbors(ctx, nil); err != nil || len(result) != 0 {
+		return fmt.Errorf("Neighbors без запросов: %v, %v", result, err)
+	}
+	return nil
+}
+
+func testRange(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	symbol := sym("G")
+	currency, err := addCurrency(ctx, repo, symbol)
+	if err != nil {
+		return err
+	}
+	if err := addSeries(ctx, repo, currency, 1, 2, 3, 4, 5); err != nil {
+		return err
+	}
+
+	// [base+1m, base+4m) - вторая, третья и четвертая точки
+	points, err := repo.Range(ctx, symbol, base.Add(time.Minute), base.Add(4*time.Minute), 100)
+	if err != nil {
+		return fmt.Errorf("Range: %w", err)
+	}
+	if len(points) != 3 {
+		return fmt.Errorf("Range вернул %d точек, ожидалось 3", len(points))
+	}
+	for i, p := range points {
+		if err := checkPoint(fmt.Sprintf("точка %d", i), &p, float64(i+2), base.Add(time.Duration(i+1)*time.Minute)); err != nil {
+			return err
+		}
+	}
+
+	if limited, err := repo.Range(ctx, symbol, base, base.Add(time.Hour), 2); err != nil || len(limited) != 2 {
+		return fmt.Errorf("Range с limit 2 вернул %d точек, %v", len(limited), err)
+	}
+	if _, err := repo.Range(ctx, sym("X"), base, base.Add(time.Hour), 10); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("Range несуществующей валюты: %v, ожидалось ErrNotFound", err)
+	}
+	return nil
+}
+
+func testPrecision(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	symbol := sym("P")
+	currency, err := addCurrency(ctx, repo, symbol)
+	if err != nil {
+		return err
+	}
+	if err := addSeries(ctx, repo, currency, 97234.12345678, 0.00000001); err != nil {
+		return err
+	}
+
+	result, err := repo.Neighbors(ctx, []repository.PriceQuery{{Symbol: symbol, At: base}, {Symbol: symbol, At: base.Add(time.Minute)}})
+	if err != nil {
+		return fmt.Errorf("Neighbors: %w", err)
+	}
+	return errors.Join(
+		checkPoint("8 знаков после запятой", result[0].Before, 97234.12345678, base),
+		checkPoint("минимальный шаг цены", result[1].Before, 0.00000001, base.Add(time.Minute)),
+	)
+}
+
+func testUpsert(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	symbol := sym("U")
+	currency, err := addCurrency(ctx, repo, symbol)
+	if err != nil {
+		return err
+	}
+	if err := addSeries(ctx, repo, currency, 1, 2); err != nil {
+		return err
+	}
+
+	// Повторная точка на base заменяет первую, не добавляя новую
+	first := models.Price{CurrencyID: currency.ID, Price: 10, Timestamp: base, Source: "test"}
+	if err := repo.SavePrice(ctx, &first); err != nil {
+		return fmt.Errorf("SavePrice: %w", err)
+	}
+	second := models.Price{CurrencyID: currency.ID, Price: 11, Timestamp: base, Source: "test"}
+	if err := repo.SavePrice(ctx, &second); err != nil {
+		return fmt.Errorf("повторный SavePrice: %w", err)
+	}
+	if second.ID == 0 || second.ID != first.ID {
+		return fmt.Errorf("повторная точка получила ID %d, ожидался %d", second.ID, first.ID)
+	}
+
+	points, err := repo.Range(ctx, symbol, base, base.Add(time.Hour), 100)
+	if err != nil {
+		return fmt.Errorf("Range: %w", err)
+	}
+	if len(points) != 2 {
+		return fmt.Errorf("Range вернул %d точек, ожидалось 2", len(points))
+	}
+	return errors.Join(
+		checkPoint("замененная точка", &points[0], 11, base),
+		checkPoint("вторая точка", &points[1], 2, base.Add(time.Minute)),
+	)
+}
+
+func testExport(ctx context.Context, repo repository.Repository, sym func(string) string) error {
+	first, second := sym("E"), sym("F")
+	for i, symbol := range []string{first, second} {
+		currency, err := addCurrency(ctx, repo, symbol)
+		if err != nil {
+			return err
+		}
+		if err := addSeries(ctx, repo, currency, float64(10*i+1), float64(10*i+2), float64(10*i+3)); err != nil {
+			return err
+		}
+	}
+
+	// Валюты идут по порядку создания, внутри валюты - по возрастанию времени; base+2m не входит
+	var got []repository.ExportPoint
+	query := repository.ExportQuery{Symbols: []string{second, first}, From: base, To: base.Add(2 * time.Minute)}
+	err := repo.Export(ctx, query, func(p repository.ExportPoint) error {
+		got = append(got, p)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Export: %w", err)
+	}
+	if len(got) != 4 {
+		return fmt.Errorf("Export вернул %d точек, ожидалось 4", len(got))
+	}
+	want := []struct {
+		symbol string
+		price  float64
+		at     time.Time
+	}{
+		{first, 1, base}, {first, 2, base.Add(time.Minute)},
+		{second, 11, base}, {second, 12, base.Add(time.Minute)},
+	}
+	for i, w := range want {
+		if got[i].Symbol != w.symbol || got[i].CurrencyID == 0 {
+			return fmt.Errorf("точка %d: валюта %s (%d), ожидалась %s", i, got[i].Symbol, got[i].CurrencyID, w.symbol)
+		}
+		if err := checkPoint(fmt.Sprintf("точка %d", i), &got[i].Point, w.price, w.at); err != nil {
+			return err
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.Export(ctx, query, func(repository.ExportPoint) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		return fmt.Errorf("Export не прервался на ошибке получателя: %v после %d точек", err, calls)
+	}
+	query.Symbols = []string{first, sym("X")}
+	if err := repo.Export(ctx, query, func(repository.ExportPoint) error { return nil }); !errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("Export несуществующей валюты: %v, ожидалось ErrNotFound", err)
+	}
+	return nil
+}
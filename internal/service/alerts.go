@@ -0,0 +1,382 @@
+package services
+
+import (
+	"affarm/config"
+	"affarm/internal/models"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Заголовки запроса к вебхуку
+const (
+	SignatureHeader = "X-Affarm-Signature" // sha256=<hex HMAC-SHA256 от "<timestamp>.<тело>">
+	TimestampHeader = "X-Affarm-Timestamp" // Unix-секунды момента отправки
+)
+
+// alertSweepInterval - как часто проверяются отложенные доставки
+const alertSweepInterval = 5 * time.Second
+
+// AlertPayload - тело уведомления о срабатывании алерта
+type AlertPayload struct {
+	AlertID       uint      `json:"alert_id"`
+	Symbol        string    `json:"symbol"`
+	Kind          string    `json:"kind"`
+	Threshold     float64   `json:"threshold"`
+	Price         float64   `json:"price"`
+	Timestamp     time.Time `json:"timestamp"`
+	ChangePercent *float64  `json:"change_percent,omitempty"`
+	WindowSec     int       `json:"window_sec,omitempty"`
+}
+
+// AlertService проверяет правила алертов на каждой новой цене и доставляет
+// уведомления на вебхуки с подписью, повторами и журналом доставки
+type AlertService struct {
+	db          *gorm.DB
+	webhooks    map[string]config.WebhookConfig
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+
+	queue       chan uint // id доставок к отправке
+	stopChannel chan struct{}
+	wg          sync.WaitGroup
+
+	mu       sync.Mutex
+	inflight map[uint]bool
+}
+
+// NewAlertService - конструктор сервиса алертов
+func NewAlertService(db *gorm.DB, cfg config.AlertsConfig) *AlertService {
+	if db == nil {
+		log.Panic("ошибка, подключение к базе не существует")
+	}
+
+	webhooks := make(map[string]config.WebhookConfig, len(cfg.Webhooks))
+	for _, webhook := range cfg.Webhooks {
+		webhooks[webhook.Name] = webhook
+	}
+
+	return &AlertService{
+		db:          db,
+		webhooks:    webhooks,
+		client:      &http.Client{Timeout: time.Duration(cfg.TimeoutSec) * time.Second},
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     time.Duration(cfg.RetryBackoffMs) * time.Millisecond,
+		queue:       make(chan uint, 1024),
+		stopChannel: make(chan struct{}),
+		inflight:    make(map[uint]bool),
+	}
+}
+
+// HasWebhook сообщает, настроен ли вебхук с таким именем
+func (s *AlertService) HasWebhook(name string) bool {
+	_, ok := s.webhooks[name]
+	return ok
+}
+
+// Start запускает отправку уведомлений. Доставки, оставшиеся pending после перезапуска, будут досланы
+func (s *AlertService) Start() {
+	s.wg.Add(2)
+	go s.sendLoop()
+	go s.sweepLoop()
+	log.Printf("Сервис алертов запущен, вебхуков: %d", len(s.webhooks))
+}
+
+// Stop останавливает отправку и дожидается завершения текущей доставки
+func (s *AlertService) Stop() {
+	close(s.stopChannel)
+	s.wg.Wait()
+}
+
+// OnPrice проверяет правила валюты на новой цене, реализует PriceListener
+func (s *AlertService) OnPrice(currency models.Currency, price models.Price) {
+	var rules []models.AlertRule
+	if err := s.db.Where("currency_id = ? AND enabled", currency.ID).Find(&rules).Error; err != nil {
+		log.Printf("ошибка загрузки правил алертов для %s: %v", currency.Symbol, err)
+		return
+	}
+
+	for _, rule := range rules {
+		payload, triggered, err := s.evaluate(rule, currency, price)
+		if err != nil {
+			log.Printf("ошибка проверки алерта %d: %v", rule.ID, err)
+			continue
+		}
+
+		updates := map[string]interface{}{}
+		if keepsLastPrice(rule, price) {
+			updates["last_price"] = price.Price
+		}
+		if triggered {
+			updates["last_triggered_at"] = price.Timestamp
+		}
+		if len(updates) == 0 {
+			continue
+		}
+
+		var deliveries []models.AlertDelivery
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&rule).Updates(updates).Error; err != nil {
+				return err
+			}
+			if !triggered {
+				return nil
+			}
+			deliveries, err = s.createDeliveries(tx, rule, payload)
+			return err
+		})
+		if err != nil {
+			log.Printf("ошибка сохранения срабатывания алерта %d: %v", rule.ID, err)
+			continue
+		}
+
+		if triggered {
+			log.Printf("Сработал алерт %d (%s %s %v) при цене %f", rule.ID, currency.Symbol, rule.Kind, rule.Threshold, price.Price)
+		}
+		for _, delivery := range deliveries {
+			s.enqueue(delivery.ID)
+		}
+	}
+}
+
+// evaluate проверяет одно правило. Пересечение уровня определяется по предыдущей цене правила,
+// изменение - по последней точке ряда не позже начала окна
+func (s *AlertService) evaluate(rule models.AlertRule, currency models.Currency, price models.Price) (AlertPayload, bool, error) {
+	payload := AlertPayload{
+		AlertID:   rule.ID,
+		Symbol:    currency.Symbol,
+		Kind:      rule.Kind,
+		Threshold: rule.Threshold,
+		Price:     price.Price,
+		Timestamp: price.Timestamp,
+	}
+
+	if inCooldown(rule, price.Timestamp) {
+		return payload, false, nil
+	}
+
+	switch rule.Kind {
+	case models.AlertAbove:
+		crossed := price.Price >= rule.Threshold && (rule.LastPrice == nil || *rule.LastPrice < rule.Threshold)
+		return payload, crossed, nil
+	case models.AlertBelow:
+		crossed := price.Price <= rule.Threshold && (rule.LastPrice == nil || *rule.LastPrice > rule.Threshold)
+		return payload, crossed, nil
+	case models.AlertChange:
+		var base models.Price
+		err := s.db.Where("currency_id = ? AND timestamp <= ?", currency.ID, price.Timestamp.Add(-time.Duration(rule.WindowSec)*time.Second)).
+			Order("timestamp DESC").
+			First(&base).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && base.Price == 0) {
+			return payload, false, nil
+		}
+		if err != nil {
+			return payload, false, err
+		}
+		change := (price.Price/base.Price - 1) * 100
+		payload.ChangePercent = &change
+		payload.WindowSec = rule.WindowSec
+		return payload, math.Abs(change) >= rule.Threshold, nil
+	default:
+		return payload, false, fmt.Errorf("неизвестный тип правила %q", rule.Kind)
+	}
+}
+
+// inCooldown сообщает, что с последнего срабатывания правила к моменту at прошло меньше cooldown_sec
+func inCooldown(rule models.AlertRule, at time.Time) bool {
+	return rule.LastTriggeredAt != nil && at.Sub(*rule.LastTriggeredAt) < time.Duration(rule.CooldownSec)*time.Second
+}
+
+// keepsLastPrice сообщает, запоминается ли цена как предыдущая для следующей проверки пересечения.
+// Во время паузы после срабатывания запоминаются только цены по другую сторону уровня: возврат цены
+// за уровень не теряется, и повторное пересечение внутри паузы срабатывает первой ценой после нее
+func keepsLastPrice(rule models.AlertRule, price models.Price) bool {
+	if !inCooldown(rule, price.Timestamp) {
+		return true
+	}
+	switch rule.Kind {
+	case models.AlertAbove:
+		return price.Price < rule.Threshold
+	case models.AlertBelow:
+		return price.Price > rule.Threshold
+	default:
+		return true
+	}
+}
+
+// createDeliveries записывает в журнал по доставке на каждый вебхук правила
+func (s *AlertService) createDeliveries(tx *gorm.DB, rule models.AlertRule, payload AlertPayload) ([]models.AlertDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if rule.Webhook != "" {
+		names = []string{rule.Webhook}
+	} else {
+		for name := range s.webhooks {
+			names = append(names, name)
+		}
+	}
+
+	deliveries := make([]models.AlertDelivery, 0, len(names))
+	for _, name := range names {
+		deliveries = append(deliveries, models.AlertDelivery{
+			AlertRuleID:   rule.ID,
+			Webhook:       name,
+			Payload:       string(body),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+	return deliveries, tx.Create(&deliveries).Error
+}
+
+func (s *AlertService) enqueue(id uint) {
+	s.mu.Lock()
+	if s.inflight[id] {
+		s.mu.Unlock()
+		return
+	}
+	s.inflight[id] = true
+	s.mu.Unlock()
+
+	select {
+	case s.queue <- id:
+	default:
+		// Очередь переполнена - доставку подберет sweepLoop
+		s.mu.Lock()
+		delete(s.inflight, id)
+		s.mu.Unlock()
+	}
+}
+
+func (s *AlertService) sweepLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(alertSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			var ids []uint
+			err := s.db.Model(&models.AlertDelivery{}).
+				Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+				Order("id").Limit(cap(s.queue)).
+				Pluck("id", &ids).Error
+			if err != nil {
+				log.Printf("ошибка поиска отложенных доставок: %v", err)
+				continue
+			}
+			for _, id := range ids {
+				s.enqueue(id)
+			}
+		case <-s.stopChannel:
+			return
+		}
+	}
+}
+
+func (s *AlertService) sendLoop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case id := <-s.queue:
+			s.deliver(id)
+			s.mu.Lock()
+			delete(s.inflight, id)
+			s.mu.Unlock()
+		case <-s.stopChannel:
+			return
+		}
+	}
+}
+
+// deliver делает одну попытку доставки и записывает результат в журнал.
+// При ошибке следующая попытка откладывается с экспоненциальной паузой
+func (s *AlertService) deliver(id uint) {
+	var delivery models.AlertDelivery
+	if err := s.db.First(&delivery, id).Error; err != nil {
+		log.Printf("ошибка загрузки доставки %d: %v", id, err)
+		return
+	}
+	if delivery.Status != models.DeliveryPending {
+		return
+	}
+
+	code, err := s.send(delivery)
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= s.maxAttempts {
+			delivery.Status = models.DeliveryFailed
+			log.Printf("доставка %d на вебхук %s не удалась после %d попыток: %v", delivery.ID, delivery.Webhook, delivery.Attempts, err)
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(s.backoff << (delivery.Attempts - 1))
+		}
+	}
+
+	if err := s.db.Save(&delivery).Error; err != nil {
+		log.Printf("ошибка сохранения доставки %d: %v", delivery.ID, err)
+	}
+}
+
+func (s *AlertService) send(delivery models.AlertDelivery) (int, error) {
+	webhook, ok := s.webhooks[delivery.Webhook]
+	if !ok {
+		return 0, fmt.Errorf("вебхук %q не настроен", delivery.Webhook)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("неверный статус код: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign возвращает hex HMAC-SHA256 от "<timestamp>.<body>". Получатель проверяет подпись тем же способом
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
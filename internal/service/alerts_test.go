@@ -0,0 +1,103 @@
+package services
+
+import (
+	"affarm/config"
+	"affarm/internal/database"
+	"affarm/internal/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestAlerts(t *testing.T, webhooks ...config.WebhookConfig) *AlertService {
+	t.Helper()
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "alerts.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return NewAlertService(db, config.AlertsConfig{Webhooks: webhooks, MaxAttempts: 1, TimeoutSec: 5, RetryBackoffMs: 10})
+}
+
+func TestSign(t *testing.T) {
+	// Значение посчитано независимо: HMAC-SHA256 с ключом "secret" от "1700000000.{"alert_id":1}"
+	const want = "52b8e9e42cd4854dddabf1f4b6e3840078d4e07673b04e20419b480c5a5fd150"
+	if got := Sign("secret", "1700000000", []byte(`{"alert_id":1}`)); got != want {
+		t.Fatalf("подпись %s, ожидалось %s", got, want)
+	}
+}
+
+// TestSendSignature проверяет подпись запроса так, как ее проверяет получатель:
+// по заголовку времени и телу запроса
+func TestSendSignature(t *testing.T) {
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	alerts := newTestAlerts(t, config.WebhookConfig{Name: "hook", URL: server.URL, Secret: "secret"})
+	payload := `{"alert_id":7,"symbol":"BTC"}`
+	if _, err := alerts.send(models.AlertDelivery{Webhook: "hook", Payload: payload}); err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != payload {
+		t.Fatalf("тело %q, ожидалось %q", body, payload)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(header.Get(TimestampHeader) + "." + payload))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); header.Get(SignatureHeader) != want {
+		t.Fatalf("подпись %q, ожидалось %q", header.Get(SignatureHeader), want)
+	}
+}
+
+// TestAlertCrossingCooldown проверяет правило above на последовательности цен: пересечение во время
+// паузы не срабатывает сразу, но и не теряется, а цена, не уходившая за уровень, не срабатывает повторно
+func TestAlertCrossingCooldown(t *testing.T) {
+	alerts := newTestAlerts(t, config.WebhookConfig{Name: "hook", URL: "http://127.0.0.1:0"})
+	currency := models.Currency{Symbol: "BTC", Status: models.CurrencyActive}
+	if err := alerts.db.Create(&currency).Error; err != nil {
+		t.Fatal(err)
+	}
+	rule := models.AlertRule{CurrencyID: currency.ID, Kind: models.AlertAbove, Threshold: 100, CooldownSec: 60, Enabled: true}
+	if err := alerts.db.Create(&rule).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	steps := []struct {
+		offset    time.Duration
+		price     float64
+		triggered int64 // срабатываний к этому шагу
+	}{
+		{0, 99, 0},
+		{10 * time.Second, 101, 1},  // пересечение
+		{20 * time.Second, 98, 1},   // пауза: цена вернулась под уровень
+		{30 * time.Second, 102, 1},  // пауза: повторное пересечение откладывается
+		{80 * time.Second, 103, 2},  // после паузы срабатывает отложенное пересечение
+		{150 * time.Second, 104, 2}, // цена не уходила под уровень: нового пересечения нет
+	}
+	for _, step := range steps {
+		alerts.OnPrice(currency, models.Price{Price: step.price, Timestamp: start.Add(step.offset)})
+		var triggered int64
+		if err := alerts.db.Model(&models.AlertDelivery{}).Count(&triggered).Error; err != nil {
+			t.Fatal(err)
+		}
+		if triggered != step.triggered {
+			t.Fatalf("после цены %v через %s срабатываний %d, ожидалось %d", step.price, step.offset, triggered, step.triggered)
+		}
+	}
+}
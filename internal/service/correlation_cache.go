@@ -0,0 +1,90 @@
+package services
+
+import (
+	"affarm/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CorrelationKey - параметры расчета корреляций, по которым кешируется результат
+type CorrelationKey struct {
+	Symbols   string // отсортированные символы через запятую
+	Benchmark string
+	From      time.Time
+	To        time.Time
+	Interval  time.Duration
+}
+
+type correlationEntry struct {
+	symbols []string
+	value   any
+	created time.Time
+}
+
+// CorrelationCache - кеш результатов корреляций по окнам.
+// Запись сбрасывается, когда сохраняется цена одной из ее валют внутри окна [From, To)
+type CorrelationCache struct {
+	mu      sync.Mutex
+	limit   int
+	entries map[CorrelationKey]correlationEntry
+}
+
+// NewCorrelationCache - конструктор кеша, limit - максимальное количество записей
+func NewCorrelationCache(limit int) *CorrelationCache {
+	return &CorrelationCache{limit: limit, entries: make(map[CorrelationKey]correlationEntry)}
+}
+
+// Get возвращает закешированный результат
+func (c *CorrelationCache) Get(key CorrelationKey) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	return entry.value, ok
+}
+
+// Put сохраняет результат. При переполнении вытесняется самая старая запись
+func (c *CorrelationCache) Put(key CorrelationKey, symbols []string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.limit {
+		var oldestKey CorrelationKey
+		var oldest time.Time
+		for k, entry := range c.entries {
+			if oldest.IsZero() || entry.created.Before(oldest) {
+				oldestKey, oldest = k, entry.created
+			}
+		}
+		delete(c.entries, oldestKey)
+	}
+	c.entries[key] = correlationEntry{symbols: symbols, value: value, created: time.Now()}
+}
+
+// OnPrice сбрасывает записи, на которые влияет новая цена, реализует PriceListener
+func (c *CorrelationCache) OnPrice(currency models.Currency, price models.Price) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if price.Timestamp.Before(key.From) || !price.Timestamp.Before(key.To) {
+			continue
+		}
+		if slices.Contains(entry.symbols, currency.Symbol) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// Forget сбрасывает все записи с валютой symbol, например после удаления или загрузки ее истории
+func (c *CorrelationCache) Forget(symbol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if slices.Contains(entry.symbols, symbol) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"context"
+	"errors"
+	"log"
+)
+
+// CurrencyService - управление списком отслеживаемых валют, общее для HTTP и gRPC
+type CurrencyService struct {
+	repo  repository.Currencies
+	cache *PriceCache
+}
+
+// NewCurrencyService - конструктор сервиса валют
+func NewCurrencyService(repo repository.Currencies, cache *PriceCache) *CurrencyService {
+	return &CurrencyService{repo: repo, cache: cache}
+}
+
+// Add добавляет валюту в отслеживание. Ранее удаленная валюта восстанавливается,
+// тогда restored = true. Если валюта уже отслеживается, возвращается ErrCurrencyExists
+func (s *CurrencyService) Add(ctx context.Context, symbol string) (currency models.Currency, restored bool, err error) {
+	if err := ValidateSymbol(symbol); err != nil {
+		return currency, false, err
+	}
+
+	// Поиск существующей валюты с тем же символом, включая удаленные
+	currency, err = s.repo.FindBySymbol(ctx, symbol)
+	switch {
+	case err == nil && currency.DeletedAt.Valid: // Валюта была удалена
+		if err := s.repo.Restore(ctx, &currency); err != nil {
+			return currency, false, err
+		}
+		s.cache.Track(currency)
+		log.Printf("Валюта для отслеживания восстановлена: %s", symbol)
+		return currency, true, nil
+	case err == nil: // Валюта уже существует и не удалена
+		return currency, false, ErrCurrencyExists
+	case errors.Is(err, repository.ErrNotFound): // Валюта раньше не существовала
+		currency = models.Currency{Symbol: symbol}
+		err := s.repo.Create(ctx, &currency)
+		if errors.Is(err, repository.ErrConflict) { // Валюту успел добавить параллельный запрос
+			return currency, false, ErrCurrencyExists
+		}
+		if err != nil {
+			return currency, false, err
+		}
+		s.cache.Track(currency)
+		log.Printf("Новая валюта для отслеживания добавлена: %s", symbol)
+		return currency, false, nil
+	default:
+		return currency, false, err
+	}
+}
+
+// Remove убирает валюту из отслеживания по id (если задан) или символу
+func (s *CurrencyService) Remove(ctx context.Context, id uint, symbol string) error {
+	if id == 0 {
+		if err := ValidateSymbol(symbol); err != nil {
+			return err
+		}
+	}
+
+	err := s.repo.Delete(ctx, id, symbol)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrCurrencyNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	s.cache.Untrack(id, symbol)
+	log.Printf("Валюта удалена: ID=%v, Symbol=%v", id, symbol)
+	return nil
+}
+
+// Pause приостанавливает сбор цен валюты: история остается доступной для запросов, а чекер цен валюту пропускает.
+// ErrCurrencyNotFound, если валюты нет или она удалена; конфликт, если сбор уже приостановлен
+func (s *CurrencyService) Pause(ctx context.Context, symbol string) (CurrencySnapshot, error) {
+	return s.setStatus(ctx, symbol, models.CurrencyActive, models.CurrencyPaused)
+}
+
+// Resume возобновляет сбор цен приостановленной валюты. ErrCurrencyNotFound, если валюты нет или она удалена;
+// конфликт, если сбор не приостановлен
+func (s *CurrencyService) Resume(ctx context.Context, symbol string) (CurrencySnapshot, error) {
+	return s.setStatus(ctx, symbol, models.CurrencyPaused, models.CurrencyActive)
+}
+
+func (s *CurrencyService) setStatus(ctx context.Context, symbol, from, to string) (CurrencySnapshot, error) {
+	if err := ValidateSymbol(symbol); err != nil {
+		return CurrencySnapshot{}, err
+	}
+
+	currency, err := s.repo.FindBySymbol(ctx, symbol)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && currency.DeletedAt.Valid) {
+		return CurrencySnapshot{}, ErrCurrencyNotFound
+	}
+	if err != nil {
+		return CurrencySnapshot{}, err
+	}
+	if currency.Status != from {
+		return CurrencySnapshot{}, statusConflict(symbol, currency.Status)
+	}
+
+	err = s.repo.SetStatus(ctx, currency.ID, from, to)
+	if errors.Is(err, repository.ErrConflict) { // Состояние успел сменить параллельный запрос
+		return CurrencySnapshot{}, statusConflict(symbol, to)
+	}
+	if err != nil {
+		return CurrencySnapshot{}, err
+	}
+
+	s.cache.SetStatus(symbol, to)
+	log.Printf("Состояние валюты %s: %s -> %s", symbol, from, to)
+	snapshot, ok := s.cache.Get(symbol)
+	if !ok {
+		snapshot = CurrencySnapshot{ID: currency.ID, Symbol: symbol, Status: to, AddedAt: currency.CreatedAt}
+	}
+	return snapshot, nil
+}
+
+// TrackingHistory - периоды отслеживания валюты по возрастанию начала
+type TrackingHistory struct {
+	Symbol  string                  `json:"symbol"`
+	Tracked bool                    `json:"tracked"` // цены валюты собираются сейчас: она не удалена и не приостановлена
+	Periods []models.TrackingPeriod `json:"periods"`
+}
+
+// TrackingHistory возвращает историю отслеживания валюты, включая удаленную. ErrCurrencyNotFound, если валюты нет
+func (s *CurrencyService) TrackingHistory(ctx context.Context, symbol string) (TrackingHistory, error) {
+	history := TrackingHistory{Symbol: symbol, Periods: []models.TrackingPeriod{}}
+	if err := ValidateSymbol(symbol); err != nil {
+		return history, err
+	}
+
+	currency, err := s.repo.FindBySymbol(ctx, symbol)
+	if errors.Is(err, repository.ErrNotFound) {
+		return history, ErrCurrencyNotFound
+	}
+	if err != nil {
+		return history, err
+	}
+	periods, err := s.repo.TrackingPeriods(ctx, []string{symbol})
+	if err != nil {
+		return history, err
+	}
+	if len(periods[symbol]) > 0 {
+		history.Periods = periods[symbol]
+	}
+	history.Tracked = !currency.DeletedAt.Valid && currency.Status == models.CurrencyActive
+	return history, nil
+}
+
+// Refresh перечитывает кеш валют из хранилища после изменений в обход сервиса, например загрузки истории
+func (s *CurrencyService) Refresh(ctx context.Context) error {
+	return s.cache.Load(ctx, s.repo)
+}
+
+// List возвращает все известные валюты из кеша
+func (s *CurrencyService) List() []CurrencySnapshot {
+	return s.cache.List()
+}
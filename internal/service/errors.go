@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrorKind - категория доменной ошибки. По ней транспорты выбирают код ответа:
+// HTTP-статус или код gRPC
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota // сбой хранилища или другая непредвиденная ошибка
+	KindNotFound                  // запрошенной сущности нет
+	KindConflict                  // сущность уже существует
+	KindInvalid                   // некорректные входные данные
+)
+
+// Error - доменная ошибка сервисов. Message безопасно отдавать клиенту
+type Error struct {
+	Kind    ErrorKind
+	Message string
+	Err     error // более общая ошибка, на которую можно проверять через errors.Is
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf возвращает категорию ошибки; для ошибок не из сервисов это KindInternal
+func KindOf(err error) ErrorKind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
+// Ошибки сервисов валют и цен
+var (
+	ErrCurrencyNotFound = &Error{Kind: KindNotFound, Message: "Currency not found"}
+	ErrCurrencyExists   = &Error{Kind: KindConflict, Message: "Currency already exists"}
+	ErrNoPriceData      = &Error{Kind: KindNotFound, Message: "No price data available"}
+)
+
+// invalidf создает ошибку некорректного ввода
+func invalidf(format string, args ...any) error {
+	return &Error{Kind: KindInvalid, Message: fmt.Sprintf(format, args...)}
+}
+
+// noPriceData уточняет ErrNoPriceData символом валюты
+func noPriceData(symbol string) error {
+	return &Error{Kind: KindNotFound, Message: ErrNoPriceData.Message + " for " + symbol, Err: ErrNoPriceData}
+}
+
+// notTracked - ErrNoPriceData для момента, когда валюта не отслеживалась
+func notTracked(symbol string, at time.Time) error {
+	message := fmt.Sprintf("%s was not tracked at %s", symbol, at.UTC().Format(time.RFC3339Nano))
+	return &Error{Kind: KindNotFound, Message: message, Err: ErrNoPriceData}
+}
+
+// statusConflict - валюта уже не в том состоянии, из которого ее переводят
+func statusConflict(symbol, status string) error {
+	return &Error{Kind: KindConflict, Message: fmt.Sprintf("Currency %s is %s", symbol, status)}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"affarm/internal/pricefile"
+	"affarm/internal/repository"
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// MaxExportSymbols - максимальное количество валют в одной выгрузке
+const MaxExportSymbols = 1000
+
+// ExportQuery - выгрузка сырых точек рядов за период [From, To) в файл формата Format
+type ExportQuery struct {
+	Symbols []string // пусто - все отслеживаемые валюты
+	From    time.Time
+	To      time.Time
+	Format  string // pricefile.FormatCSV, FormatNDJSON или FormatParquet
+}
+
+// Validate проверяет выгрузку и убирает повторы символов. Транспорты вызывают ее до того,
+// как начнут писать ответ, чтобы ошибку ввода можно было вернуть обычным кодом
+func (q *ExportQuery) Validate() error {
+	if len(q.Symbols) > MaxExportSymbols {
+		return invalidf("too many symbols, max %d", MaxExportSymbols)
+	}
+	symbols := make([]string, 0, len(q.Symbols))
+	seen := make(map[string]bool, len(q.Symbols))
+	for _, symbol := range q.Symbols {
+		if err := ValidateSymbol(symbol); err != nil {
+			return err
+		}
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	q.Symbols = symbols
+
+	if q.From.IsZero() || q.To.IsZero() {
+		return invalidf("from and to are required")
+	}
+	if !q.To.After(q.From) {
+		return invalidf("to must be after from")
+	}
+	if _, err := pricefile.Extension(q.Format); err != nil {
+		return invalidf("invalid format %q, expected csv, ndjson or parquet", q.Format)
+	}
+	return nil
+}
+
+// Export пишет точки в w по мере чтения из хранилища и возвращает количество выгруженных строк.
+// Ошибка хранилища может прийти, когда часть файла уже записана
+func (s *PriceService) Export(ctx context.Context, q ExportQuery, w io.Writer) (int64, error) {
+	if err := q.Validate(); err != nil {
+		return 0, err
+	}
+	writer, err := pricefile.NewWriter(q.Format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = s.repo.Export(ctx, repository.ExportQuery{Symbols: q.Symbols, From: q.From, To: q.To}, func(p repository.ExportPoint) error {
+		count++
+		return writer.Write(pricefile.Row{
+			Symbol:     p.Symbol,
+			CurrencyID: int64(p.CurrencyID),
+			Timestamp:  p.Timestamp,
+			Price:      p.Price.String(),
+			Source:     p.Source,
+			Volume:     p.Volume,
+		})
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return count, ErrCurrencyNotFound
+	}
+	if err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"affarm/config"
+	"affarm/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"sync"
+	"time"
+)
+
+// PriceEvent - полезная нагрузка события price.created
+type PriceEvent struct {
+	PriceID    uint      `json:"price_id"`
+	CurrencyID uint      `json:"currency_id"`
+	Symbol     string    `json:"symbol"`
+	Price      float64   `json:"price"`
+	Timestamp  time.Time `json:"timestamp"`
+	Source     string    `json:"source"`
+}
+
+// OutboxEnvelope - событие в том виде, в котором его получают sinks.
+// Seq - уникальный номер события, по нему получатель отбрасывает повторы. Событие долгой транзакции
+// может прийти после событий с большим Seq, поэтому повтор определяется по полученным номерам, а не по максимуму
+type OutboxEnvelope struct {
+	Seq       uint64          `json:"seq"`
+	Topic     string          `json:"topic"`
+	CreatedAt time.Time       `json:"created_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// EventSink - получатель событий outbox. Publish должен вернуть ошибку,
+// если хотя бы одно событие пачки не принято: тогда пачка будет отправлена повторно
+type EventSink interface {
+	Name() string
+	Publish(ctx context.Context, events []OutboxEnvelope) error
+}
+
+// WriteOutboxEvent записывает событие в outbox в рамках транзакции tx
+func WriteOutboxEvent(tx *gorm.DB, topic string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации события: %w", err)
+	}
+	event := models.OutboxEvent{Topic: topic, Payload: string(body)}
+	if err := tx.Create(&event).Error; err != nil {
+		return fmt.Errorf("ошибка записи события в outbox: %w", err)
+	}
+	return nil
+}
+
+// maxOutboxGaps - сколько пропущенных номеров событий отслеживается на один sink.
+// Пропуски сверх этого не ждут: такой разрыв означает массовый откат вставок, а не долгие транзакции
+const maxOutboxGaps = 10000
+
+// OutboxRelay читает события из outbox и доставляет их в каждый sink независимо.
+// Позиция каждого sink хранится в outbox_cursors и сдвигается только после успешной отправки,
+// поэтому доставка как минимум однократная: после сбоя пачка может прийти повторно.
+//
+// Номер события выдается при вставке, а видимым оно становится при коммите, поэтому транзакция
+// с меньшим номером может закоммититься позже уже отправленных событий. Такие пропуски номеров
+// запоминаются и перепроверяются, пока не придет событие или не истечет gapTimeout
+// (номер мог сгореть при откате). В курсор пишется позиция до самого раннего пропуска:
+// после перезапуска события за ней отправятся повторно, и пропуски будут найдены заново
+type OutboxRelay struct {
+	db            *gorm.DB
+	sinks         []EventSink
+	interval      time.Duration
+	batchSize     int
+	gapTimeout    time.Duration
+	pruneInterval time.Duration
+
+	stopChannel chan struct{}
+	wg          sync.WaitGroup
+}
+
+// relayState - позиция рассылки одного sink в памяти
+type relayState struct {
+	loaded bool
+	high   uint64               // последний отправленный номер
+	gaps   map[uint64]time.Time // номера ниже high, которых не было в outbox, и когда пропуск замечен
+	saved  uint64               // позиция, записанная в outbox_cursors
+}
+
+// NewOutboxRelay - конструктор рассылки событий
+func NewOutboxRelay(db *gorm.DB, cfg config.OutboxConfig, sinks []EventSink) *OutboxRelay {
+	if db == nil {
+		log.Panic("ошибка, подключение к базе не существует")
+	}
+
+	return &OutboxRelay{
+		db:            db,
+		sinks:         sinks,
+		interval:      time.Duration(cfg.PollIntervalMs) * time.Millisecond,
+		batchSize:     cfg.BatchSize,
+		gapTimeout:    time.Duration(cfg.GapTimeoutMs) * time.Millisecond,
+		pruneInterval: time.Duration(cfg.PruneIntervalMin) * time.Minute,
+		stopChannel:   make(chan struct{}),
+	}
+}
+
+// Start запускает по горутине на каждый sink, чтобы медленный получатель не задерживал остальных,
+// и очистку доставленных событий
+func (r *OutboxRelay) Start() {
+	for _, sink := range r.sinks {
+		r.wg.Add(1)
+		go r.run(sink)
+	}
+	if r.pruneInterval > 0 {
+		r.wg.Add(1)
+		go r.runPrune()
+	}
+	log.Printf("Рассылка outbox запущена, получателей: %d", len(r.sinks))
+}
+
+// Stop останавливает рассылку и дожидается завершения текущих отправок
+func (r *OutboxRelay) Stop() {
+	close(r.stopChannel)
+	r.wg.Wait()
+}
+
+func (r *OutboxRelay) run(sink EventSink) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	state := &relayState{gaps: make(map[uint64]time.Time)}
+	for {
+		select {
+		case <-ticker.C:
+			// Отправляем пачки подряд, пока не догоним хвост outbox
+			for {
+				sent, err := r.relayBatch(sink, state)
+				if err != nil {
+					log.Printf("ошибка отправки событий в %s: %v", sink.Name(), err)
+					break
+				}
+				if sent < r.batchSize {
+					break
+				}
+			}
+		case <-r.stopChannel:
+			return
+		}
+	}
+}
+
+// relayBatch отправляет в sink события, закрывшие пропуски, и следующую пачку после high,
+// затем сдвигает курсор. Возвращает количество новых событий после high
+func (r *OutboxRelay) relayBatch(sink EventSink, state *relayState) (int, error) {
+	if !state.loaded {
+		cursor := models.OutboxCursor{Sink: sink.Name()}
+		if err := r.db.FirstOrCreate(&cursor, models.OutboxCursor{Sink: sink.Name()}).Error; err != nil {
+			return 0, fmt.Errorf("ошибка чтения курсора: %w", err)
+		}
+		state.high, state.saved, state.loaded = cursor.LastSeq, cursor.LastSeq, true
+	}
+
+	var late []models.OutboxEvent
+	if len(state.gaps) > 0 {
+		ids := make([]uint64, 0, len(state.gaps))
+		for id := range state.gaps {
+			ids = append(ids, id)
+		}
+		if err := r.db.Where("id IN ?", ids).Order("id").Find(&late).Error; err != nil {
+			return 0, fmt.Errorf("ошибка чтения outbox: %w", err)
+		}
+	}
+
+	var events []models.OutboxEvent
+	err := r.db.Where("id > ?", state.high).Order("id").Limit(r.batchSize).Find(&events).Error
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения outbox: %w", err)
+	}
+
+	now := time.Now()
+	if len(late) > 0 || len(events) > 0 {
+		envelopes := make([]OutboxEnvelope, 0, len(late)+len(events))
+		for _, event := range append(late, events...) {
+			envelopes = append(envelopes, OutboxEnvelope{
+				Seq:       event.ID,
+				Topic:     event.Topic,
+				CreatedAt: event.CreatedAt,
+				Payload:   json.RawMessage(event.Payload),
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := sink.Publish(ctx, envelopes); err != nil {
+			return 0, err
+		}
+
+		for _, event := range late {
+			delete(state.gaps, event.ID)
+		}
+		for _, event := range events {
+			for id := state.high + 1; id < event.ID && len(state.gaps) < maxOutboxGaps; id++ {
+				state.gaps[id] = now
+			}
+			state.high = event.ID
+		}
+	}
+
+	for id, seen := range state.gaps {
+		if now.Sub(seen) >= r.gapTimeout {
+			delete(state.gaps, id)
+			log.Printf("outbox: событие %d для %s не появилось за %s, номер пропущен", id, sink.Name(), r.gapTimeout)
+		}
+	}
+
+	// Курсор не обгоняет пропуски, которые еще могут заполниться
+	safe := state.high
+	for id := range state.gaps {
+		safe = min(safe, id-1)
+	}
+	if safe != state.saved {
+		cursor := models.OutboxCursor{Sink: sink.Name(), LastSeq: safe}
+		if err := r.db.Save(&cursor).Error; err != nil {
+			return 0, fmt.Errorf("ошибка сохранения курсора: %w", err)
+		}
+		state.saved = safe
+	}
+	return len(events), nil
+}
+
+func (r *OutboxRelay) runPrune() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := r.prune(); err != nil {
+				log.Printf("ошибка очистки outbox: %v", err)
+			}
+		case <-r.stopChannel:
+			return
+		}
+	}
+}
+
+// prune удаляет события не дальше самого отстающего курсора: они доставлены всем получателям из конфига
+// или их пропуск истек. Курсоры получателей, убранных из конфига, не учитываются; пока хотя бы у одного
+// получателя нет курсора, не удаляется ничего. Возвращает количество удаленных событий
+func (r *OutboxRelay) prune() (int64, error) {
+	if len(r.sinks) == 0 {
+		return 0, nil
+	}
+	names := make([]string, 0, len(r.sinks))
+	for _, sink := range r.sinks {
+		names = append(names, sink.Name())
+	}
+
+	var cursors []models.OutboxCursor
+	if err := r.db.Where("sink IN ?", names).Find(&cursors).Error; err != nil {
+		return 0, fmt.Errorf("ошибка чтения курсоров: %w", err)
+	}
+	if len(cursors) < len(names) {
+		return 0, nil
+	}
+	delivered := cursors[0].LastSeq
+	for _, cursor := range cursors[1:] {
+		delivered = min(delivered, cursor.LastSeq)
+	}
+
+	result := r.db.Where("id <= ?", delivered).Delete(&models.OutboxEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("ошибка удаления событий: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
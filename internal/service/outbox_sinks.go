@@ -0,0 +1,278 @@
+package services
+
+import (
+	"affarm/config"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewEventSinks создает получателей событий по конфигу
+func NewEventSinks(cfg []config.SinkConfig) ([]EventSink, error) {
+	sinks := make([]EventSink, 0, len(cfg))
+	for _, sinkCfg := range cfg {
+		switch sinkCfg.Type {
+		case "stdout":
+			sinks = append(sinks, NewWriterSink(sinkCfg.Name, os.Stdout))
+		case "file":
+			if sinkCfg.Path == "" {
+				return nil, fmt.Errorf("для sink %s не указан path", sinkCfg.Name)
+			}
+			sinks = append(sinks, NewFileSink(sinkCfg.Name, sinkCfg.Path))
+		case "http":
+			if sinkCfg.URL == "" {
+				return nil, fmt.Errorf("для sink %s не указан url", sinkCfg.Name)
+			}
+			sinks = append(sinks, NewHTTPSink(sinkCfg.Name, sinkCfg.URL))
+		case "nats":
+			if sinkCfg.URL == "" || sinkCfg.Subject == "" {
+				return nil, fmt.Errorf("для sink %s не указаны url или subject", sinkCfg.Name)
+			}
+			sinks = append(sinks, NewBrokerSink(sinkCfg.Name, NewNATSPublisher(sinkCfg.URL), sinkCfg.Subject))
+		default:
+			return nil, fmt.Errorf("неизвестный тип sink %q", sinkCfg.Type)
+		}
+	}
+	return sinks, nil
+}
+
+// writeNDJSON пишет события по одному JSON-объекту на строку
+func writeNDJSON(w io.Writer, events []OutboxEnvelope) error {
+	encoder := json.NewEncoder(w)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriterSink пишет события в NDJSON в произвольный io.Writer (например, stdout)
+type WriterSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func NewWriterSink(name string, w io.Writer) *WriterSink {
+	return &WriterSink{name: name, w: w}
+}
+
+func (s *WriterSink) Name() string { return s.name }
+
+func (s *WriterSink) Publish(_ context.Context, events []OutboxEnvelope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeNDJSON(s.w, events)
+}
+
+// FileSink дописывает события в NDJSON-файл и сбрасывает его на диск перед подтверждением
+type FileSink struct {
+	name string
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSink(name, path string) *FileSink {
+	return &FileSink{name: name, path: path}
+}
+
+func (s *FileSink) Name() string { return s.name }
+
+func (s *FileSink) Publish(_ context.Context, events []OutboxEnvelope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла событий: %w", err)
+	}
+	defer file.Close()
+
+	buffered := bufio.NewWriter(file)
+	if err := writeNDJSON(buffered, events); err != nil {
+		return fmt.Errorf("ошибка записи событий: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("ошибка записи событий: %w", err)
+	}
+	return file.Sync()
+}
+
+// HTTPSink отправляет пачку событий одним POST-запросом с телом NDJSON.
+// Пачка считается принятой только при ответе 2xx
+type HTTPSink struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(name, url string) *HTTPSink {
+	return &HTTPSink{name: name, url: url, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (s *HTTPSink) Name() string { return s.name }
+
+func (s *HTTPSink) Publish(ctx context.Context, events []OutboxEnvelope) error {
+	var body bytes.Buffer
+	if err := writeNDJSON(&body, events); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, &body)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("неверный статус код: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// BrokerPublisher - минимальный интерфейс брокера сообщений (NATS, Kafka и т.п.).
+// Publish возвращает управление после того, как брокер подтвердил прием всех сообщений
+type BrokerPublisher interface {
+	Publish(ctx context.Context, subject string, messages [][]byte) error
+}
+
+// BrokerSink публикует каждое событие отдельным сообщением в брокер
+type BrokerSink struct {
+	name      string
+	publisher BrokerPublisher
+	subject   string
+}
+
+func NewBrokerSink(name string, publisher BrokerPublisher, subject string) *BrokerSink {
+	return &BrokerSink{name: name, publisher: publisher, subject: subject}
+}
+
+func (s *BrokerSink) Name() string { return s.name }
+
+func (s *BrokerSink) Publish(ctx context.Context, events []OutboxEnvelope) error {
+	messages := make([][]byte, len(events))
+	for i, event := range events {
+		message, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		messages[i] = message
+	}
+	return s.publisher.Publish(ctx, s.subject, messages)
+}
+
+// NATSPublisher публикует сообщения по текстовому протоколу NATS.
+// После пачки PUB отправляется PING: ответ PONG означает, что сервер обработал все сообщения
+type NATSPublisher struct {
+	address string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSPublisher принимает адрес вида nats://host:port
+func NewNATSPublisher(rawURL string) *NATSPublisher {
+	address := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.Host != "" {
+		address = parsed.Host
+	}
+	return &NATSPublisher{address: address}
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, subject string, messages [][]byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.connect(ctx); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		p.conn.SetDeadline(deadline)
+	}
+
+	writer := bufio.NewWriter(p.conn)
+	for _, message := range messages {
+		fmt.Fprintf(writer, "PUB %s %d\r\n", subject, len(message))
+		writer.Write(message)
+		writer.WriteString("\r\n")
+	}
+	writer.WriteString("PING\r\n")
+	if err := writer.Flush(); err != nil {
+		p.close()
+		return fmt.Errorf("ошибка отправки в nats: %w", err)
+	}
+
+	if err := p.waitPong(); err != nil {
+		p.close()
+		return err
+	}
+	return nil
+}
+
+// connect устанавливает соединение и выполняет рукопожатие INFO/CONNECT
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	if p.conn != nil {
+		return nil
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к nats: %w", err)
+	}
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+
+	line, err := p.reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO") {
+		p.close()
+		return fmt.Errorf("nats не прислал INFO: %v", err)
+	}
+	if _, err := io.WriteString(conn, "CONNECT {\"verbose\":false,\"pedantic\":false,\"name\":\"affarm-outbox\"}\r\n"); err != nil {
+		p.close()
+		return fmt.Errorf("ошибка рукопожатия с nats: %w", err)
+	}
+	return nil
+}
+
+func (p *NATSPublisher) waitPong() error {
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("ошибка чтения ответа nats: %w", err)
+		}
+		switch {
+		case strings.HasPrefix(line, "PONG"):
+			return nil
+		case strings.HasPrefix(line, "PING"):
+			io.WriteString(p.conn, "PONG\r\n")
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats вернул ошибку: %s", strings.TrimSpace(line))
+		}
+	}
+}
+
+func (p *NATSPublisher) close() {
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.conn, p.reader = nil, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNATS - сервер NATS из одного соединения: отвечает на рукопожатие, записывает полученные строки
+// и на PING отвечает reply (по умолчанию PONG), предварительно прислав свой PING
+type fakeNATS struct {
+	address string
+	lines   chan string
+	reply   string
+}
+
+func startFakeNATS(t *testing.T, reply string) *fakeNATS {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeNATS{address: listener.Addr().String(), lines: make(chan string, 100), reply: reply}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.WriteString(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576}\r\n")
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(server.lines)
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			server.lines <- line
+			switch {
+			case line == "PING":
+				// Сервер сам проверяет клиента до ответа: клиент должен ответить PONG и ждать дальше
+				io.WriteString(conn, "PING\r\n")
+				pong, err := reader.ReadString('\n')
+				if err != nil {
+					close(server.lines)
+					return
+				}
+				server.lines <- strings.TrimRight(pong, "\r\n")
+				io.WriteString(conn, server.reply+"\r\n")
+			case strings.HasPrefix(line, "PUB "):
+				fields := strings.Fields(line)
+				size, _ := strconv.Atoi(fields[len(fields)-1])
+				payload := make([]byte, size+2) // сообщение и \r\n
+				if _, err := io.ReadFull(reader, payload); err != nil {
+					close(server.lines)
+					return
+				}
+				server.lines <- string(payload[:size])
+			}
+		}
+	}()
+	return server
+}
+
+// next возвращает следующую строку, полученную сервером
+func (s *fakeNATS) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case line := <-s.lines:
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatal("сервер nats ничего не получил")
+		return ""
+	}
+}
+
+func TestNATSPublisherFraming(t *testing.T) {
+	server := startFakeNATS(t, "PONG")
+	publisher := NewNATSPublisher("nats://" + server.address)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := publisher.Publish(ctx, "affarm.prices", [][]byte{[]byte(`{"seq":1}`), []byte(`{"seq":22}`)}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`CONNECT {"verbose":false,"pedantic":false,"name":"affarm-outbox"}`,
+		"PUB affarm.prices 9", `{"seq":1}`,
+		"PUB affarm.prices 10", `{"seq":22}`,
+		"PING", "PONG",
+	}
+	for _, line := range want {
+		if got := server.next(t); got != line {
+			t.Fatalf("сервер получил %q, ожидалось %q", got, line)
+		}
+	}
+}
+
+func TestNATSPublisherError(t *testing.T) {
+	server := startFakeNATS(t, "-ERR 'Authorization Violation'")
+	publisher := NewNATSPublisher("nats://" + server.address)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := publisher.Publish(ctx, "affarm.prices", [][]byte{[]byte(`{}`)})
+	if err == nil || !strings.Contains(err.Error(), "Authorization Violation") {
+		t.Fatalf("ошибка %v, ожидался ответ -ERR сервера", err)
+	}
+	if publisher.conn != nil {
+		t.Fatal("после -ERR соединение должно закрываться, чтобы следующая пачка переподключилась")
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"affarm/config"
+	"affarm/internal/database"
+	"affarm/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MemoryBroker - брокер в памяти процесса вместо NATS/Kafka. Fail позволяет сымитировать отказ брокера
+type MemoryBroker struct {
+	mu       sync.Mutex
+	messages map[string][][]byte
+	Fail     error
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{messages: make(map[string][][]byte)}
+}
+
+func (b *MemoryBroker) Publish(_ context.Context, subject string, messages [][]byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Fail != nil {
+		return b.Fail
+	}
+	b.messages[subject] = append(b.messages[subject], messages...)
+	return nil
+}
+
+// Seqs возвращает номера событий, опубликованных в subject, по порядку
+func (b *MemoryBroker) Seqs(t *testing.T, subject string) []uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	seqs := make([]uint64, 0, len(b.messages[subject]))
+	for _, message := range b.messages[subject] {
+		var envelope OutboxEnvelope
+		if err := json.Unmarshal(message, &envelope); err != nil {
+			t.Fatal(err)
+		}
+		seqs = append(seqs, envelope.Seq)
+	}
+	return seqs
+}
+
+func newTestRelay(t *testing.T, gapTimeout time.Duration) (*OutboxRelay, *MemoryBroker, EventSink) {
+	t.Helper()
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	broker := NewMemoryBroker()
+	sink := NewBrokerSink("test", broker, "prices")
+	relay := NewOutboxRelay(db, config.OutboxConfig{PollIntervalMs: 1000, BatchSize: 100, GapTimeoutMs: int(gapTimeout / time.Millisecond)}, []EventSink{sink})
+	return relay, broker, sink
+}
+
+// insertEvents записывает события с заданными номерами, как если бы их транзакции закоммитились в этом порядке
+func insertEvents(t *testing.T, relay *OutboxRelay, ids ...uint64) {
+	t.Helper()
+	for _, id := range ids {
+		event := models.OutboxEvent{ID: id, Topic: models.TopicPriceCreated, Payload: "{}"}
+		if err := relay.db.Create(&event).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func cursorOf(t *testing.T, relay *OutboxRelay, sink EventSink) uint64 {
+	t.Helper()
+	var cursor models.OutboxCursor
+	if err := relay.db.First(&cursor, "sink = ?", sink.Name()).Error; err != nil {
+		t.Fatal(err)
+	}
+	return cursor.LastSeq
+}
+
+func TestOutboxRelayLateCommit(t *testing.T) {
+	relay, broker, sink := newTestRelay(t, time.Minute)
+	state := &relayState{gaps: make(map[uint64]time.Time)}
+
+	// Событие 3 принадлежит транзакции, которая коммитится после 4 и 5
+	insertEvents(t, relay, 1, 2, 4, 5)
+	if _, err := relay.relayBatch(sink, state); err != nil {
+		t.Fatal(err)
+	}
+	if got := cursorOf(t, relay, sink); got != 2 {
+		t.Fatalf("курсор %d, ожидалось 2: он не должен обгонять пропуск 3", got)
+	}
+
+	insertEvents(t, relay, 3, 6)
+	if _, err := relay.relayBatch(sink, state); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := broker.Seqs(t, "prices"), []uint64{1, 2, 4, 5, 3, 6}; !slices.Equal(got, want) {
+		t.Fatalf("отправлено %v, ожидалось %v", got, want)
+	}
+	if got := cursorOf(t, relay, sink); got != 6 {
+		t.Fatalf("курсор %d, ожидалось 6", got)
+	}
+}
+
+func TestOutboxRelayGapTimeout(t *testing.T) {
+	relay, broker, sink := newTestRelay(t, time.Millisecond)
+	state := &relayState{gaps: make(map[uint64]time.Time)}
+
+	// Номер 2 сгорел при откате и никогда не появится
+	insertEvents(t, relay, 1, 3)
+	if _, err := relay.relayBatch(sink, state); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := relay.relayBatch(sink, state); err != nil {
+		t.Fatal(err)
+	}
+	if len(state.gaps) != 0 {
+		t.Fatalf("пропуски после таймаута: %v", state.gaps)
+	}
+	if got := cursorOf(t, relay, sink); got != 3 {
+		t.Fatalf("курсор %d, ожидалось 3", got)
+	}
+	if got, want := broker.Seqs(t, "prices"), []uint64{1, 3}; !slices.Equal(got, want) {
+		t.Fatalf("отправлено %v, ожидалось %v", got, want)
+	}
+}
+
+func TestOutboxRelayRetryAfterFailure(t *testing.T) {
+	relay, broker, sink := newTestRelay(t, time.Minute)
+	state := &relayState{gaps: make(map[uint64]time.Time)}
+
+	insertEvents(t, relay, 1, 2)
+	broker.Fail = errors.New("broker down")
+	if _, err := relay.relayBatch(sink, state); err == nil {
+		t.Fatal("ожидалась ошибка отправки")
+	}
+
+	broker.Fail = nil
+	if _, err := relay.relayBatch(sink, state); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := broker.Seqs(t, "prices"), []uint64{1, 2}; !slices.Equal(got, want) {
+		t.Fatalf("отправлено %v, ожидалось %v", got, want)
+	}
+	if got := cursorOf(t, relay, sink); got != 2 {
+		t.Fatalf("курсор %d, ожидалось 2", got)
+	}
+}
+
+func TestOutboxRelayPrune(t *testing.T) {
+	relay, _, fast := newTestRelay(t, time.Minute)
+	slow := NewBrokerSink("slow", NewMemoryBroker(), "prices")
+	relay.sinks = append(relay.sinks, slow)
+	fastState := &relayState{gaps: make(map[uint64]time.Time)}
+	slowState := &relayState{gaps: make(map[uint64]time.Time)}
+
+	insertEvents(t, relay, 1, 2)
+	if _, err := relay.relayBatch(fast, fastState); err != nil {
+		t.Fatal(err)
+	}
+	// У второго получателя еще нет курсора: удалять нельзя ничего
+	if deleted, err := relay.prune(); err != nil || deleted != 0 {
+		t.Fatalf("удалено %d (%v), ожидалось 0", deleted, err)
+	}
+
+	if _, err := relay.relayBatch(slow, slowState); err != nil {
+		t.Fatal(err)
+	}
+	insertEvents(t, relay, 3)
+	if _, err := relay.relayBatch(fast, fastState); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := relay.prune(); err != nil || deleted != 2 {
+		t.Fatalf("удалено %d (%v), ожидалось 2: событие 3 еще не получил slow", deleted, err)
+	}
+	var left []uint64
+	if err := relay.db.Model(&models.OutboxEvent{}).Order("id").Pluck("id", &left).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(left, []uint64{3}) {
+		t.Fatalf("в outbox остались %v, ожидалось [3]", left)
+	}
+}
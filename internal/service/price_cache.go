@@ -0,0 +1,160 @@
+package services
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Статусы отслеживания валюты, совпадают с состояниями models.Currency
+const (
+	StatusActive   = models.CurrencyActive
+	StatusPaused   = models.CurrencyPaused
+	StatusArchived = models.CurrencyArchived
+)
+
+// CurrencySnapshot - состояние отслеживаемой валюты в кеше
+type CurrencySnapshot struct {
+	ID         uint       `json:"id"`
+	Symbol     string     `json:"symbol"`
+	Status     string     `json:"status" enums:"active,paused,archived"`
+	AddedAt    time.Time  `json:"added_at"`
+	LastPrice  *float64   `json:"last_price,omitempty"`
+	LastUpdate *time.Time `json:"last_update,omitempty"`
+	LastSource string     `json:"last_source,omitempty"`
+	PointCount int64      `json:"point_count"`
+}
+
+// PriceListener получает каждую сохраненную чекером цену
+type PriceListener interface {
+	OnPrice(currency models.Currency, price models.Price)
+}
+
+// PriceCache - кеш списка валют и их последних цен в памяти.
+// Заполняется из БД при старте, дальше обновляется чекером цен и обработчиками add/remove
+type PriceCache struct {
+	mu         sync.RWMutex
+	currencies map[string]*CurrencySnapshot // ключ - символ валюты
+}
+
+// NewPriceCache - конструктор пустого кеша
+func NewPriceCache() *PriceCache {
+	return &PriceCache{currencies: make(map[string]*CurrencySnapshot)}
+}
+
+// Load заполняет кеш из хранилища
+func (c *PriceCache) Load(ctx context.Context, repo repository.Currencies) error {
+	rows, err := repo.Stats(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки кеша валют: %w", err)
+	}
+
+	currencies := make(map[string]*CurrencySnapshot, len(rows))
+	for _, row := range rows {
+		snapshot := &CurrencySnapshot{
+			ID:         row.Currency.ID,
+			Symbol:     row.Currency.Symbol,
+			Status:     row.Currency.Status,
+			AddedAt:    row.Currency.CreatedAt,
+			PointCount: row.PointCount,
+		}
+		if row.Currency.DeletedAt.Valid {
+			snapshot.Status = StatusArchived
+		}
+		if row.Last != nil {
+			price, update := row.Last.Price.InexactFloat64(), row.Last.Timestamp
+			snapshot.LastPrice, snapshot.LastUpdate, snapshot.LastSource = &price, &update, row.Last.Source
+		}
+		currencies[row.Currency.Symbol] = snapshot
+	}
+
+	c.mu.Lock()
+	c.currencies = currencies
+	c.mu.Unlock()
+	return nil
+}
+
+// Track отмечает валюту как отслеживаемую (новую или восстановленную)
+func (c *PriceCache) Track(currency models.Currency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if snapshot, ok := c.currencies[currency.Symbol]; ok {
+		snapshot.Status = StatusActive
+		return
+	}
+	c.currencies[currency.Symbol] = &CurrencySnapshot{
+		ID:      currency.ID,
+		Symbol:  currency.Symbol,
+		Status:  StatusActive,
+		AddedAt: currency.CreatedAt,
+	}
+}
+
+// Untrack отмечает валюту как удаленную из отслеживания (archived). Ищет по id, если он задан, иначе по символу
+func (c *PriceCache) Untrack(id uint, symbol string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, snapshot := range c.currencies {
+		if (id != 0 && snapshot.ID == id) || (id == 0 && snapshot.Symbol == symbol) {
+			snapshot.Status = StatusArchived
+		}
+	}
+}
+
+// SetStatus меняет статус валюты в кеше
+func (c *PriceCache) SetStatus(symbol, status string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if snapshot, ok := c.currencies[symbol]; ok {
+		snapshot.Status = status
+	}
+}
+
+// OnPrice обновляет последнюю цену валюты, реализует PriceListener
+func (c *PriceCache) OnPrice(currency models.Currency, price models.Price) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot, ok := c.currencies[currency.Symbol]
+	if !ok {
+		snapshot = &CurrencySnapshot{ID: currency.ID, Symbol: currency.Symbol, Status: StatusActive, AddedAt: currency.CreatedAt}
+		c.currencies[currency.Symbol] = snapshot
+	}
+	value, timestamp := price.Price, price.Timestamp
+	snapshot.LastPrice = &value
+	snapshot.LastUpdate = &timestamp
+	snapshot.LastSource = price.Source
+	snapshot.PointCount++
+}
+
+// List возвращает копию состояния всех валют, отсортированную по символу
+func (c *PriceCache) List() []CurrencySnapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]CurrencySnapshot, 0, len(c.currencies))
+	for _, snapshot := range c.currencies {
+		result = append(result, *snapshot)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Symbol < result[j].Symbol })
+	return result
+}
+
+// Get возвращает копию состояния одной валюты
+func (c *PriceCache) Get(symbol string) (CurrencySnapshot, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	snapshot, ok := c.currencies[symbol]
+	if !ok {
+		return CurrencySnapshot{}, false
+	}
+	return *snapshot, true
+}
@@ -3,6 +3,9 @@ package services
 import (
 	"affarm/config"
 	"affarm/internal/models"
+	"affarm/internal/repository"
+	"affarm/internal/repository/gormrepo"
+	"context"
 	"encoding/json"
 	"fmt"
 	"gorm.io/gorm"
@@ -13,30 +16,40 @@ import (
 )
 
 type PriceUpdater struct {
-	db           *gorm.DB
+	repo         repository.Repository
 	interval     time.Duration
 	binanceURL   string
 	convertation string
 	stopChannel  chan bool
+	listeners    []PriceListener // получатели сохраненных цен (кеш и т.п.)
+	outbox       *gorm.DB        // если задано, событие price.created пишется в outbox вместе с ценой
 }
 
-func NewPriceUpdater(db *gorm.DB, cfg *config.BinanceConfig) *PriceUpdater {
-	if db == nil {
-		log.Panic("ошибка, подключение к базе не существует")
+func NewPriceUpdater(repo repository.Repository, cfg *config.BinanceConfig, listeners ...PriceListener) *PriceUpdater {
+	if repo == nil {
+		log.Panic("ошибка, хранилище не существует")
 	}
 	if cfg == nil {
 		log.Panic("ошибка, конфиг отсутствует")
 	}
 
 	return &PriceUpdater{
-		db:           db,
+		repo:         repo,
 		interval:     time.Duration(cfg.TimeoutSec) * time.Second,
 		binanceURL:   cfg.APIURL + "/api/v3/ticker/price?symbol=%s",
 		convertation: cfg.Convertation,
 		stopChannel:  make(chan bool),
+		listeners:    listeners,
 	}
 }
 
+// WithOutbox включает запись события price.created в outbox в одной транзакции с ценой.
+// Outbox живет в SQL-базе, поэтому цены в этом режиме пишутся через db, а не через хранилище
+func (pu *PriceUpdater) WithOutbox(db *gorm.DB) *PriceUpdater {
+	pu.outbox = db
+	return pu
+}
+
 func (pu *PriceUpdater) Start() {
 	ticker := time.NewTicker(pu.interval)
 	defer ticker.Stop()
@@ -60,8 +73,8 @@ func (pu *PriceUpdater) Stop() {
 
 func (pu *PriceUpdater) updatePrices() {
 	// Получаем список всех отслеживаемых валют
-	var currencies []models.Currency
-	if err := pu.db.Find(&currencies).Error; err != nil {
+	currencies, err := pu.repo.Active(context.Background())
+	if err != nil {
 		log.Printf("ошибка при запросе списка отслеживаемых валют из бд: %v", err)
 		return
 	}
@@ -80,11 +93,16 @@ func (pu *PriceUpdater) updatePrices() {
 		}
 
 		// Сохраняем цену в БД
-		if err := pu.savePrice(currency.ID, price); err != nil {
+		record, err := pu.savePrice(currency, price)
+		if err != nil {
 			log.Printf("ошибка при сохранении цены на %s: %v", currency.Symbol, err)
 			continue
 		}
 
+		for _, listener := range pu.listeners {
+			listener.OnPrice(currency, record)
+		}
+
 		log.Printf("Обновлена цена для %s: %f", currency.Symbol, price)
 	}
 }
@@ -126,16 +144,38 @@ func (pu *PriceUpdater) fetchPriceFromBinance(symbol string) (float64, error) {
 	return price, nil
 }
 
-func (pu *PriceUpdater) savePrice(currencyID uint, price float64) error {
+func (pu *PriceUpdater) savePrice(currency models.Currency, price float64) (models.Price, error) {
 	priceRecord := models.Price{
-		CurrencyID: currencyID,
+		CurrencyID: currency.ID,
 		Price:      price,
 		Timestamp:  time.Now(),
+		Source:     models.PriceSourceBinance,
 	}
 
-	if err := pu.db.Create(&priceRecord).Error; err != nil {
-		return fmt.Errorf("ошибка при сохранении цены в бд: %w", err)
+	if pu.outbox == nil {
+		if err := pu.repo.SavePrice(context.Background(), &priceRecord); err != nil {
+			return models.Price{}, err
+		}
+		return priceRecord, nil
+	}
+
+	// Цена и событие о ней пишутся в одной транзакции: событие не теряется и не появляется без цены
+	err := pu.outbox.Transaction(func(tx *gorm.DB) error {
+		if err := gormrepo.UpsertPrice(tx, &priceRecord); err != nil {
+			return err
+		}
+		return WriteOutboxEvent(tx, models.TopicPriceCreated, PriceEvent{
+			PriceID:    priceRecord.ID,
+			CurrencyID: currency.ID,
+			Symbol:     currency.Symbol,
+			Price:      priceRecord.Price,
+			Timestamp:  priceRecord.Timestamp,
+			Source:     priceRecord.Source,
+		})
+	})
+	if err != nil {
+		return models.Price{}, fmt.Errorf("ошибка при сохранении цены в бд: %w", err)
 	}
 
-	return nil
+	return priceRecord, nil
 }
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"affarm/internal/models"
+	"sync"
+	"time"
+)
+
+// StreamEvent - событие о новой цене для потоковых клиентов
+type StreamEvent struct {
+	ID    uint64
+	Price PriceEvent
+}
+
+// PriceSubscription - подписка клиента на поток цен.
+// Канал C закрывается при отписке или если клиент не успевает читать события
+type PriceSubscription struct {
+	C       <-chan StreamEvent
+	Dropped bool // подписка закрыта из-за медленного клиента
+
+	hub     *PriceHub
+	ch      chan StreamEvent
+	symbols map[string]bool // пусто - все валюты
+}
+
+func (s *PriceSubscription) matches(symbol string) bool {
+	return len(s.symbols) == 0 || s.symbols[symbol]
+}
+
+// Close отписывает клиента
+func (s *PriceSubscription) Close() {
+	s.hub.remove(s, false)
+}
+
+// PriceHub раздает новые цены потоковым клиентам (SSE, WebSocket).
+// Хранит короткий буфер последних событий для продолжения по Last-Event-ID.
+// Отправка клиентам не блокирует чекер цен: клиент с заполненным буфером отключается
+type PriceHub struct {
+	mu          sync.Mutex
+	nextID      uint64
+	replay      []StreamEvent // кольцевой буфер последних событий
+	replayStart int
+	replaySize  int
+	clientQueue int
+	subscribers map[*PriceSubscription]struct{}
+}
+
+// NewPriceHub - конструктор, replaySize - сколько событий хранится для продолжения,
+// clientQueue - сколько событий может ждать отправки одному клиенту
+func NewPriceHub(replaySize, clientQueue int) *PriceHub {
+	return &PriceHub{
+		// ID продолжают расти и после перезапуска, чтобы старый Last-Event-ID не блокировал новые события
+		nextID:      uint64(time.Now().UnixMilli()),
+		replay:      make([]StreamEvent, 0, replaySize),
+		replaySize:  replaySize,
+		clientQueue: clientQueue,
+		subscribers: make(map[*PriceSubscription]struct{}),
+	}
+}
+
+// OnPrice рассылает цену подписчикам, реализует PriceListener
+func (h *PriceHub) OnPrice(currency models.Currency, price models.Price) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	event := StreamEvent{ID: h.nextID, Price: PriceEvent{
+		PriceID:    price.ID,
+		CurrencyID: currency.ID,
+		Symbol:     currency.Symbol,
+		Price:      price.Price,
+		Timestamp:  price.Timestamp,
+		Source:     price.Source,
+	}}
+
+	if len(h.replay) < h.replaySize {
+		h.replay = append(h.replay, event)
+	} else if h.replaySize > 0 {
+		h.replay[h.replayStart] = event
+		h.replayStart = (h.replayStart + 1) % h.replaySize
+	}
+
+	for sub := range h.subscribers {
+		if !sub.matches(currency.Symbol) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			h.dropLocked(sub, true)
+		}
+	}
+}
+
+// Subscribe подписывает клиента на валюты symbols (пусто - все).
+// Если lastEventID > 0, сначала отдаются события из буфера после него.
+// Возвращает false вторым значением, если часть событий после lastEventID уже вытеснена из буфера
+func (h *PriceHub) Subscribe(symbols []string, lastEventID uint64) (*PriceSubscription, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	filter := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		filter[symbol] = true
+	}
+
+	var backlog []StreamEvent
+	complete := true
+	if lastEventID > 0 {
+		events := h.orderedLocked()
+		if len(events) > 0 && events[0].ID > lastEventID+1 {
+			complete = false
+		}
+		for _, event := range events {
+			if event.ID > lastEventID && (len(filter) == 0 || filter[event.Price.Symbol]) {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+
+	ch := make(chan StreamEvent, h.clientQueue+len(backlog))
+	for _, event := range backlog {
+		ch <- event
+	}
+	sub := &PriceSubscription{C: ch, hub: h, ch: ch, symbols: filter}
+	h.subscribers[sub] = struct{}{}
+	return sub, complete
+}
+
+// orderedLocked возвращает буфер в порядке возрастания ID
+func (h *PriceHub) orderedLocked() []StreamEvent {
+	events := make([]StreamEvent, 0, len(h.replay))
+	events = append(events, h.replay[h.replayStart:]...)
+	return append(events, h.replay[:h.replayStart]...)
+}
+
+func (h *PriceHub) remove(sub *PriceSubscription, dropped bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.dropLocked(sub, dropped)
+}
+
+func (h *PriceHub) dropLocked(sub *PriceSubscription, dropped bool) {
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	sub.Dropped = dropped
+	close(sub.ch)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"affarm/internal/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func publishPrices(hub *PriceHub, symbol string, prices ...float64) {
+	currency := models.Currency{Symbol: symbol}
+	for _, price := range prices {
+		hub.OnPrice(currency, models.Price{Price: price, Timestamp: time.Now().UTC()})
+	}
+}
+
+// receive читает из подписки n событий, не дожидаясь новых
+func receive(t *testing.T, sub *PriceSubscription, n int) []StreamEvent {
+	t.Helper()
+	events := make([]StreamEvent, 0, n)
+	for len(events) < n {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				t.Fatalf("подписка закрыта после %d событий из %d", len(events), n)
+			}
+			events = append(events, event)
+		default:
+			t.Fatalf("получено %d событий из %d", len(events), n)
+		}
+	}
+	return events
+}
+
+func prices(events []StreamEvent) []float64 {
+	result := make([]float64, len(events))
+	for i, event := range events {
+		result[i] = event.Price.Price
+	}
+	return result
+}
+
+func TestPriceHubSlowConsumer(t *testing.T) {
+	hub := NewPriceHub(10, 2)
+	slow, _ := hub.Subscribe(nil, 0)
+	fast, _ := hub.Subscribe(nil, 0)
+
+	publishPrices(hub, "BTC", 1, 2)
+	receive(t, fast, 2)
+	// Очередь slow заполнена: третье событие отключает его, не задерживая остальных
+	publishPrices(hub, "BTC", 3)
+
+	if got := prices(receive(t, slow, 2)); !slices.Equal(got, []float64{1, 2}) {
+		t.Fatalf("медленный клиент получил %v до отключения, ожидалось [1 2]", got)
+	}
+	if _, ok := <-slow.C; ok || !slow.Dropped {
+		t.Fatal("медленный клиент должен отключаться с Dropped")
+	}
+	if got := prices(receive(t, fast, 1)); !slices.Equal(got, []float64{3}) {
+		t.Fatalf("быстрый клиент получил %v, ожидалось [3]", got)
+	}
+
+	// Закрытие уже отключенной подписки ничего не ломает, обычная отписка не помечается Dropped
+	slow.Close()
+	fast.Close()
+	if _, ok := <-fast.C; ok || fast.Dropped {
+		t.Fatal("отписка должна закрывать канал без Dropped")
+	}
+}
+
+func TestPriceHubReplay(t *testing.T) {
+	hub := NewPriceHub(10, 10)
+	first, _ := hub.Subscribe(nil, 0)
+	publishPrices(hub, "BTC", 1)
+	publishPrices(hub, "ETH", 2)
+	publishPrices(hub, "BTC", 3, 4)
+	events := receive(t, first, 4)
+	first.Close()
+
+	// Продолжение после второго события отдает из буфера только последующие события нужных валют
+	sub, complete := hub.Subscribe([]string{"BTC"}, events[1].ID)
+	defer sub.Close()
+	if !complete {
+		t.Fatal("буфер содержит все события после Last-Event-ID, complete должен быть true")
+	}
+	replayed := receive(t, sub, 2)
+	if got := prices(replayed); !slices.Equal(got, []float64{3, 4}) {
+		t.Fatalf("из буфера получено %v, ожидалось [3 4]", got)
+	}
+	if replayed[0].ID != events[2].ID || replayed[1].ID != events[3].ID {
+		t.Fatalf("ID событий из буфера %d, %d, ожидалось %d, %d", replayed[0].ID, replayed[1].ID, events[2].ID, events[3].ID)
+	}
+
+	// Новые события приходят после буфера
+	publishPrices(hub, "ETH", 5)
+	publishPrices(hub, "BTC", 6)
+	if got := prices(receive(t, sub, 1)); !slices.Equal(got, []float64{6}) {
+		t.Fatalf("после буфера получено %v, ожидалось [6]", got)
+	}
+}
+
+func TestPriceHubReplayIncomplete(t *testing.T) {
+	hub := NewPriceHub(2, 10)
+	first, _ := hub.Subscribe(nil, 0)
+	publishPrices(hub, "BTC", 1, 2, 3, 4)
+	events := receive(t, first, 4)
+	first.Close()
+
+	// События 2 и 3 вытеснены из буфера на два события: продолжение после 1 неполное
+	sub, complete := hub.Subscribe(nil, events[0].ID)
+	defer sub.Close()
+	if complete {
+		t.Fatal("часть событий после Last-Event-ID вытеснена, complete должен быть false")
+	}
+	if got := prices(receive(t, sub, 2)); !slices.Equal(got, []float64{3, 4}) {
+		t.Fatalf("из буфера получено %v, ожидалось [3 4]", got)
+	}
+
+	// Продолжение с последнего вытесненного события еще полное: следующее за ним в буфере
+	sub2, complete := hub.Subscribe(nil, events[1].ID)
+	defer sub2.Close()
+	if !complete {
+		t.Fatal("следующее событие после Last-Event-ID в буфере, complete должен быть true")
+	}
+	// Без Last-Event-ID продолжения нет, и подписка всегда полная
+	sub3, complete := hub.Subscribe(nil, 0)
+	defer sub3.Close()
+	if !complete {
+		t.Fatal("подписка без Last-Event-ID должна быть полной")
+	}
+	select {
+	case event := <-sub3.C:
+		t.Fatalf("подписка без Last-Event-ID получила событие из буфера %v", event)
+	default:
+	}
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"affarm/internal/models"
+	"affarm/internal/repository"
+	"context"
+	"errors"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Качество ответа о цене
+const (
+	QualityExact        = "exact"        // точка найдена в пределах ±1 секунды
+	QualityNearest      = "nearest"      // взята ближайшая точка
+	QualityInterpolated = "interpolated" // линейная интерполяция между соседними точками
+	QualityStale        = "stale"        // ближайшая точка дальше StaleAfter от запрошенного момента
+)
+
+// MaxLookupItems - максимальное количество пар в одном пакетном запросе
+const MaxLookupItems = 10000
+
+// MaxRangePoints - максимальное количество точек в ответе на запрос диапазона
+const MaxRangePoints = 10000
+
+// StaleAfter - расстояние до ближайшей точки, после которого ответ считается устаревшим
+const StaleAfter = 10 * time.Minute
+
+// PricePoint - точка ряда цен, использованная для ответа
+type PricePoint struct {
+	Price     float64         `json:"price"`
+	Decimal   decimal.Decimal `json:"-"` // точное значение из БД для денежных расчетов
+	Timestamp time.Time       `json:"timestamp"`
+	Source    string          `json:"source"`
+	// ResolutionSeconds - шаг свертки, из которой взята точка старше срока хранения сырых цен
+	ResolutionSeconds int64 `json:"resolution_seconds,omitempty"`
+}
+
+// PriceResponse - структура ответа
+type PriceResponse struct {
+	Symbol        string       `json:"symbol"`
+	Price         float64      `json:"price"`
+	Timestamp     time.Time    `json:"timestamp"`      // метка ближайшей из использованных точек
+	OffsetSeconds float64      `json:"offset_seconds"` // знаковое расстояние от запрошенного момента до timestamp
+	Source        string       `json:"source"`
+	Quality       string       `json:"quality" enums:"exact,nearest,interpolated,stale"`
+	Points        []PricePoint `json:"points"` // все точки, по которым посчитан ответ
+}
+
+// PriceQuery - запрос цены одной валюты на момент времени
+type PriceQuery = repository.PriceQuery
+
+// PriceLookup - соседние точки ряда для одного PriceQuery
+type PriceLookup struct {
+	CurrencyFound bool
+	NotTracked    bool        // запрошенный момент вне периодов отслеживания валюты (только при NoBridgeGaps)
+	Before        *PricePoint // последняя точка не позже запрошенного момента
+	After         *PricePoint // первая точка не раньше запрошенного момента
+}
+
+// LookupOptions - параметры поиска цены на момент времени
+type LookupOptions struct {
+	Interpolate bool // интерполировать между соседними точками вместо выбора ближайшей
+	// NoBridgeGaps запрещает брать точки из других периодов отслеживания: момент, когда валюта
+	// не отслеживалась, дает ошибку, а соседние точки ищутся только внутри периода, в который он попал
+	NoBridgeGaps bool
+}
+
+// PriceService - поиск цен по сохраненным рядам, общий для HTTP и gRPC
+type PriceService struct {
+	repo repository.Repository
+}
+
+// NewPriceService - конструктор сервиса цен
+func NewPriceService(repo repository.Repository) *PriceService {
+	return &PriceService{repo: repo}
+}
+
+// Lookup возвращает соседние точки для каждого запроса, в порядке запросов.
+// Запросы не проверяются, это делают At и Batch
+func (s *PriceService) Lookup(ctx context.Context, queries []PriceQuery) ([]PriceLookup, error) {
+	neighbors, err := s.repo.Neighbors(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PriceLookup, len(neighbors))
+	for i, n := range neighbors {
+		result[i].CurrencyFound = n.CurrencyFound
+		if n.Before != nil {
+			result[i].Before = newPricePoint(*n.Before)
+		}
+		if n.After != nil {
+			result[i].After = newPricePoint(*n.After)
+		}
+	}
+	return result, nil
+}
+
+// limitToTracking ограничивает соседние точки периодом отслеживания, в который попал момент запроса
+func (s *PriceService) limitToTracking(ctx context.Context, queries []PriceQuery, lookups []PriceLookup) error {
+	symbols := make([]string, 0, len(queries))
+	seen := make(map[string]bool, len(queries))
+	for _, query := range queries {
+		if !seen[query.Symbol] {
+			seen[query.Symbol] = true
+			symbols = append(symbols, query.Symbol)
+		}
+	}
+	periods, err := s.repo.TrackingPeriods(ctx, symbols)
+	if err != nil {
+		return err
+	}
+
+	for i, query := range queries {
+		lookup := &lookups[i]
+		if !lookup.CurrencyFound {
+			continue
+		}
+		period, ok := coveringPeriod(periods[query.Symbol], query.At)
+		if !ok {
+			lookup.NotTracked, lookup.Before, lookup.After = true, nil, nil
+			continue
+		}
+		if lookup.Before != nil && lookup.Before.Timestamp.Before(period.StartedAt) {
+			lookup.Before = nil
+		}
+		if lookup.After != nil && period.StoppedAt != nil && !lookup.After.Timestamp.Before(*period.StoppedAt) {
+			lookup.After = nil
+		}
+	}
+	return nil
+}
+
+// coveringPeriod ищет период, в который входит момент at
+func coveringPeriod(periods []models.TrackingPeriod, at time.Time) (models.TrackingPeriod, bool) {
+	for _, period := range periods {
+		if period.Covers(at) {
+			return period, true
+		}
+	}
+	return models.TrackingPeriod{}, false
+}
+
+func newPricePoint(p repository.Point) *PricePoint {
+	return &PricePoint{
+		Price:     p.Price.InexactFloat64(),
+		Decimal:   p.Price,
+		Timestamp: p.Timestamp.UTC(),
+		Source:    p.Source,
+
+		ResolutionSeconds: int64(p.Resolution / time.Second),
+	}
+}
+
+// validateQuery проверяет символ и момент времени одного запроса
+func validateQuery(q PriceQuery) error {
+	if err := ValidateSymbol(q.Symbol); err != nil {
+		return err
+	}
+	return validateMoment(q.At)
+}
+
+// lookup - Lookup с учетом LookupOptions
+func (s *PriceService) lookup(ctx context.Context, queries []PriceQuery, opts LookupOptions) ([]PriceLookup, error) {
+	lookups, err := s.Lookup(ctx, queries)
+	if err != nil || !opts.NoBridgeGaps || len(queries) == 0 {
+		return lookups, err
+	}
+	if err := s.limitToTracking(ctx, queries, lookups); err != nil {
+		return nil, err
+	}
+	return lookups, nil
+}
+
+// At возвращает цену валюты на момент времени
+func (s *PriceService) At(ctx context.Context, symbol string, at time.Time, opts LookupOptions) (PriceResponse, error) {
+	query := PriceQuery{Symbol: symbol, At: at}
+	if err := validateQuery(query); err != nil {
+		return PriceResponse{}, err
+	}
+
+	lookups, err := s.lookup(ctx, []PriceQuery{query}, opts)
+	if err != nil {
+		return PriceResponse{}, err
+	}
+	return lookups[0].Response(symbol, at, opts.Interpolate)
+}
+
+// BatchResult - результат одной пары пакетного запроса
+type BatchResult struct {
+	Response PriceResponse
+	Err      error // доменная ошибка пары: некорректный ввод, нет валюты или данных
+}
+
+// Batch разрешает набор пар одним запросом в хранилище. Невалидные пары получают ошибку
+// и в хранилище не попадают. Общая ошибка возвращается при пустом или слишком большом
+// наборе и при сбое хранилища
+func (s *PriceService) Batch(ctx context.Context, queries []PriceQuery, opts LookupOptions) ([]BatchResult, error) {
+	if len(queries) == 0 {
+		return nil, invalidf("items must not be empty")
+	}
+	if len(queries) > MaxLookupItems {
+		return nil, invalidf("too many items, max %d", MaxLookupItems)
+	}
+
+	results := make([]BatchResult, len(queries))
+	valid := make([]PriceQuery, 0, len(queries))
+	positions := make([]int, 0, len(queries))
+	for i, query := range queries {
+		if results[i].Err = validateQuery(query); results[i].Err == nil {
+			valid = append(valid, query)
+			positions = append(positions, i)
+		}
+	}
+
+	lookups, err := s.lookup(ctx, valid, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i, lookup := range lookups {
+		query := valid[i]
+		result := &results[positions[i]]
+		result.Response, result.Err = lookup.Response(query.Symbol, query.At, opts.Interpolate)
+	}
+	return results, nil
+}
+
+// Range возвращает точки ряда валюты в диапазоне [from, to) по возрастанию времени.
+// limit 0 означает MaxRangePoints
+func (s *PriceService) Range(ctx context.Context, symbol string, from, to time.Time, limit int) ([]PricePoint, error) {
+	if err := ValidateSymbol(symbol); err != nil {
+		return nil, err
+	}
+	if from.IsZero() || to.IsZero() {
+		return nil, invalidf("from and to are required")
+	}
+	if !to.After(from) {
+		return nil, invalidf("to must be after from")
+	}
+	if limit < 0 || limit > MaxRangePoints {
+		return nil, invalidf("invalid limit, max %d", MaxRangePoints)
+	}
+	if limit == 0 {
+		limit = MaxRangePoints
+	}
+
+	points, err := s.repo.Range(ctx, symbol, from, to, limit)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrCurrencyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PricePoint, len(points))
+	for i, point := range points {
+		result[i] = *newPricePoint(point)
+	}
+	return result, nil
+}
+
+// Response превращает соседние точки в ответ или ошибку ErrCurrencyNotFound / ErrNoPriceData
+func (l PriceLookup) Response(symbol string, at time.Time, interpolate bool) (PriceResponse, error) {
+	if !l.CurrencyFound {
+		return PriceResponse{}, ErrCurrencyNotFound
+	}
+	if l.NotTracked {
+		return PriceResponse{}, notTracked(symbol, at)
+	}
+	resp, ok := l.Resolve(symbol, at, interpolate)
+	if !ok {
+		return PriceResponse{}, noPriceData(symbol)
+	}
+	return resp, nil
+}
+
+// Resolve выбирает ответ по соседним точкам: точное совпадение (±1 секунда),
+// интерполяцию (если запрошена) или ближайшую точку.
+// Возвращает false, если данных о цене нет.
+func (l PriceLookup) Resolve(symbol string, at time.Time, interpolate bool) (PriceResponse, bool) {
+	at = at.UTC()
+	before, after := l.Before, l.After
+
+	switch {
+	case before == nil && after == nil:
+		return PriceResponse{}, false
+	case before != nil && at.Sub(before.Timestamp) <= time.Second:
+		return newPriceResponse(symbol, at, QualityExact, *before), true
+	case after != nil && after.Timestamp.Sub(at) <= time.Second:
+		return newPriceResponse(symbol, at, QualityExact, *after), true
+	case before == nil:
+		return newPriceResponse(symbol, at, QualityNearest, *after), true
+	case after == nil:
+		return newPriceResponse(symbol, at, QualityNearest, *before), true
+	case interpolate:
+		return interpolatedPriceResponse(symbol, at, *before, *after), true
+	case at.Sub(before.Timestamp) < after.Timestamp.Sub(at):
+		return newPriceResponse(symbol, at, QualityNearest, *before), true
+	default:
+		return newPriceResponse(symbol, at, QualityNearest, *after), true
+	}
+}
+
+// newPriceResponse собирает ответ по одной точке.
+// Качество nearest понижается до stale, если точка дальше StaleAfter.
+func newPriceResponse(symbol string, at time.Time, quality string, p PricePoint) PriceResponse {
+	offset := p.Timestamp.Sub(at)
+	if quality == QualityNearest && offset.Abs() > StaleAfter {
+		quality = QualityStale
+	}
+	return PriceResponse{
+		Symbol:        symbol,
+		Price:         p.Price,
+		Timestamp:     p.Timestamp,
+		OffsetSeconds: offset.Seconds(),
+		Source:        p.Source,
+		Quality:       quality,
+		Points:        []PricePoint{p},
+	}
+}
+
+// interpolatedPriceResponse линейно интерполирует цену между точками до и после момента at.
+// Если хотя бы одна из точек дальше StaleAfter, ответ помечается как stale.
+func interpolatedPriceResponse(symbol string, at time.Time, before, after PricePoint) PriceResponse {
+	span := after.Timestamp.Sub(before.Timestamp)
+	price := before.Price
+	if span > 0 {
+		weight := float64(at.Sub(before.Timestamp)) / float64(span)
+		price = before.Price + (after.Price-before.Price)*weight
+	}
+
+	nearest := before
+	if after.Timestamp.Sub(at) < at.Sub(before.Timestamp) {
+		nearest = after
+	}
+
+	quality := QualityInterpolated
+	if at.Sub(before.Timestamp) > StaleAfter || after.Timestamp.Sub(at) > StaleAfter {
+		quality = QualityStale
+	}
+
+	source := before.Source
+	if after.Source != before.Source {
+		source = before.Source + "," + after.Source
+	}
+
+	return PriceResponse{
+		Symbol:        symbol,
+		Price:         price,
+		Timestamp:     nearest.Timestamp,
+		OffsetSeconds: nearest.Timestamp.Sub(at).Seconds(),
+		Source:        source,
+		Quality:       quality,
+		Points:        []PricePoint{before, after},
+	}
+}
@@ -0,0 +1,14 @@
+package services
+
+import "affarm/internal/archive"
+
+// Services - сервисы и кеши, общие для всех транспортов (HTTP, gRPC)
+type Services struct {
+	Currencies   *CurrencyService
+	Prices       *PriceService
+	Cache        *PriceCache
+	Correlations *CorrelationCache
+	Alerts       *AlertService
+	Hub          *PriceHub
+	Archive      *archive.Reader // nil, если чтение цен из архива выключено
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxSymbolLength - максимальная длина символа валюты, как в колонке currencies.symbol
+const MaxSymbolLength = 10
+
+// ValidateSymbol проверяет символ валюты: непустой, в верхнем регистре, не длиннее MaxSymbolLength
+func ValidateSymbol(symbol string) error {
+	switch {
+	case symbol == "":
+		return invalidf("symbol is required")
+	case strings.ToUpper(symbol) != symbol:
+		return invalidf("symbol %q must be uppercase", symbol)
+	case utf8.RuneCountInString(symbol) > MaxSymbolLength:
+		return invalidf("symbol %q is longer than %d characters", symbol, MaxSymbolLength)
+	}
+	return nil
+}
+
+// validateMoment проверяет, что момент времени задан
+func validateMoment(at time.Time) error {
+	if at.IsZero() {
+		return invalidf("timestamp is required")
+	}
+	return nil
+}